@@ -45,7 +45,10 @@ type Appendable interface {
 	Offset() int64
 	SetOffset(off int64) error
 	DiscardUpto(off int64) error
+	PunchHole(off, length int64) error
 	Append(bs []byte) (off int64, n int, err error)
+	WrittenBytes() int64
+	BytesWrittenSinceFlush() int64
 	Flush() error
 	Sync() error
 	ReadAt(bs []byte, off int64) (int, error)
@@ -0,0 +1,80 @@
+/*
+Copyright 2022 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package memapp
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/codenotary/immudb/embedded/appendable"
+	"github.com/codenotary/immudb/embedded/appendable/multiapp"
+	"github.com/codenotary/immudb/embedded/appendable/singleapp"
+)
+
+// MultiFileAppendableHooks makes a multi-file appendable hold each of its chunks in memory.
+// Chunks are preserved after being closed so they can be opened again through the same hooks
+type MultiFileAppendableHooks struct {
+	buffers map[string]*buffer
+
+	mutex sync.Mutex
+}
+
+func NewMultiFileAppendableHooks() *MultiFileAppendableHooks {
+	return &MultiFileAppendableHooks{
+		buffers: make(map[string]*buffer),
+	}
+}
+
+func (h *MultiFileAppendableHooks) OpenAppendable(options *singleapp.Options, appname string, needsWriteAccess bool) (appendable.Appendable, error) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	buf, ok := h.buffers[appname]
+	if !ok {
+		buf = &buffer{metadata: options.GetMetadata()}
+		h.buffers[appname] = buf
+	}
+
+	return openBuffer(buf, options.GetReadOnly()), nil
+}
+
+func (h *MultiFileAppendableHooks) OpenInitialAppendable(opts *multiapp.Options, singleAppOpts *singleapp.Options) (app appendable.Appendable, appID int64, err error) {
+	h.mutex.Lock()
+
+	for appname := range h.buffers {
+		id, err := strconv.ParseInt(strings.TrimSuffix(appname, "."+opts.GetFileExt()), 10, 64)
+		if err != nil {
+			continue
+		}
+
+		if id > appID {
+			appID = id
+		}
+	}
+
+	h.mutex.Unlock()
+
+	app, err = h.OpenAppendable(singleAppOpts, fmt.Sprintf("%08d.%s", appID, opts.GetFileExt()), true)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return app, appID, nil
+}
+
+var _ multiapp.MultiFileAppendableHooks = (*MultiFileAppendableHooks)(nil)
@@ -0,0 +1,319 @@
+/*
+Copyright 2022 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package memapp
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/codenotary/immudb/embedded/appendable"
+	"github.com/codenotary/immudb/embedded/appendable/singleapp"
+)
+
+var ErrIllegalArguments = errors.New("illegal arguments")
+var ErrAlreadyClosed = errors.New("in-memory appendable already closed")
+var ErrReadOnly = errors.New("cannot append when opened in read-only mode")
+
+// buffer holds the content of an in-memory appendable,
+// it may outlive the appendable so it can be opened again
+type buffer struct {
+	data     []byte
+	metadata []byte
+
+	mutex sync.RWMutex
+}
+
+// InMemoryAppendable is an appendable backed by a byte buffer and intended to
+// be used in tests where no filesystem state is required
+type InMemoryAppendable struct {
+	buf *buffer
+
+	readOnly bool
+
+	offset         int64
+	writtenBytes   int64
+	unflushedBytes int64
+
+	closed bool
+
+	mutex sync.Mutex
+}
+
+func Open(opts *Options) (*InMemoryAppendable, error) {
+	if !opts.Valid() {
+		return nil, ErrIllegalArguments
+	}
+
+	return openBuffer(&buffer{metadata: opts.metadata}, opts.readOnly), nil
+}
+
+func openBuffer(buf *buffer, readOnly bool) *InMemoryAppendable {
+	buf.mutex.RLock()
+	defer buf.mutex.RUnlock()
+
+	return &InMemoryAppendable{
+		buf:      buf,
+		readOnly: readOnly,
+		offset:   int64(len(buf.data)),
+	}
+}
+
+func (a *InMemoryAppendable) Metadata() []byte {
+	return a.buf.metadata
+}
+
+func (a *InMemoryAppendable) CompressionFormat() int {
+	return appendable.NoCompression
+}
+
+func (a *InMemoryAppendable) CompressionLevel() int {
+	return appendable.DefaultCompressionLevel
+}
+
+func (a *InMemoryAppendable) Size() (int64, error) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if a.closed {
+		return 0, ErrAlreadyClosed
+	}
+
+	a.buf.mutex.RLock()
+	defer a.buf.mutex.RUnlock()
+
+	return int64(len(a.buf.data)), nil
+}
+
+func (a *InMemoryAppendable) Offset() int64 {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	return a.offset
+}
+
+func (a *InMemoryAppendable) SetOffset(off int64) error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if a.closed {
+		return ErrAlreadyClosed
+	}
+
+	if off < 0 {
+		return ErrIllegalArguments
+	}
+
+	a.offset = off
+	return nil
+}
+
+func (a *InMemoryAppendable) DiscardUpto(off int64) error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if a.closed {
+		return ErrAlreadyClosed
+	}
+
+	if a.offset < off {
+		return fmt.Errorf("%w: discard beyond existent data boundaries", ErrIllegalArguments)
+	}
+
+	return nil
+}
+
+// PunchHole zeroes the content in the range [off, off+length), no memory is released
+func (a *InMemoryAppendable) PunchHole(off, length int64) error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if a.closed {
+		return ErrAlreadyClosed
+	}
+
+	if a.readOnly {
+		return ErrReadOnly
+	}
+
+	if off < 0 || length < 0 {
+		return ErrIllegalArguments
+	}
+
+	if a.offset < off+length {
+		return fmt.Errorf("%w: hole beyond existent data boundaries", ErrIllegalArguments)
+	}
+
+	a.buf.mutex.Lock()
+	defer a.buf.mutex.Unlock()
+
+	end := off + length
+	if end > int64(len(a.buf.data)) {
+		end = int64(len(a.buf.data))
+	}
+
+	for i := off; i < end; i++ {
+		a.buf.data[i] = 0
+	}
+
+	return nil
+}
+
+func (a *InMemoryAppendable) Append(bs []byte) (off int64, n int, err error) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if a.closed {
+		return 0, 0, ErrAlreadyClosed
+	}
+
+	if a.readOnly {
+		return 0, 0, ErrReadOnly
+	}
+
+	if len(bs) == 0 {
+		return 0, 0, ErrIllegalArguments
+	}
+
+	a.buf.mutex.Lock()
+	defer a.buf.mutex.Unlock()
+
+	end := a.offset + int64(len(bs))
+
+	if end > int64(len(a.buf.data)) {
+		data := make([]byte, end)
+		copy(data, a.buf.data)
+		a.buf.data = data
+	}
+
+	copy(a.buf.data[a.offset:], bs)
+
+	off = a.offset
+	n = len(bs)
+
+	a.offset = end
+	a.writtenBytes += int64(n)
+	a.unflushedBytes += int64(n)
+
+	return off, n, nil
+}
+
+func (a *InMemoryAppendable) WrittenBytes() int64 {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	return a.writtenBytes
+}
+
+// BytesWrittenSinceFlush returns the number of bytes appended since the last call to Flush or Sync
+func (a *InMemoryAppendable) BytesWrittenSinceFlush() int64 {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	return a.unflushedBytes
+}
+
+func (a *InMemoryAppendable) Flush() error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if a.closed {
+		return ErrAlreadyClosed
+	}
+
+	if a.readOnly {
+		return ErrReadOnly
+	}
+
+	a.unflushedBytes = 0
+
+	return nil
+}
+
+func (a *InMemoryAppendable) Sync() error {
+	return a.Flush()
+}
+
+func (a *InMemoryAppendable) ReadAt(bs []byte, off int64) (int, error) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if a.closed {
+		return 0, ErrAlreadyClosed
+	}
+
+	if bs == nil || off < 0 {
+		return 0, ErrIllegalArguments
+	}
+
+	a.buf.mutex.RLock()
+	defer a.buf.mutex.RUnlock()
+
+	if off >= int64(len(a.buf.data)) {
+		return 0, io.EOF
+	}
+
+	n := copy(bs, a.buf.data[off:])
+	if n < len(bs) {
+		return n, io.EOF
+	}
+
+	return n, nil
+}
+
+// Copy dumps the content into a single-file appendable located at dstPath
+func (a *InMemoryAppendable) Copy(dstPath string) error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if a.closed {
+		return ErrAlreadyClosed
+	}
+
+	a.buf.mutex.RLock()
+	defer a.buf.mutex.RUnlock()
+
+	dst, err := singleapp.Open(dstPath, singleapp.DefaultOptions().WithMetadata(a.buf.metadata))
+	if err != nil {
+		return err
+	}
+
+	if len(a.buf.data) > 0 {
+		_, _, err = dst.Append(a.buf.data)
+		if err != nil {
+			dst.Close()
+			return err
+		}
+	}
+
+	return dst.Close()
+}
+
+func (a *InMemoryAppendable) Close() error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if a.closed {
+		return ErrAlreadyClosed
+	}
+
+	a.closed = true
+
+	return nil
+}
+
+var _ appendable.Appendable = (*InMemoryAppendable)(nil)
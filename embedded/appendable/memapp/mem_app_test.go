@@ -0,0 +1,242 @@
+/*
+Copyright 2022 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package memapp
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/codenotary/immudb/embedded/appendable"
+	"github.com/codenotary/immudb/embedded/appendable/multiapp"
+	"github.com/codenotary/immudb/embedded/appendable/singleapp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemApp(t *testing.T) {
+	_, err := Open(nil)
+	require.ErrorIs(t, err, ErrIllegalArguments)
+
+	a, err := Open(DefaultOptions().WithMetadata([]byte{1, 2}))
+	require.NoError(t, err)
+
+	require.Equal(t, []byte{1, 2}, a.Metadata())
+	require.Equal(t, appendable.NoCompression, a.CompressionFormat())
+	require.Equal(t, appendable.DefaultCompressionLevel, a.CompressionLevel())
+
+	sz, err := a.Size()
+	require.NoError(t, err)
+	require.Zero(t, sz)
+
+	_, _, err = a.Append(nil)
+	require.ErrorIs(t, err, ErrIllegalArguments)
+
+	off, n, err := a.Append([]byte{0, 1, 2, 3})
+	require.NoError(t, err)
+	require.Equal(t, int64(0), off)
+	require.Equal(t, 4, n)
+
+	off, n, err = a.Append([]byte{4, 5, 6})
+	require.NoError(t, err)
+	require.Equal(t, int64(4), off)
+	require.Equal(t, 3, n)
+
+	require.Equal(t, int64(7), a.Offset())
+	require.Equal(t, int64(7), a.WrittenBytes())
+	require.Equal(t, int64(7), a.BytesWrittenSinceFlush())
+
+	err = a.Flush()
+	require.NoError(t, err)
+	require.Zero(t, a.BytesWrittenSinceFlush())
+
+	err = a.Sync()
+	require.NoError(t, err)
+	require.Zero(t, a.BytesWrittenSinceFlush())
+
+	bs := make([]byte, 4)
+	n, err = a.ReadAt(bs, 2)
+	require.NoError(t, err)
+	require.Equal(t, 4, n)
+	require.Equal(t, []byte{2, 3, 4, 5}, bs)
+
+	n, err = a.ReadAt(bs, 5)
+	require.ErrorIs(t, err, io.EOF)
+	require.Equal(t, 2, n)
+
+	_, err = a.ReadAt(bs, 7)
+	require.ErrorIs(t, err, io.EOF)
+
+	_, err = a.ReadAt(nil, 0)
+	require.ErrorIs(t, err, ErrIllegalArguments)
+
+	err = a.SetOffset(-1)
+	require.ErrorIs(t, err, ErrIllegalArguments)
+
+	err = a.SetOffset(6)
+	require.NoError(t, err)
+
+	err = a.DiscardUpto(7)
+	require.ErrorIs(t, err, ErrIllegalArguments)
+
+	err = a.DiscardUpto(6)
+	require.NoError(t, err)
+
+	_, _, err = a.Append([]byte{16, 17})
+	require.NoError(t, err)
+
+	sz, err = a.Size()
+	require.NoError(t, err)
+	require.Equal(t, int64(8), sz)
+
+	dir, err := ioutil.TempDir("", "memapp")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	err = a.Copy(filepath.Join(dir, "copy.aof"))
+	require.NoError(t, err)
+
+	copied, err := singleapp.Open(filepath.Join(dir, "copy.aof"), singleapp.DefaultOptions().WithReadOnly(true))
+	require.NoError(t, err)
+
+	require.Equal(t, []byte{1, 2}, copied.Metadata())
+
+	bs = make([]byte, 8)
+	_, err = copied.ReadAt(bs, 0)
+	require.NoError(t, err)
+	require.Equal(t, []byte{0, 1, 2, 3, 4, 5, 16, 17}, bs)
+
+	err = copied.Close()
+	require.NoError(t, err)
+
+	err = a.Close()
+	require.NoError(t, err)
+
+	err = a.Close()
+	require.ErrorIs(t, err, ErrAlreadyClosed)
+
+	_, err = a.Size()
+	require.ErrorIs(t, err, ErrAlreadyClosed)
+
+	_, _, err = a.Append([]byte{1})
+	require.ErrorIs(t, err, ErrAlreadyClosed)
+
+	_, err = a.ReadAt(bs, 0)
+	require.ErrorIs(t, err, ErrAlreadyClosed)
+
+	err = a.Flush()
+	require.ErrorIs(t, err, ErrAlreadyClosed)
+
+	err = a.SetOffset(0)
+	require.ErrorIs(t, err, ErrAlreadyClosed)
+
+	err = a.DiscardUpto(0)
+	require.ErrorIs(t, err, ErrAlreadyClosed)
+
+	err = a.Copy(filepath.Join(dir, "copy2.aof"))
+	require.ErrorIs(t, err, ErrAlreadyClosed)
+}
+
+func TestMemAppPunchHole(t *testing.T) {
+	a, err := Open(DefaultOptions())
+	require.NoError(t, err)
+
+	_, _, err = a.Append([]byte{1, 2, 3, 4, 5})
+	require.NoError(t, err)
+
+	err = a.PunchHole(-1, 1)
+	require.ErrorIs(t, err, ErrIllegalArguments)
+
+	err = a.PunchHole(4, 2)
+	require.ErrorIs(t, err, ErrIllegalArguments)
+
+	err = a.PunchHole(1, 3)
+	require.NoError(t, err)
+
+	bs := make([]byte, 5)
+	_, err = a.ReadAt(bs, 0)
+	require.NoError(t, err)
+	require.Equal(t, []byte{1, 0, 0, 0, 5}, bs)
+
+	err = a.Close()
+	require.NoError(t, err)
+
+	err = a.PunchHole(0, 1)
+	require.ErrorIs(t, err, ErrAlreadyClosed)
+}
+
+func TestMemAppReadOnly(t *testing.T) {
+	a, err := Open(DefaultOptions().WithReadOnly(true))
+	require.NoError(t, err)
+
+	_, _, err = a.Append([]byte{1})
+	require.ErrorIs(t, err, ErrReadOnly)
+
+	err = a.Flush()
+	require.ErrorIs(t, err, ErrReadOnly)
+}
+
+func TestMultiAppWithMemAppHooks(t *testing.T) {
+	dir, err := ioutil.TempDir("", "memapp_multiapp")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	hooks := NewMultiFileAppendableHooks()
+
+	opts := multiapp.DefaultOptions().
+		WithFileSize(10).
+		WithMaxOpenedFiles(2)
+
+	a, err := multiapp.OpenWithHooks(dir, hooks, opts)
+	require.NoError(t, err)
+
+	var expected []byte
+
+	for i := 0; i < 10; i++ {
+		bs := []byte{byte(i), byte(i), byte(i), byte(i), byte(i), byte(i), byte(i)}
+
+		_, _, err = a.Append(bs)
+		require.NoError(t, err)
+
+		expected = append(expected, bs...)
+	}
+
+	err = a.Close()
+	require.NoError(t, err)
+
+	require.Len(t, hooks.buffers, 7)
+
+	// no files are created
+	fis, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	require.Empty(t, fis)
+
+	a, err = multiapp.OpenWithHooks(dir, hooks, opts)
+	require.NoError(t, err)
+
+	sz, err := a.Size()
+	require.NoError(t, err)
+	require.Equal(t, int64(len(expected)), sz)
+
+	bs := make([]byte, len(expected))
+	_, err = a.ReadAt(bs, 0)
+	require.NoError(t, err)
+	require.Equal(t, expected, bs)
+
+	err = a.Close()
+	require.NoError(t, err)
+}
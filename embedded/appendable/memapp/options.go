@@ -0,0 +1,41 @@
+/*
+Copyright 2022 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package memapp
+
+type Options struct {
+	readOnly bool
+	metadata []byte
+}
+
+func DefaultOptions() *Options {
+	return &Options{
+		readOnly: false,
+	}
+}
+
+func (opts *Options) Valid() bool {
+	return opts != nil
+}
+
+func (opts *Options) WithReadOnly(readOnly bool) *Options {
+	opts.readOnly = readOnly
+	return opts
+}
+
+func (opts *Options) WithMetadata(metadata []byte) *Options {
+	opts.metadata = metadata
+	return opts
+}
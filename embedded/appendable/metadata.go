@@ -19,6 +19,7 @@ import (
 	"bufio"
 	"bytes"
 	"encoding/binary"
+	"encoding/gob"
 	"io"
 )
 
@@ -112,6 +113,65 @@ func (m *Metadata) GetInt(key string) (int, bool) {
 	return int(binary.BigEndian.Uint64(v)), true
 }
 
+// uint64Tag prefixes values stored with PutUint64
+const uint64Tag = byte(1)
+
+// PutUint64 stores v under key as a tagged big-endian value
+func (m *Metadata) PutUint64(key string, v uint64) {
+	b := make([]byte, 1+8)
+	b[0] = uint64Tag
+	binary.BigEndian.PutUint64(b[1:], v)
+	m.Put(key, b)
+}
+
+// GetUint64 returns the value stored under key with PutUint64, values stored with PutInt are also accepted.
+// false is returned if there is no such key or its value is not an integer
+func (m *Metadata) GetUint64(key string) (uint64, bool) {
+	v, ok := m.Get(key)
+	if !ok {
+		return 0, false
+	}
+
+	if len(v) == 8 {
+		return binary.BigEndian.Uint64(v), true
+	}
+
+	if len(v) != 1+8 || v[0] != uint64Tag {
+		return 0, false
+	}
+
+	return binary.BigEndian.Uint64(v[1:]), true
+}
+
+// PutGob stores v encoded with encoding/gob under key
+func (m *Metadata) PutGob(key string, v interface{}) error {
+	var b bytes.Buffer
+
+	err := gob.NewEncoder(&b).Encode(v)
+	if err != nil {
+		return err
+	}
+
+	m.Put(key, b.Bytes())
+
+	return nil
+}
+
+// GetGob decodes into v the value stored under key with PutGob, false is returned if there is no such key
+func (m *Metadata) GetGob(key string, v interface{}) (bool, error) {
+	b, ok := m.Get(key)
+	if !ok {
+		return false, nil
+	}
+
+	err := gob.NewDecoder(bytes.NewReader(b)).Decode(v)
+	if err != nil {
+		return true, err
+	}
+
+	return true, nil
+}
+
 func (m *Metadata) Put(key string, value []byte) {
 	m.data[key] = value
 }
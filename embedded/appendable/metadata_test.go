@@ -18,6 +18,7 @@ package appendable
 import (
 	"errors"
 	"fmt"
+	"math"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -72,3 +73,66 @@ func TestMedatada(t *testing.T) {
 	_, err = md.WriteTo(mockedWriter)
 	require.Error(t, err)
 }
+
+func TestMedatadaUint64(t *testing.T) {
+	md := NewMetadata(nil)
+
+	_, found := md.GetUint64("key")
+	require.False(t, found)
+
+	md.PutUint64("max", math.MaxUint64)
+	md.PutUint64("zero", 0)
+	md.PutInt("int", 42)
+	md.Put("invalid", []byte{2, 0, 0, 0, 0, 0, 0, 0, 1})
+
+	md1 := NewMetadata(md.Bytes())
+
+	v, found := md1.GetUint64("max")
+	require.True(t, found)
+	require.Equal(t, uint64(math.MaxUint64), v)
+
+	v, found = md1.GetUint64("zero")
+	require.True(t, found)
+	require.Zero(t, v)
+
+	v, found = md1.GetUint64("int")
+	require.True(t, found)
+	require.Equal(t, uint64(42), v)
+
+	_, found = md1.GetUint64("invalid")
+	require.False(t, found)
+}
+
+func TestMedatadaGob(t *testing.T) {
+	type config struct {
+		Algorithm string
+		Levels    []int
+	}
+
+	md := NewMetadata(nil)
+
+	var cfg config
+
+	found, err := md.GetGob("config", &cfg)
+	require.NoError(t, err)
+	require.False(t, found)
+
+	err = md.PutGob("config", config{Algorithm: "sha256", Levels: []int{1, 2, 3}})
+	require.NoError(t, err)
+
+	err = md.PutGob("invalid", func() {})
+	require.Error(t, err)
+
+	md1 := NewMetadata(md.Bytes())
+
+	found, err = md1.GetGob("config", &cfg)
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, config{Algorithm: "sha256", Levels: []int{1, 2, 3}}, cfg)
+
+	md1.Put("corrupted", []byte{1, 2, 3})
+
+	found, err = md1.GetGob("corrupted", &cfg)
+	require.Error(t, err)
+	require.True(t, found)
+}
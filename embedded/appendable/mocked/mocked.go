@@ -16,19 +16,22 @@ limitations under the License.
 package mocked
 
 type MockedAppendable struct {
-	MetadataFn          func() []byte
-	SizeFn              func() (int64, error)
-	OffsetFn            func() int64
-	SetOffsetFn         func(off int64) error
-	DiscardUptoFn       func(off int64) error
-	AppendFn            func(bs []byte) (off int64, n int, err error)
-	FlushFn             func() error
-	SyncFn              func() error
-	ReadAtFn            func(bs []byte, off int64) (int, error)
-	CopyFn              func(dstPath string) error
-	CloseFn             func() error
-	CompressionFormatFn func() int
-	CompressionLevelFn  func() int
+	MetadataFn               func() []byte
+	SizeFn                   func() (int64, error)
+	OffsetFn                 func() int64
+	SetOffsetFn              func(off int64) error
+	DiscardUptoFn            func(off int64) error
+	PunchHoleFn              func(off, length int64) error
+	AppendFn                 func(bs []byte) (off int64, n int, err error)
+	WrittenBytesFn           func() int64
+	BytesWrittenSinceFlushFn func() int64
+	FlushFn                  func() error
+	SyncFn                   func() error
+	ReadAtFn                 func(bs []byte, off int64) (int, error)
+	CopyFn                   func(dstPath string) error
+	CloseFn                  func() error
+	CompressionFormatFn      func() int
+	CompressionLevelFn       func() int
 }
 
 func (a *MockedAppendable) Metadata() []byte {
@@ -55,10 +58,22 @@ func (a *MockedAppendable) DiscardUpto(off int64) error {
 	return a.DiscardUptoFn(off)
 }
 
+func (a *MockedAppendable) PunchHole(off, length int64) error {
+	return a.PunchHoleFn(off, length)
+}
+
 func (a *MockedAppendable) Append(bs []byte) (off int64, n int, err error) {
 	return a.AppendFn(bs)
 }
 
+func (a *MockedAppendable) WrittenBytes() int64 {
+	return a.WrittenBytesFn()
+}
+
+func (a *MockedAppendable) BytesWrittenSinceFlush() int64 {
+	return a.BytesWrittenSinceFlushFn()
+}
+
 func (a *MockedAppendable) Flush() error {
 	return a.FlushFn()
 }
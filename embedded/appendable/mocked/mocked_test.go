@@ -48,10 +48,22 @@ func TestMocked(t *testing.T) {
 		return 0, 0, nil
 	}
 
+	mocked.WrittenBytesFn = func() int64 {
+		return 0
+	}
+
+	mocked.BytesWrittenSinceFlushFn = func() int64 {
+		return 0
+	}
+
 	mocked.DiscardUptoFn = func(off int64) error {
 		return nil
 	}
 
+	mocked.PunchHoleFn = func(off, length int64) error {
+		return nil
+	}
+
 	mocked.FlushFn = func() error {
 		return nil
 	}
@@ -96,9 +108,15 @@ func TestMocked(t *testing.T) {
 	require.Equal(t, 0, n)
 	require.NoError(t, err)
 
+	require.Equal(t, int64(0), mocked.WrittenBytes())
+	require.Equal(t, int64(0), mocked.BytesWrittenSinceFlush())
+
 	err = mocked.DiscardUpto(1)
 	require.NoError(t, err)
 
+	err = mocked.PunchHole(0, 1)
+	require.NoError(t, err)
+
 	err = mocked.Flush()
 	require.NoError(t, err)
 
@@ -16,10 +16,12 @@ limitations under the License.
 package multiapp
 
 import (
+	"crypto/sha256"
 	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"log"
 	"os"
 	"path"
 	"path/filepath"
@@ -36,6 +38,9 @@ var ErrorPathIsNotADirectory = errors.New("path is not a directory")
 var ErrIllegalArguments = errors.New("illegal arguments")
 var ErrAlreadyClosed = errors.New("multi-appendable already closed")
 var ErrReadOnly = errors.New("cannot append when opened in read-only mode")
+var ErrSegmentIsActive = errors.New("segment is active")
+var ErrMetadataTooLarge = errors.New("metadata exceeds the segment header size")
+var ErrInvalidSegmentIndex = errors.New("invalid segment index")
 
 const (
 	metaFileSize    = "FILE_SIZE"
@@ -65,8 +70,15 @@ func (d *DefaultMultiFileAppendableHooks) OpenInitialAppendable(opts *Options, s
 
 	var filename string
 
-	if len(fis) > 0 {
-		filename = fis[len(fis)-1].Name()
+	// checksum files are kept next to segments
+	for i := len(fis) - 1; i >= 0; i-- {
+		if filepath.Ext(fis[i].Name()) != "."+ChecksumFileExt {
+			filename = fis[i].Name()
+			break
+		}
+	}
+
+	if filename != "" {
 
 		appID, err = strconv.ParseInt(strings.TrimSuffix(filename, filepath.Ext(filename)), 10, 64)
 		if err != nil {
@@ -99,12 +111,32 @@ type MultiFileAppendable struct {
 	path            string
 	readOnly        bool
 	synced          bool
+	directIO        bool
+	mmapSize        int64
 	fileMode        os.FileMode
 	fileSize        int
 	fileExt         string
 	readBufferSize  int
 	writeBufferSize int
 
+	writtenBytes int64
+
+	// bytes appended since the last flush or sync
+	unflushedBytes int64
+
+	// readSem bounds the number of concurrent reads, nil when unbounded
+	readSem chan struct{}
+
+	// when bufferedSync > 1 segments are opened unsynced and fsynced once every bufferedSync flushes
+	bufferedSync int
+	pendingSyncs int
+
+	// checksum of the content of the active segment, nil when checksums are disabled
+	currChecksum   *segmentChecksum
+	checksumOnRead bool
+	// sealed segments verified since opening
+	verifiedSegments map[int64]struct{}
+
 	closed bool
 
 	hooks MultiFileAppendableHooks
@@ -123,6 +155,27 @@ func OpenWithHooks(path string, hooks MultiFileAppendableHooks, opts *Options) (
 		return nil, ErrIllegalArguments
 	}
 
+	m := appendable.NewMetadata(nil)
+	m.PutInt(metaFileSize, opts.fileSize)
+	m.Put(metaWrappedMeta, opts.metadata)
+
+	appendableOpts := singleapp.DefaultOptions().
+		WithReadOnly(opts.readOnly).
+		WithSynced(opts.synced && opts.bufferedSync <= 1).
+		WithDirectIO(opts.directIO).
+		WithMmapSize(opts.mmapSize).
+		WithFileMode(opts.fileMode).
+		WithCompressionFormat(opts.compressionFormat).
+		WithCompresionLevel(opts.compressionLevel).
+		WithReadBufferSize(opts.readBufferSize).
+		WithWriteBufferSize(opts.writeBufferSize).
+		WithMetadata(m.Bytes())
+
+	if opts.segmentHeaderSize > 0 && singleapp.HeaderSize(appendableOpts) > opts.segmentHeaderSize {
+		return nil, fmt.Errorf("%w: %d bytes are required but the limit is %d",
+			ErrMetadataTooLarge, singleapp.HeaderSize(appendableOpts), opts.segmentHeaderSize)
+	}
+
 	finfo, err := os.Stat(path)
 	if err != nil {
 		if !os.IsNotExist(err) || opts.readOnly {
@@ -137,20 +190,6 @@ func OpenWithHooks(path string, hooks MultiFileAppendableHooks, opts *Options) (
 		return nil, ErrorPathIsNotADirectory
 	}
 
-	m := appendable.NewMetadata(nil)
-	m.PutInt(metaFileSize, opts.fileSize)
-	m.Put(metaWrappedMeta, opts.metadata)
-
-	appendableOpts := singleapp.DefaultOptions().
-		WithReadOnly(opts.readOnly).
-		WithSynced(opts.synced).
-		WithFileMode(opts.fileMode).
-		WithCompressionFormat(opts.compressionFormat).
-		WithCompresionLevel(opts.compressionLevel).
-		WithReadBufferSize(opts.readBufferSize).
-		WithWriteBufferSize(opts.writeBufferSize).
-		WithMetadata(m.Bytes())
-
 	currApp, currAppID, err := hooks.OpenInitialAppendable(opts, appendableOpts)
 	if err != nil {
 		return nil, err
@@ -163,21 +202,43 @@ func OpenWithHooks(path string, hooks MultiFileAppendableHooks, opts *Options) (
 
 	fileSize, _ := appendable.NewMetadata(currApp.Metadata()).GetInt(metaFileSize)
 
-	return &MultiFileAppendable{
+	var readSem chan struct{}
+	if opts.maxConcurrentReads > 0 {
+		readSem = make(chan struct{}, opts.maxConcurrentReads)
+	}
+
+	mf := &MultiFileAppendable{
 		appendables:     appendableLRUCache{cache: cache},
 		currAppID:       currAppID,
 		currApp:         currApp,
 		path:            path,
 		readOnly:        opts.readOnly,
 		synced:          opts.synced,
+		directIO:        opts.directIO,
+		mmapSize:        opts.mmapSize,
 		fileMode:        opts.fileMode,
 		fileSize:        fileSize,
 		fileExt:         opts.fileExt,
 		readBufferSize:  opts.readBufferSize,
 		writeBufferSize: opts.writeBufferSize,
+		readSem:         readSem,
+		bufferedSync:    opts.bufferedSync,
+		checksumOnRead:  opts.checksumOnRead,
 		closed:          false,
 		hooks:           hooks,
-	}, nil
+	}
+
+	if opts.checksumOnRead {
+		mf.verifiedSegments = make(map[int64]struct{})
+
+		err = mf.initChecksum()
+		if err != nil {
+			currApp.Close()
+			return nil, err
+		}
+	}
+
+	return mf, nil
 }
 
 func appendableName(appID int64, ext string) string {
@@ -279,6 +340,49 @@ func (mf *MultiFileAppendable) Size() (int64, error) {
 	return mf.currAppID*int64(mf.fileSize) + currSize, nil
 }
 
+// SegmentSizes returns the size of each segment, where index i corresponds to the segment i.
+// The size of the active segment is its current write offset.
+// Segments already discarded are reported with size zero, nil is returned if the appendable is closed
+func (mf *MultiFileAppendable) SegmentSizes() []int64 {
+	mf.mutex.Lock()
+	defer mf.mutex.Unlock()
+
+	if mf.closed {
+		return nil
+	}
+
+	sizes := make([]int64, mf.currAppID+1)
+
+	for appID := int64(0); appID < mf.currAppID; appID++ {
+		size, err := mf.segmentSize(appID)
+		if err != nil {
+			continue
+		}
+
+		sizes[appID] = size
+	}
+
+	sizes[mf.currAppID] = mf.currApp.Offset()
+
+	return sizes
+}
+
+// segmentSize uses the offset instead of the file size as cached segments may hold non-flushed data
+func (mf *MultiFileAppendable) segmentSize(appID int64) (int64, error) {
+	app, err := mf.appendables.Get(appID)
+	if err == nil {
+		return app.Offset(), nil
+	}
+
+	app, err = mf.openAppendable(appendableName(appID, mf.fileExt), false)
+	if err != nil {
+		return 0, err
+	}
+	defer app.Close()
+
+	return app.Offset(), nil
+}
+
 func (mf *MultiFileAppendable) Append(bs []byte) (off int64, n int, err error) {
 	mf.mutex.Lock()
 	defer mf.mutex.Unlock()
@@ -299,6 +403,21 @@ func (mf *MultiFileAppendable) Append(bs []byte) (off int64, n int, err error) {
 		available := mf.fileSize - int(mf.currApp.Offset())
 
 		if available <= 0 {
+			if mf.currChecksum != nil {
+				// the sealed segment is synced so its stored checksum covers all of its content
+				err = syncAppendable(mf.currApp)
+				if err != nil {
+					return off, n, err
+				}
+
+				err = mf.storeChecksum(false)
+				if err != nil {
+					return off, n, err
+				}
+
+				mf.currChecksum = newSegmentChecksum()
+			}
+
 			_, ejectedApp, err := mf.appendables.Put(mf.currAppID, mf.currApp)
 			if err != nil {
 				return off, n, err
@@ -306,6 +425,14 @@ func (mf *MultiFileAppendable) Append(bs []byte) (off int64, n int, err error) {
 
 			if ejectedApp != nil {
 				metricsCacheEvicted.Inc()
+
+				if mf.deferredSync() {
+					err = syncAppendable(ejectedApp)
+					if err != nil {
+						return off, n, err
+					}
+				}
+
 				err = ejectedApp.Close()
 				if err != nil {
 					return off, n, err
@@ -342,16 +469,40 @@ func (mf *MultiFileAppendable) Append(bs []byte) (off int64, n int, err error) {
 			off = offn + mf.currAppID*int64(mf.fileSize)
 		}
 
+		if mf.currChecksum != nil {
+			mf.currChecksum.Write(bs[n : n+d])
+		}
+
 		n += d
+		mf.writtenBytes += int64(d)
+		mf.unflushedBytes += int64(d)
 	}
 
 	return
 }
 
+// WrittenBytes returns the number of bytes appended through this instance since it was opened
+func (mf *MultiFileAppendable) WrittenBytes() int64 {
+	mf.mutex.Lock()
+	defer mf.mutex.Unlock()
+
+	return mf.writtenBytes
+}
+
+// BytesWrittenSinceFlush returns the number of bytes appended since the last call to Flush or Sync
+func (mf *MultiFileAppendable) BytesWrittenSinceFlush() int64 {
+	mf.mutex.Lock()
+	defer mf.mutex.Unlock()
+
+	return mf.unflushedBytes
+}
+
 func (mf *MultiFileAppendable) openAppendable(appname string, activeChunk bool) (appendable.Appendable, error) {
 	appendableOpts := singleapp.DefaultOptions().
 		WithReadOnly(mf.readOnly).
-		WithSynced(mf.synced).
+		WithSynced(mf.synced && !mf.deferredSync()).
+		WithDirectIO(mf.directIO).
+		WithMmapSize(mf.mmapSize).
 		WithFileMode(mf.fileMode).
 		WithReadBufferSize(mf.readBufferSize).
 		WithWriteBufferSize(mf.writeBufferSize).
@@ -383,6 +534,16 @@ func (mf *MultiFileAppendable) SetOffset(off int64) error {
 
 	appID := appendableID(off, mf.fileSize)
 
+	prevAppID := mf.currAppID
+	prevOff := mf.currApp.Offset()
+
+	if mf.currChecksum != nil {
+		err := mf.currApp.Flush()
+		if err != nil {
+			return err
+		}
+	}
+
 	if mf.currAppID != appID {
 
 		// Head might have moved back, this means that all
@@ -409,9 +570,25 @@ func (mf *MultiFileAppendable) SetOffset(off int64) error {
 
 		mf.currAppID = appID
 		mf.currApp = app
+
+		// segments from the new head onwards will be written again
+		for id := range mf.verifiedSegments {
+			if id >= appID {
+				delete(mf.verifiedSegments, id)
+			}
+		}
 	}
 
-	return mf.currApp.SetOffset(off % int64(mf.fileSize))
+	err := mf.currApp.SetOffset(off % int64(mf.fileSize))
+	if err != nil {
+		return err
+	}
+
+	if mf.currChecksum != nil {
+		return mf.moveChecksum(prevAppID, prevOff)
+	}
+
+	return nil
 }
 
 func (mf *MultiFileAppendable) DiscardUpto(off int64) error {
@@ -446,6 +623,122 @@ func (mf *MultiFileAppendable) DiscardUpto(off int64) error {
 		if err != nil && !os.IsNotExist(err) {
 			return err
 		}
+
+		err = os.Remove(mf.checksumPath(i))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+
+		delete(mf.verifiedSegments, i)
+	}
+
+	return nil
+}
+
+// PunchHole releases the disk space used by the range [off, off+length), which may span several segments.
+// The range is read as zeroes afterwards, segments already discarded are skipped
+func (mf *MultiFileAppendable) PunchHole(off, length int64) error {
+	if off < 0 || length < 0 {
+		return ErrIllegalArguments
+	}
+
+	mf.mutex.Lock()
+
+	if mf.closed {
+		mf.mutex.Unlock()
+		return ErrAlreadyClosed
+	}
+
+	if mf.readOnly {
+		mf.mutex.Unlock()
+		return ErrReadOnly
+	}
+
+	if mf.offset() < off+length {
+		mf.mutex.Unlock()
+		return fmt.Errorf("%w: hole beyond existent data boundaries", ErrIllegalArguments)
+	}
+
+	currAppID := mf.currAppID
+
+	mf.mutex.Unlock()
+
+	for length > 0 {
+		appID := appendableID(off, mf.fileSize)
+		segOff := off % int64(mf.fileSize)
+
+		n := int64(mf.fileSize) - segOff
+		if n > length {
+			n = length
+		}
+
+		off += n
+		length -= n
+
+		if appID < currAppID {
+			_, err := os.Stat(filepath.Join(mf.path, appendableName(appID, mf.fileExt)))
+			if os.IsNotExist(err) {
+				continue
+			}
+		}
+
+		app, err := mf.appendableFor(appID * int64(mf.fileSize))
+		if err != nil {
+			return err
+		}
+
+		err = app.PunchHole(segOff, n)
+		if err != nil {
+			return err
+		}
+
+		if mf.checksumOnRead {
+			err = mf.refreshChecksum(appID, app)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// SetFilePermissions changes the permissions of the file backing the sealed segment segmentIndex.
+// ErrSegmentIsActive is returned when segmentIndex refers to the segment currently being written
+func (mf *MultiFileAppendable) SetFilePermissions(segmentIndex int, perm os.FileMode) error {
+	mf.mutex.Lock()
+	defer mf.mutex.Unlock()
+
+	if mf.closed {
+		return ErrAlreadyClosed
+	}
+
+	if segmentIndex < 0 || int64(segmentIndex) > mf.currAppID {
+		return ErrIllegalArguments
+	}
+
+	if int64(segmentIndex) == mf.currAppID {
+		return ErrSegmentIsActive
+	}
+
+	return os.Chmod(filepath.Join(mf.path, appendableName(int64(segmentIndex), mf.fileExt)), perm)
+}
+
+// SetAllSealedFilePermissions changes the permissions of the files backing every sealed segment,
+// segments already discarded are skipped
+func (mf *MultiFileAppendable) SetAllSealedFilePermissions(perm os.FileMode) error {
+	mf.mutex.Lock()
+	defer mf.mutex.Unlock()
+
+	if mf.closed {
+		return ErrAlreadyClosed
+	}
+
+	for appID := int64(0); appID < mf.currAppID; appID++ {
+		err := os.Chmod(filepath.Join(mf.path, appendableName(appID, mf.fileExt)), perm)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
 	}
 
 	return nil
@@ -480,6 +773,14 @@ func (mf *MultiFileAppendable) appendableFor(off int64) (appendable.Appendable,
 			return nil, err
 		}
 
+		if mf.checksumOnRead {
+			err = mf.verifySealedSegment(appID, app)
+			if err != nil {
+				app.Close()
+				return nil, err
+			}
+		}
+
 		_, ejectedApp, err := mf.appendables.Put(appID, app)
 		if err != nil {
 			return nil, err
@@ -487,6 +788,15 @@ func (mf *MultiFileAppendable) appendableFor(off int64) (appendable.Appendable,
 
 		if ejectedApp != nil {
 			metricsCacheEvicted.Inc()
+
+			// the ejected segment may have been sealed without being synced yet
+			if mf.deferredSync() {
+				err = syncAppendable(ejectedApp)
+				if err != nil {
+					return nil, err
+				}
+			}
+
 			err = ejectedApp.Close()
 			if err != nil {
 				return nil, err
@@ -499,11 +809,26 @@ func (mf *MultiFileAppendable) appendableFor(off int64) (appendable.Appendable,
 	return app, nil
 }
 
+func (mf *MultiFileAppendable) acquireReadSlot() {
+	if mf.readSem != nil {
+		mf.readSem <- struct{}{}
+	}
+}
+
+func (mf *MultiFileAppendable) releaseReadSlot() {
+	if mf.readSem != nil {
+		<-mf.readSem
+	}
+}
+
 func (mf *MultiFileAppendable) ReadAt(bs []byte, off int64) (int, error) {
 	if len(bs) == 0 {
 		return 0, ErrIllegalArguments
 	}
 
+	mf.acquireReadSlot()
+	defer mf.releaseReadSlot()
+
 	metricsReads.Inc()
 
 	r := 0
@@ -536,6 +861,101 @@ func (mf *MultiFileAppendable) ReadAt(bs []byte, off int64) (int, error) {
 	return r, nil
 }
 
+// ReadSegmentAt reads from the segment with the specified index, off is relative to the beginning of the segment.
+// Unlike ReadAt, reads are not continued on the following segment
+func (mf *MultiFileAppendable) ReadSegmentAt(segmentIndex int, off int64, bs []byte) (int, error) {
+	if len(bs) == 0 || off < 0 {
+		return 0, ErrIllegalArguments
+	}
+
+	mf.mutex.Lock()
+
+	if mf.closed {
+		mf.mutex.Unlock()
+		return 0, ErrAlreadyClosed
+	}
+
+	currAppID := mf.currAppID
+
+	mf.mutex.Unlock()
+
+	if segmentIndex < 0 || int64(segmentIndex) > currAppID {
+		return 0, ErrInvalidSegmentIndex
+	}
+
+	mf.acquireReadSlot()
+	defer mf.releaseReadSlot()
+
+	app, err := mf.appendableFor(int64(segmentIndex) * int64(mf.fileSize))
+	if err != nil {
+		return 0, err
+	}
+
+	return app.ReadAt(bs, off)
+}
+
+// ReadSequentially reads the whole content from offset 0 up to Size() in chunks of one segment file size,
+// aligned to segment boundaries, and passes each of them to fn. The chunk is only valid during the call to fn.
+// Only flushed data is read. An error returned by fn stops the scan and is returned
+func (mf *MultiFileAppendable) ReadSequentially(fn func(off int64, bs []byte) error) error {
+	if fn == nil {
+		return ErrIllegalArguments
+	}
+
+	size, err := mf.Size()
+	if err != nil {
+		return err
+	}
+
+	bs := make([]byte, mf.fileSize)
+
+	for off := int64(0); off < size; off += int64(mf.fileSize) {
+		chunkSize := int64(mf.fileSize)
+		if size-off < chunkSize {
+			chunkSize = size - off
+		}
+
+		n, err := mf.ReadAt(bs[:chunkSize], off)
+		if err != nil && err != io.EOF {
+			return err
+		}
+
+		if n > 0 {
+			ferr := fn(off, bs[:n])
+			if ferr != nil {
+				return ferr
+			}
+		}
+
+		if err == io.EOF {
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// Checksum returns the SHA-256 of the whole content from offset 0 up to Size(), as read by ReadSequentially.
+// Its cost is O(N) in the size of the appendable, it's meant to verify offline copies and not to be called
+// while the appendable is being written
+func (mf *MultiFileAppendable) Checksum() ([sha256.Size]byte, error) {
+	var checksum [sha256.Size]byte
+
+	h := sha256.New()
+
+	err := mf.ReadSequentially(func(_ int64, bs []byte) error {
+		_, err := h.Write(bs)
+		return err
+	})
+	if err != nil {
+		return checksum, err
+	}
+
+	copy(checksum[:], h.Sum(nil))
+
+	return checksum, nil
+}
+
 func (mf *MultiFileAppendable) Flush() error {
 	mf.mutex.Lock()
 	defer mf.mutex.Unlock()
@@ -555,7 +975,44 @@ func (mf *MultiFileAppendable) flush() error {
 		return err
 	}
 
-	return mf.currApp.Flush()
+	err = mf.currApp.Flush()
+	if err != nil {
+		return err
+	}
+
+	// flushed content is only synced when syncs are not deferred, otherwise the checksum is stored on sync
+	if mf.synced && !mf.deferredSync() {
+		err = mf.storeChecksum(false)
+		if err != nil {
+			return err
+		}
+	}
+
+	if mf.deferredSync() && mf.unflushedBytes > 0 {
+		mf.pendingSyncs++
+
+		if mf.pendingSyncs >= mf.bufferedSync {
+			return mf.sync()
+		}
+	}
+
+	mf.unflushedBytes = 0
+
+	return nil
+}
+
+// deferredSync returns true when fsyncs are batched instead of being done on each flush
+func (mf *MultiFileAppendable) deferredSync() bool {
+	return mf.synced && !mf.readOnly && mf.bufferedSync > 1
+}
+
+func syncAppendable(app appendable.Appendable) error {
+	err := app.Flush()
+	if err != nil {
+		return err
+	}
+
+	return app.Sync()
 }
 
 func (mf *MultiFileAppendable) FlushWithId(appID int64) error {
@@ -586,7 +1043,112 @@ func (mf *MultiFileAppendable) sync() error {
 		return err
 	}
 
-	return mf.currApp.Sync()
+	err = mf.currApp.Sync()
+	if err != nil {
+		return err
+	}
+
+	err = mf.storeChecksum(true)
+	if err != nil {
+		return err
+	}
+
+	mf.unflushedBytes = 0
+	mf.pendingSyncs = 0
+
+	return nil
+}
+
+// SyncSegment fsyncs the segment with the specified index without syncing the remaining ones.
+// Sealed segments no longer kept opened were already flushed when evicted, their files are synced directly
+func (mf *MultiFileAppendable) SyncSegment(segmentIndex int) error {
+	mf.mutex.Lock()
+	defer mf.mutex.Unlock()
+
+	if mf.closed {
+		return ErrAlreadyClosed
+	}
+
+	if mf.readOnly {
+		return ErrReadOnly
+	}
+
+	if segmentIndex < 0 || int64(segmentIndex) > mf.currAppID {
+		return ErrInvalidSegmentIndex
+	}
+
+	if int64(segmentIndex) == mf.currAppID {
+		return mf.currApp.Sync()
+	}
+
+	app, err := mf.appendables.Get(int64(segmentIndex))
+	if err == nil {
+		return app.Sync()
+	}
+	if err != cache.ErrKeyNotFound {
+		return err
+	}
+
+	f, err := os.Open(filepath.Join(mf.path, appendableName(int64(segmentIndex), mf.fileExt)))
+	if err != nil {
+		return err
+	}
+
+	err = f.Sync()
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// ActiveSegmentID returns the index of the segment currently being written
+func (mf *MultiFileAppendable) ActiveSegmentID() int {
+	mf.mutex.Lock()
+	defer mf.mutex.Unlock()
+
+	return int(mf.currAppID)
+}
+
+// HardLink creates destPath as a hard link of the file backing the sealed segment segmentIndex,
+// so the segment can be archived without using additional disk space.
+// When hard links can not be created (e.g. destPath is in a different filesystem) the file is copied instead
+func (mf *MultiFileAppendable) HardLink(segmentIndex int, destPath string) error {
+	mf.mutex.Lock()
+	defer mf.mutex.Unlock()
+
+	if mf.closed {
+		return ErrAlreadyClosed
+	}
+
+	if segmentIndex < 0 || int64(segmentIndex) > mf.currAppID {
+		return ErrInvalidSegmentIndex
+	}
+
+	if int64(segmentIndex) == mf.currAppID {
+		return ErrSegmentIsActive
+	}
+
+	app, err := mf.appendables.Get(int64(segmentIndex))
+	if err == nil {
+		err = app.Flush()
+	}
+	if err != nil && err != cache.ErrKeyNotFound {
+		return err
+	}
+
+	srcPath := filepath.Join(mf.path, appendableName(int64(segmentIndex), mf.fileExt))
+
+	err = os.Link(srcPath, destPath)
+	if err == nil || os.IsExist(err) {
+		return err
+	}
+
+	log.Printf("WARNING: unable to hard link segment %d to '%s', falling back to copy: %v", segmentIndex, destPath, err)
+
+	_, err = copyFile(srcPath, destPath)
+	return err
 }
 
 func (mf *MultiFileAppendable) Close() error {
@@ -597,6 +1159,32 @@ func (mf *MultiFileAppendable) Close() error {
 		return ErrAlreadyClosed
 	}
 
+	if mf.deferredSync() {
+		err := mf.appendables.Apply(func(k int64, v appendable.Appendable) error {
+			return syncAppendable(v)
+		})
+		if err != nil {
+			return err
+		}
+
+		err = syncAppendable(mf.currApp)
+		if err != nil {
+			return err
+		}
+	}
+
+	if mf.currChecksum != nil {
+		err := syncAppendable(mf.currApp)
+		if err != nil {
+			return err
+		}
+
+		err = mf.storeChecksum(true)
+		if err != nil {
+			return err
+		}
+	}
+
 	mf.closed = true
 
 	err := mf.appendables.Apply(func(k int64, v appendable.Appendable) error {
@@ -16,11 +16,17 @@ limitations under the License.
 package multiapp
 
 import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"hash/crc32"
 	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/codenotary/immudb/embedded/appendable"
 	"github.com/codenotary/immudb/embedded/appendable/singleapp"
@@ -73,9 +79,23 @@ func TestMultiApp(t *testing.T) {
 	require.Equal(t, int64(4), off)
 	require.Equal(t, 7, n)
 
+	require.Equal(t, int64(11), a.WrittenBytes())
+	require.Equal(t, int64(11), a.BytesWrittenSinceFlush())
+
 	err = a.Flush()
 	require.NoError(t, err)
 
+	require.Equal(t, int64(11), a.WrittenBytes())
+	require.Zero(t, a.BytesWrittenSinceFlush())
+
+	_, _, err = a.Append([]byte{11})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), a.BytesWrittenSinceFlush())
+
+	err = a.Sync()
+	require.NoError(t, err)
+	require.Zero(t, a.BytesWrittenSinceFlush())
+
 	bs := make([]byte, 4)
 	n, err = a.ReadAt(bs, 0)
 	require.NoError(t, err)
@@ -368,3 +388,670 @@ func TestMultiAppDiscard(t *testing.T) {
 	err = a.Close()
 	require.NoError(t, err)
 }
+
+func TestMultiAppSegmentSizes(t *testing.T) {
+	a, err := Open("testdata_segment_sizes", DefaultOptions().WithFileSize(4).WithMaxOpenedFiles(1))
+	defer os.RemoveAll("testdata_segment_sizes")
+	require.NoError(t, err)
+
+	require.Equal(t, []int64{0}, a.SegmentSizes())
+
+	_, _, err = a.Append([]byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10})
+	require.NoError(t, err)
+
+	require.Equal(t, []int64{4, 4, 2}, a.SegmentSizes())
+
+	err = a.DiscardUpto(8)
+	require.NoError(t, err)
+
+	require.Equal(t, []int64{0, 0, 2}, a.SegmentSizes())
+
+	err = a.Close()
+	require.NoError(t, err)
+
+	require.Nil(t, a.SegmentSizes())
+
+	a, err = Open("testdata_segment_sizes", DefaultOptions().WithFileSize(4))
+	require.NoError(t, err)
+
+	require.Equal(t, []int64{0, 0, 2}, a.SegmentSizes())
+
+	err = a.Close()
+	require.NoError(t, err)
+}
+
+func TestMultiAppSetFilePermissions(t *testing.T) {
+	a, err := Open("testdata_file_permissions", DefaultOptions().WithFileSize(4).WithFileExt("aof"))
+	defer os.RemoveAll("testdata_file_permissions")
+	require.NoError(t, err)
+
+	_, _, err = a.Append([]byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10})
+	require.NoError(t, err)
+
+	err = a.SetFilePermissions(-1, 0444)
+	require.ErrorIs(t, err, ErrIllegalArguments)
+
+	err = a.SetFilePermissions(3, 0444)
+	require.ErrorIs(t, err, ErrIllegalArguments)
+
+	err = a.SetFilePermissions(2, 0444)
+	require.ErrorIs(t, err, ErrSegmentIsActive)
+
+	err = a.SetFilePermissions(0, 0400)
+	require.NoError(t, err)
+
+	fi, err := os.Stat("testdata_file_permissions/00000000.aof")
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0400), fi.Mode().Perm())
+
+	err = a.SetAllSealedFilePermissions(0444)
+	require.NoError(t, err)
+
+	for _, name := range []string{"00000000.aof", "00000001.aof"} {
+		fi, err := os.Stat("testdata_file_permissions/" + name)
+		require.NoError(t, err)
+		require.Equal(t, os.FileMode(0444), fi.Mode().Perm())
+	}
+
+	fi, err = os.Stat("testdata_file_permissions/00000002.aof")
+	require.NoError(t, err)
+	require.NotEqual(t, os.FileMode(0444), fi.Mode().Perm())
+
+	err = a.Close()
+	require.NoError(t, err)
+
+	err = a.SetFilePermissions(0, 0444)
+	require.ErrorIs(t, err, ErrAlreadyClosed)
+
+	err = a.SetAllSealedFilePermissions(0444)
+	require.ErrorIs(t, err, ErrAlreadyClosed)
+}
+
+func TestMultiAppSegmentHeaderSize(t *testing.T) {
+	defer os.RemoveAll("testdata_segment_header_size")
+
+	_, err := Open("testdata_segment_header_size", DefaultOptions().WithSegmentHeaderSize(16))
+	require.ErrorIs(t, err, ErrMetadataTooLarge)
+
+	_, err = os.Stat("testdata_segment_header_size")
+	require.True(t, os.IsNotExist(err))
+
+	a, err := Open("testdata_segment_header_size", DefaultOptions().WithSegmentHeaderSize(1024))
+	require.NoError(t, err)
+
+	err = a.Close()
+	require.NoError(t, err)
+
+	_, err = Open("testdata_segment_header_size", DefaultOptions().
+		WithSegmentHeaderSize(1024).
+		WithMetadata(make([]byte, 1024)))
+	require.ErrorIs(t, err, ErrMetadataTooLarge)
+}
+
+func TestMultiAppReadSegmentAt(t *testing.T) {
+	a, err := Open("testdata_read_segment_at", DefaultOptions().WithFileSize(4).WithMaxOpenedFiles(1))
+	defer os.RemoveAll("testdata_read_segment_at")
+	require.NoError(t, err)
+
+	_, _, err = a.Append([]byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10})
+	require.NoError(t, err)
+
+	err = a.Flush()
+	require.NoError(t, err)
+
+	bs := make([]byte, 4)
+
+	_, err = a.ReadSegmentAt(0, 0, nil)
+	require.ErrorIs(t, err, ErrIllegalArguments)
+
+	_, err = a.ReadSegmentAt(0, -1, bs)
+	require.ErrorIs(t, err, ErrIllegalArguments)
+
+	_, err = a.ReadSegmentAt(-1, 0, bs)
+	require.ErrorIs(t, err, ErrInvalidSegmentIndex)
+
+	_, err = a.ReadSegmentAt(3, 0, bs)
+	require.ErrorIs(t, err, ErrInvalidSegmentIndex)
+
+	n, err := a.ReadSegmentAt(0, 0, bs)
+	require.NoError(t, err)
+	require.Equal(t, 4, n)
+	require.Equal(t, []byte{1, 2, 3, 4}, bs)
+
+	n, err = a.ReadSegmentAt(1, 1, bs[:2])
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+	require.Equal(t, []byte{6, 7}, bs[:2])
+
+	n, err = a.ReadSegmentAt(2, 0, bs)
+	require.ErrorIs(t, err, io.EOF)
+	require.Equal(t, 2, n)
+	require.Equal(t, []byte{9, 10}, bs[:2])
+
+	err = a.Close()
+	require.NoError(t, err)
+
+	_, err = a.ReadSegmentAt(0, 0, bs)
+	require.ErrorIs(t, err, ErrAlreadyClosed)
+}
+
+func TestMultiAppSyncSegment(t *testing.T) {
+	a, err := Open("testdata_sync_segment", DefaultOptions().WithFileSize(4).WithMaxOpenedFiles(1))
+	defer os.RemoveAll("testdata_sync_segment")
+	require.NoError(t, err)
+
+	require.Equal(t, 0, a.ActiveSegmentID())
+
+	_, _, err = a.Append([]byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10})
+	require.NoError(t, err)
+
+	require.Equal(t, 2, a.ActiveSegmentID())
+
+	err = a.SyncSegment(-1)
+	require.ErrorIs(t, err, ErrInvalidSegmentIndex)
+
+	err = a.SyncSegment(3)
+	require.ErrorIs(t, err, ErrInvalidSegmentIndex)
+
+	for i := 0; i <= a.ActiveSegmentID(); i++ {
+		err = a.SyncSegment(i)
+		require.NoError(t, err)
+	}
+
+	err = a.Close()
+	require.NoError(t, err)
+
+	err = a.SyncSegment(0)
+	require.ErrorIs(t, err, ErrAlreadyClosed)
+
+	a, err = Open("testdata_sync_segment", DefaultOptions().WithFileSize(4).WithReadOnly(true))
+	require.NoError(t, err)
+
+	err = a.SyncSegment(0)
+	require.ErrorIs(t, err, ErrReadOnly)
+
+	err = a.Close()
+	require.NoError(t, err)
+}
+
+func TestMultiAppHardLink(t *testing.T) {
+	a, err := Open("testdata_hard_link", DefaultOptions().WithFileSize(4).WithMaxOpenedFiles(1))
+	defer os.RemoveAll("testdata_hard_link")
+	require.NoError(t, err)
+
+	err = os.MkdirAll("testdata_hard_link_archive", 0700)
+	require.NoError(t, err)
+	defer os.RemoveAll("testdata_hard_link_archive")
+
+	_, _, err = a.Append([]byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10})
+	require.NoError(t, err)
+
+	err = a.HardLink(-1, "testdata_hard_link_archive/segment")
+	require.ErrorIs(t, err, ErrInvalidSegmentIndex)
+
+	err = a.HardLink(3, "testdata_hard_link_archive/segment")
+	require.ErrorIs(t, err, ErrInvalidSegmentIndex)
+
+	err = a.HardLink(2, "testdata_hard_link_archive/segment")
+	require.ErrorIs(t, err, ErrSegmentIsActive)
+
+	for i := 0; i < 2; i++ {
+		segmentPath := fmt.Sprintf("testdata_hard_link/%08d.aof", i)
+		archivePath := fmt.Sprintf("testdata_hard_link_archive/%08d.aof", i)
+
+		err = a.HardLink(i, archivePath)
+		require.NoError(t, err)
+
+		segmentFi, err := os.Stat(segmentPath)
+		require.NoError(t, err)
+
+		archiveFi, err := os.Stat(archivePath)
+		require.NoError(t, err)
+
+		require.True(t, os.SameFile(segmentFi, archiveFi))
+
+		err = a.HardLink(i, archivePath)
+		require.True(t, os.IsExist(err))
+	}
+
+	err = a.Close()
+	require.NoError(t, err)
+
+	err = a.HardLink(0, "testdata_hard_link_archive/segment")
+	require.ErrorIs(t, err, ErrAlreadyClosed)
+}
+
+func TestMultiAppBufferedSync(t *testing.T) {
+	a, err := Open("testdata_buffered_sync", DefaultOptions().WithFileSize(4).WithMaxOpenedFiles(1).WithBufferedSync(3))
+	defer os.RemoveAll("testdata_buffered_sync")
+	require.NoError(t, err)
+
+	for i := 1; i <= 4; i++ {
+		_, _, err = a.Append([]byte{1, 2, 3})
+		require.NoError(t, err)
+
+		err = a.Flush()
+		require.NoError(t, err)
+
+		require.Equal(t, i%3, a.pendingSyncs)
+	}
+
+	// flushing without appended data is not accounted
+	err = a.Flush()
+	require.NoError(t, err)
+	require.Equal(t, 1, a.pendingSyncs)
+
+	err = a.Sync()
+	require.NoError(t, err)
+	require.Zero(t, a.pendingSyncs)
+
+	_, _, err = a.Append([]byte{4, 5, 6})
+	require.NoError(t, err)
+
+	err = a.Close()
+	require.NoError(t, err)
+
+	a, err = Open("testdata_buffered_sync", DefaultOptions().WithFileSize(4).WithReadOnly(true))
+	require.NoError(t, err)
+
+	bs := make([]byte, 15)
+	_, err = a.ReadAt(bs, 0)
+	require.NoError(t, err)
+	require.Equal(t, []byte{1, 2, 3, 1, 2, 3, 1, 2, 3, 1, 2, 3, 4, 5, 6}, bs)
+
+	err = a.Close()
+	require.NoError(t, err)
+}
+
+func TestMultiAppMaxConcurrentReads(t *testing.T) {
+	a, err := Open("testdata_max_concurrent_reads", DefaultOptions().WithFileSize(4).WithMaxConcurrentReads(2))
+	defer os.RemoveAll("testdata_max_concurrent_reads")
+	require.NoError(t, err)
+
+	_, _, err = a.Append([]byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10})
+	require.NoError(t, err)
+
+	err = a.Flush()
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			bs := make([]byte, 10)
+
+			_, err := a.ReadAt(bs, 0)
+			require.NoError(t, err)
+			require.Equal(t, []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, bs)
+		}()
+	}
+
+	wg.Wait()
+
+	// take every slot so further reads block until one is released
+	a.acquireReadSlot()
+	a.acquireReadSlot()
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		bs := make([]byte, 2)
+
+		_, err := a.ReadSegmentAt(1, 0, bs)
+		require.NoError(t, err)
+		require.Equal(t, []byte{5, 6}, bs)
+	}()
+
+	select {
+	case <-done:
+		require.Fail(t, "read should be blocked")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	a.releaseReadSlot()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "read should be completed")
+	}
+
+	a.releaseReadSlot()
+
+	err = a.Close()
+	require.NoError(t, err)
+}
+
+func TestMultiAppReadSequentially(t *testing.T) {
+	a, err := Open("testdata_read_sequentially", DefaultOptions().WithFileSize(4))
+	defer os.RemoveAll("testdata_read_sequentially")
+	require.NoError(t, err)
+
+	err = a.ReadSequentially(nil)
+	require.ErrorIs(t, err, ErrIllegalArguments)
+
+	err = a.ReadSequentially(func(off int64, bs []byte) error {
+		require.Fail(t, "no content expected")
+		return nil
+	})
+	require.NoError(t, err)
+
+	_, _, err = a.Append([]byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10})
+	require.NoError(t, err)
+
+	err = a.Flush()
+	require.NoError(t, err)
+
+	var offs []int64
+	var content []byte
+
+	err = a.ReadSequentially(func(off int64, bs []byte) error {
+		offs = append(offs, off)
+		content = append(content, bs...)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []int64{0, 4, 8}, offs)
+	require.Equal(t, []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, content)
+
+	errStop := errors.New("stop")
+	calls := 0
+
+	err = a.ReadSequentially(func(off int64, bs []byte) error {
+		calls++
+		return errStop
+	})
+	require.ErrorIs(t, err, errStop)
+	require.Equal(t, 1, calls)
+
+	err = a.Close()
+	require.NoError(t, err)
+
+	err = a.ReadSequentially(func(off int64, bs []byte) error { return nil })
+	require.ErrorIs(t, err, ErrAlreadyClosed)
+}
+
+func TestMultiAppPunchHole(t *testing.T) {
+	a, err := Open("testdata_punch_hole", DefaultOptions().WithFileSize(4))
+	defer os.RemoveAll("testdata_punch_hole")
+	require.NoError(t, err)
+
+	content := []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+
+	_, _, err = a.Append(content)
+	require.NoError(t, err)
+
+	err = a.Flush()
+	require.NoError(t, err)
+
+	err = a.PunchHole(-1, 1)
+	require.ErrorIs(t, err, ErrIllegalArguments)
+
+	err = a.PunchHole(8, 3)
+	require.ErrorIs(t, err, ErrIllegalArguments)
+
+	// the hole spans the three segments
+	err = a.PunchHole(2, 7)
+	require.NoError(t, err)
+
+	bs := make([]byte, len(content))
+	_, err = a.ReadAt(bs, 0)
+	require.NoError(t, err)
+	require.Equal(t, content[:2], bs[:2])
+	require.Equal(t, content[9:], bs[9:])
+
+	sz, err := a.Size()
+	require.NoError(t, err)
+	require.Equal(t, int64(len(content)), sz)
+
+	err = a.DiscardUpto(4)
+	require.NoError(t, err)
+
+	// discarded segments are skipped
+	err = a.PunchHole(0, 6)
+	require.NoError(t, err)
+
+	_, err = os.Stat(filepath.Join("testdata_punch_hole", appendableName(0, a.fileExt)))
+	require.True(t, os.IsNotExist(err))
+
+	err = a.Close()
+	require.NoError(t, err)
+
+	err = a.PunchHole(0, 1)
+	require.ErrorIs(t, err, ErrAlreadyClosed)
+}
+
+func TestMultiAppChecksum(t *testing.T) {
+	a, err := Open("testdata_checksum", DefaultOptions().WithFileSize(4))
+	defer os.RemoveAll("testdata_checksum")
+	require.NoError(t, err)
+
+	checksum, err := a.Checksum()
+	require.NoError(t, err)
+	require.Equal(t, sha256.Sum256(nil), checksum)
+
+	content := []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+
+	_, _, err = a.Append(content)
+	require.NoError(t, err)
+
+	err = a.Flush()
+	require.NoError(t, err)
+
+	checksum, err = a.Checksum()
+	require.NoError(t, err)
+	require.Equal(t, sha256.Sum256(content), checksum)
+
+	err = a.Copy("testdata_checksum_copy")
+	defer os.RemoveAll("testdata_checksum_copy")
+	require.NoError(t, err)
+
+	b, err := Open("testdata_checksum_copy", DefaultOptions().WithFileSize(4).WithReadOnly(true))
+	require.NoError(t, err)
+
+	copyChecksum, err := b.Checksum()
+	require.NoError(t, err)
+	require.Equal(t, checksum, copyChecksum)
+
+	err = b.Close()
+	require.NoError(t, err)
+
+	err = a.Close()
+	require.NoError(t, err)
+
+	_, err = a.Checksum()
+	require.ErrorIs(t, err, ErrAlreadyClosed)
+}
+
+func corruptLastByte(t *testing.T, path string) {
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	require.NoError(t, err)
+	defer f.Close()
+
+	stat, err := f.Stat()
+	require.NoError(t, err)
+
+	b := make([]byte, 1)
+	_, err = f.ReadAt(b, stat.Size()-1)
+	require.NoError(t, err)
+
+	b[0]++
+
+	_, err = f.WriteAt(b, stat.Size()-1)
+	require.NoError(t, err)
+}
+
+func TestMultiAppChecksumOnRead(t *testing.T) {
+	dir := "testdata_checksum_on_read"
+	defer os.RemoveAll(dir)
+
+	opts := DefaultOptions().WithFileSize(4).WithChecksumOnRead(true)
+
+	_, err := Open("testdata_checksum_on_read_compressed", DefaultOptions().
+		WithChecksumOnRead(true).
+		WithCompressionFormat(appendable.GZipCompression))
+	defer os.RemoveAll("testdata_checksum_on_read_compressed")
+	require.ErrorIs(t, err, ErrIllegalArguments)
+
+	a, err := Open(dir, opts)
+	require.NoError(t, err)
+
+	content := []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+
+	_, _, err = a.Append(content)
+	require.NoError(t, err)
+
+	err = a.Sync()
+	require.NoError(t, err)
+
+	err = a.Close()
+	require.NoError(t, err)
+
+	_, err = os.Stat(filepath.Join(dir, checksumName(2, opts.fileExt)))
+	require.NoError(t, err)
+
+	// content is verified as segments are opened
+	a, err = Open(dir, opts)
+	require.NoError(t, err)
+
+	bs := make([]byte, len(content))
+	_, err = a.ReadAt(bs, 0)
+	require.NoError(t, err)
+	require.Equal(t, content, bs)
+
+	// sealed segments are not verified again when reopened
+	require.Len(t, a.verifiedSegments, 2)
+
+	// truncated content is checksummed again
+	err = a.SetOffset(9)
+	require.NoError(t, err)
+
+	_, _, err = a.Append([]byte{11, 12})
+	require.NoError(t, err)
+
+	err = a.Flush()
+	require.NoError(t, err)
+
+	err = a.Close()
+	require.NoError(t, err)
+
+	a, err = Open(dir, opts)
+	require.NoError(t, err)
+
+	err = a.Close()
+	require.NoError(t, err)
+
+	corruptLastByte(t, filepath.Join(dir, appendableName(0, opts.fileExt)))
+
+	a, err = Open(dir, opts)
+	require.NoError(t, err)
+
+	_, err = a.ReadAt(bs, 0)
+	require.ErrorIs(t, err, ErrSegmentCorrupt)
+
+	// segments are only verified when checksums are enabled
+	b, err := Open(dir, DefaultOptions().WithFileSize(4).WithReadOnly(true))
+	require.NoError(t, err)
+
+	_, err = b.ReadAt(bs, 0)
+	require.NoError(t, err)
+
+	err = b.Close()
+	require.NoError(t, err)
+
+	err = a.Close()
+	require.NoError(t, err)
+
+	corruptLastByte(t, filepath.Join(dir, appendableName(2, opts.fileExt)))
+
+	_, err = Open(dir, opts)
+	require.ErrorIs(t, err, ErrSegmentCorrupt)
+}
+
+func TestSegmentChecksumUnwrite(t *testing.T) {
+	content := make([]byte, 1000)
+	for i := range content {
+		content[i] = byte(i * 7)
+	}
+
+	for _, n := range []int{0, 1, 500, 1000} {
+		h := newSegmentChecksum()
+		h.Write(content)
+		h.unwrite(content[len(content)-n:])
+
+		require.Equal(t, crc32.Checksum(content[:len(content)-n], checksumTable), h.Sum32())
+	}
+}
+
+func TestMultiAppChecksumBufferedSync(t *testing.T) {
+	dir := "testdata_checksum_buffered_sync"
+	defer os.RemoveAll(dir)
+
+	opts := DefaultOptions().WithFileSize(8).WithBufferedSync(3).WithChecksumOnRead(true)
+
+	a, err := Open(dir, opts)
+	require.NoError(t, err)
+
+	_, _, err = a.Append([]byte{1, 2, 3})
+	require.NoError(t, err)
+
+	err = a.Flush()
+	require.NoError(t, err)
+
+	// flushed content is not synced yet so it's not covered by the stored checksum
+	_, _, ok, err := a.readChecksum(0)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	err = a.Sync()
+	require.NoError(t, err)
+
+	covered, _, ok, err := a.readChecksum(0)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.EqualValues(t, 3, covered)
+
+	// truncation rolls the stored checksum back
+	err = a.SetOffset(1)
+	require.NoError(t, err)
+
+	covered, checksum, ok, err := a.readChecksum(0)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.EqualValues(t, 1, covered)
+	require.Equal(t, crc32.Checksum([]byte{1}, checksumTable), checksum)
+
+	_, _, err = a.Append([]byte{4, 5, 6, 7, 8, 9, 10, 11})
+	require.NoError(t, err)
+
+	// sealed segments are synced along with their checksum
+	covered, checksum, ok, err = a.readChecksum(0)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.EqualValues(t, 8, covered)
+	require.Equal(t, crc32.Checksum([]byte{1, 4, 5, 6, 7, 8, 9, 10}, checksumTable), checksum)
+
+	// moving back to a sealed segment starts from its stored checksum
+	err = a.SetOffset(5)
+	require.NoError(t, err)
+
+	_, err = os.Stat(filepath.Join(dir, checksumName(1, opts.fileExt)))
+	require.True(t, os.IsNotExist(err))
+
+	require.Equal(t, crc32.Checksum([]byte{1, 4, 5, 6, 7}, checksumTable), a.currChecksum.Sum32())
+
+	err = a.Close()
+	require.NoError(t, err)
+
+	a, err = Open(dir, opts)
+	require.NoError(t, err)
+
+	err = a.Close()
+	require.NoError(t, err)
+}
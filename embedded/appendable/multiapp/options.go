@@ -32,6 +32,7 @@ const DefaultWriteBufferSize = 4096
 type Options struct {
 	readOnly          bool
 	synced            bool
+	directIO          bool
 	fileMode          os.FileMode
 	fileSize          int
 	fileExt           string
@@ -41,6 +42,14 @@ type Options struct {
 	compressionLevel  int
 	readBufferSize    int
 	writeBufferSize   int
+	segmentHeaderSize int
+	mmapSize          int64
+
+	maxConcurrentReads int
+
+	bufferedSync int
+
+	checksumOnRead bool
 }
 
 func DefaultOptions() *Options {
@@ -64,7 +73,11 @@ func (opts *Options) Valid() bool {
 		opts.maxOpenedFiles > 0 &&
 		opts.fileExt != "" &&
 		opts.readBufferSize > 0 &&
-		opts.writeBufferSize > 0
+		opts.writeBufferSize > 0 &&
+		opts.segmentHeaderSize >= 0 &&
+		opts.mmapSize >= 0 &&
+		opts.maxConcurrentReads >= 0 &&
+		opts.bufferedSync >= 0
 }
 
 func (opt *Options) WithReadOnly(readOnly bool) *Options {
@@ -77,6 +90,12 @@ func (opt *Options) WithSynced(synced bool) *Options {
 	return opt
 }
 
+// WithDirectIO makes the underlying appendables bypass the OS page cache when supported by the platform
+func (opt *Options) WithDirectIO(directIO bool) *Options {
+	opt.directIO = directIO
+	return opt
+}
+
 func (opt *Options) WithFileMode(fileMode os.FileMode) *Options {
 	opt.fileMode = fileMode
 	return opt
@@ -122,6 +141,13 @@ func (opts *Options) WithWriteBufferSize(size int) *Options {
 	return opts
 }
 
+// WithSegmentHeaderSize sets the maximum number of bytes the header of each segment may take,
+// zero means no limit
+func (opts *Options) WithSegmentHeaderSize(size int) *Options {
+	opts.segmentHeaderSize = size
+	return opts
+}
+
 func (opt *Options) GetFileExt() string {
 	return opt.fileExt
 }
@@ -137,3 +163,34 @@ func (opts *Options) GetReadBufferSize() int {
 func (opts *Options) GetWriteBufferSize() int {
 	return opts.writeBufferSize
 }
+
+// WithMmapSize sets the number of bytes of each uncompressed segment to be memory mapped for reading,
+// zero disables memory mapping
+func (opts *Options) WithMmapSize(size int64) *Options {
+	opts.mmapSize = size
+	return opts
+}
+
+// WithMaxConcurrentReads sets the maximum number of reads served at the same time,
+// further reads block until a running one completes. Zero means no limit
+func (opts *Options) WithMaxConcurrentReads(n int) *Options {
+	opts.maxConcurrentReads = n
+	return opts
+}
+
+// WithBufferedSync makes synced appendables fsync once every n flushes of appended data instead of on each flush,
+// explicit calls to Sync and Close are always synced. Values lower than 2 keep syncing on each flush
+func (opts *Options) WithBufferedSync(n int) *Options {
+	opts.bufferedSync = n
+	return opts
+}
+
+// WithChecksumOnRead keeps a CRC32C checksum of the content of each segment, updated on Flush and Sync,
+// and verifies it when a segment is opened so reads from a corrupted segment fail with ErrSegmentCorrupt.
+// Verifying a sealed segment reads all of its content, which is done the first time it's opened since
+// the appendable was opened, so later corruption of an already verified segment is not detected.
+// Checksums are kept next to each segment and are only supported for uncompressed segments
+func (opts *Options) WithChecksumOnRead(checksumOnRead bool) *Options {
+	opts.checksumOnRead = checksumOnRead
+	return opts
+}
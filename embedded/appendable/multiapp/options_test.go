@@ -44,12 +44,27 @@ func TestValidOptions(t *testing.T) {
 	require.Equal(t, DefaultCompressionLevel, opts.WithCompresionLevel(DefaultCompressionLevel).compressionLevel)
 
 	require.True(t, opts.WithSynced(true).synced)
+	require.True(t, opts.WithDirectIO(true).directIO)
 
 	require.False(t, opts.WithReadOnly(false).readOnly)
 
 	require.Equal(t, DefaultReadBufferSize+1, opts.WithReadBufferSize(DefaultReadBufferSize+1).GetReadBufferSize())
 	require.Equal(t, DefaultWriteBufferSize+2, opts.WithWriteBufferSize(DefaultWriteBufferSize+2).GetWriteBufferSize())
 
+	require.False(t, opts.WithSegmentHeaderSize(-1).Valid())
+	require.Equal(t, 128, opts.WithSegmentHeaderSize(128).segmentHeaderSize)
+
+	require.False(t, opts.WithMmapSize(-1).Valid())
+	require.Equal(t, int64(1024), opts.WithMmapSize(1024).mmapSize)
+
+	require.False(t, opts.WithMaxConcurrentReads(-1).Valid())
+	require.Equal(t, 4, opts.WithMaxConcurrentReads(4).maxConcurrentReads)
+
+	require.False(t, opts.WithBufferedSync(-1).Valid())
+	require.Equal(t, 8, opts.WithBufferedSync(8).bufferedSync)
+
+	require.True(t, opts.WithChecksumOnRead(true).checksumOnRead)
+
 	require.True(t, opts.Valid())
 
 	require.True(t, opts.WithReadOnly(true).readOnly)
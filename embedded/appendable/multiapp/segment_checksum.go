@@ -0,0 +1,388 @@
+/*
+Copyright 2022 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package multiapp
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/codenotary/immudb/embedded/appendable"
+)
+
+var ErrSegmentCorrupt = errors.New("segment checksum mismatch")
+
+// ChecksumFileExt is the extension of the file kept next to each segment with the checksum of its content,
+// segments are appended in place so the checksum can not be stored within them
+const ChecksumFileExt = "crc"
+
+// checksumFileSize is the size of a checksum file: the number of covered bytes followed by their CRC32C
+const checksumFileSize = 8 + 4
+
+const checksumReadBufferSize = 64 * 1024
+
+var checksumTable = crc32.MakeTable(crc32.Castagnoli)
+
+// checksumTableIndex maps the most significant byte of each entry of checksumTable to its index,
+// the most significant bytes of a CRC32 table are all different so a CRC32 update can be reverted
+var checksumTableIndex [256]byte
+
+func init() {
+	for i, v := range checksumTable {
+		checksumTableIndex[v>>24] = byte(i)
+	}
+}
+
+// segmentChecksum is the CRC32C of the content of a segment, unlike hash.Hash32 the last bytes
+// written can be removed from it so truncating a segment doesn't require to read it again
+type segmentChecksum struct {
+	crc uint32
+}
+
+func newSegmentChecksum() *segmentChecksum {
+	return &segmentChecksum{}
+}
+
+func (c *segmentChecksum) Write(p []byte) (int, error) {
+	c.crc = crc32.Update(c.crc, checksumTable, p)
+	return len(p), nil
+}
+
+// unwrite removes from the checksum p, the last bytes written to it
+func (c *segmentChecksum) unwrite(p []byte) {
+	crc := ^c.crc
+
+	for i := len(p) - 1; i >= 0; i-- {
+		j := checksumTableIndex[crc>>24]
+		crc = (crc^checksumTable[j])<<8 | uint32(j^p[i])
+	}
+
+	c.crc = ^crc
+}
+
+func (c *segmentChecksum) Sum32() uint32 {
+	return c.crc
+}
+
+func checksumName(appID int64, ext string) string {
+	return appendableName(appID, ext) + "." + ChecksumFileExt
+}
+
+func (mf *MultiFileAppendable) checksumPath(appID int64) string {
+	return filepath.Join(mf.path, checksumName(appID, mf.fileExt))
+}
+
+// readChecksum returns the number of bytes covered by the checksum of the segment and their checksum,
+// ok is false when the segment has no checksum e.g. it was written without checksums enabled
+func (mf *MultiFileAppendable) readChecksum(appID int64) (size int64, checksum uint32, ok bool, err error) {
+	f, err := os.Open(mf.checksumPath(appID))
+	if os.IsNotExist(err) {
+		return 0, 0, false, nil
+	}
+	if err != nil {
+		return 0, 0, false, err
+	}
+	defer f.Close()
+
+	var b [checksumFileSize]byte
+
+	_, err = io.ReadFull(f, b[:])
+	if err == io.EOF {
+		// the checksum file was created but its content not persisted before a crash
+		return 0, 0, false, nil
+	}
+	if err != nil {
+		return 0, 0, false, fmt.Errorf("%w: unreadable checksum of segment %d", ErrSegmentCorrupt, appID)
+	}
+
+	return int64(binary.BigEndian.Uint64(b[:])), binary.BigEndian.Uint32(b[8:]), true, nil
+}
+
+// writeChecksum overwrites the checksum of the segment in place, the write fits in a single disk sector
+func (mf *MultiFileAppendable) writeChecksum(appID int64, size int64, checksum uint32, sync bool) error {
+	f, err := os.OpenFile(mf.checksumPath(appID), os.O_CREATE|os.O_WRONLY, mf.fileMode)
+	if err != nil {
+		return err
+	}
+
+	var b [checksumFileSize]byte
+
+	binary.BigEndian.PutUint64(b[:], uint64(size))
+	binary.BigEndian.PutUint32(b[8:], checksum)
+
+	_, err = f.WriteAt(b[:], 0)
+	if err == nil && sync {
+		err = f.Sync()
+	}
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// hashSegment feeds h with the flushed content of app in the range [from, to)
+func hashSegment(h io.Writer, app appendable.Appendable, from, to int64) error {
+	bs := make([]byte, checksumReadBufferSize)
+
+	for off := from; off < to; {
+		n := int64(len(bs))
+		if to-off < n {
+			n = to - off
+		}
+
+		rn, err := app.ReadAt(bs[:n], off)
+		h.Write(bs[:rn])
+		off += int64(rn)
+
+		if err == io.EOF && int64(rn) == n {
+			continue
+		}
+		if err == io.EOF {
+			return io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// verifySegment checks the content of the segment against its checksum and returns the checksum
+// of its content up to size, which may go beyond the bytes covered by the stored checksum
+func (mf *MultiFileAppendable) verifySegment(appID int64, app appendable.Appendable, size int64) (*segmentChecksum, error) {
+	covered, checksum, ok, err := mf.readChecksum(appID)
+	if err != nil {
+		return nil, err
+	}
+
+	h := newSegmentChecksum()
+
+	if ok {
+		if covered > size {
+			return nil, fmt.Errorf("%w: segment %d is shorter than its checksummed content", ErrSegmentCorrupt, appID)
+		}
+
+		err = hashSegment(h, app, 0, covered)
+		if err == io.ErrUnexpectedEOF {
+			return nil, fmt.Errorf("%w: segment %d is shorter than its checksummed content", ErrSegmentCorrupt, appID)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if h.Sum32() != checksum {
+			return nil, fmt.Errorf("%w: segment %d", ErrSegmentCorrupt, appID)
+		}
+	} else {
+		covered = 0
+	}
+
+	err = hashSegment(h, app, covered, size)
+	if err != nil {
+		return nil, err
+	}
+
+	return h, nil
+}
+
+// verifySealedSegment is called when a sealed segment is opened for reading. Sealed segments are not
+// modified, so each one is only verified the first time it's opened, not when reopened after being
+// evicted from the cache of open segments
+func (mf *MultiFileAppendable) verifySealedSegment(appID int64, app appendable.Appendable) error {
+	if _, verified := mf.verifiedSegments[appID]; verified {
+		return nil
+	}
+
+	size, err := app.Size()
+	if err != nil {
+		return err
+	}
+
+	_, err = mf.verifySegment(appID, app, size)
+	if err != nil {
+		return err
+	}
+
+	mf.verifiedSegments[appID] = struct{}{}
+
+	return nil
+}
+
+// initChecksum verifies the active segment and sets up the incremental checksum of its content
+func (mf *MultiFileAppendable) initChecksum() error {
+	if mf.currApp.CompressionFormat() != appendable.NoCompression {
+		return fmt.Errorf("%w: segment checksums are not supported with compression", ErrIllegalArguments)
+	}
+
+	h, err := mf.verifySegment(mf.currAppID, mf.currApp, mf.currApp.Offset())
+	if err != nil {
+		return err
+	}
+
+	// the incremental checksum is only needed to append
+	if !mf.readOnly {
+		mf.currChecksum = h
+	}
+
+	return nil
+}
+
+// unhashSegment removes from h the flushed content of app in the range [from, to), which must be
+// the last content written to h
+func unhashSegment(h *segmentChecksum, app appendable.Appendable, from, to int64) error {
+	bs := make([]byte, checksumReadBufferSize)
+
+	for end := to; end > from; {
+		n := int64(len(bs))
+		if end-from < n {
+			n = end - from
+		}
+
+		_, err := app.ReadAt(bs[:n], end-n)
+		if err == io.EOF {
+			return io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return err
+		}
+
+		h.unwrite(bs[:n])
+		end -= n
+	}
+
+	return nil
+}
+
+// moveChecksum updates the checksum of the active segment after its offset was set, prevAppID and prevOff
+// are the active segment and its offset beforehand. Only the content in between both offsets is read
+func (mf *MultiFileAppendable) moveChecksum(prevAppID, prevOff int64) error {
+	h := mf.currChecksum
+	covered := prevOff
+
+	if mf.currAppID != prevAppID {
+		// the content of the following segments is no longer valid
+		for id := mf.currAppID + 1; id <= prevAppID; id++ {
+			err := os.Remove(mf.checksumPath(id))
+			if err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+
+		// the checksum of a sealed segment is the stored one, it covers nothing when missing
+		var crc uint32
+		var err error
+
+		covered, crc, _, err = mf.readChecksum(mf.currAppID)
+		if err != nil {
+			return err
+		}
+
+		h = &segmentChecksum{crc: crc}
+	}
+
+	off := mf.currApp.Offset()
+
+	if off >= covered {
+		err := hashSegment(h, mf.currApp, covered, off)
+		if err != nil {
+			return err
+		}
+
+		mf.currChecksum = h
+
+		return nil
+	}
+
+	err := unhashSegment(h, mf.currApp, off, covered)
+	if err != nil {
+		return err
+	}
+
+	mf.currChecksum = h
+
+	// the truncated content is going to be overwritten, the stored checksum must not cover it anymore
+	return mf.storeChecksum(true)
+}
+
+// resetChecksum recomputes the checksum of the active segment after its content was changed other than
+// by appending or truncating, the stored checksum is updated accordingly
+func (mf *MultiFileAppendable) resetChecksum() error {
+	err := syncAppendable(mf.currApp)
+	if err != nil {
+		return err
+	}
+
+	h := newSegmentChecksum()
+
+	err = hashSegment(h, mf.currApp, 0, mf.currApp.Offset())
+	if err != nil {
+		return err
+	}
+
+	mf.currChecksum = h
+
+	return mf.storeChecksum(true)
+}
+
+// storeChecksum writes the checksum of the flushed content of the active segment,
+// such content must already be synced so the checksum never covers data lost in a crash
+func (mf *MultiFileAppendable) storeChecksum(sync bool) error {
+	if mf.currChecksum == nil {
+		return nil
+	}
+
+	return mf.writeChecksum(mf.currAppID, mf.currApp.Offset(), mf.currChecksum.Sum32(), sync)
+}
+
+// refreshSealedChecksum recomputes and stores the checksum of a sealed segment whose content was changed
+func (mf *MultiFileAppendable) refreshSealedChecksum(appID int64, app appendable.Appendable) error {
+	size, err := app.Size()
+	if err != nil {
+		return err
+	}
+
+	h := crc32.New(checksumTable)
+
+	err = hashSegment(h, app, 0, size)
+	if err != nil {
+		return err
+	}
+
+	return mf.writeChecksum(appID, size, h.Sum32(), false)
+}
+
+// refreshChecksum updates the checksum of the segment after a hole was punched into it
+func (mf *MultiFileAppendable) refreshChecksum(appID int64, app appendable.Appendable) error {
+	mf.mutex.Lock()
+	defer mf.mutex.Unlock()
+
+	if mf.closed {
+		return ErrAlreadyClosed
+	}
+
+	if appID == mf.currAppID {
+		return mf.resetChecksum()
+	}
+
+	return mf.refreshSealedChecksum(appID, app)
+}
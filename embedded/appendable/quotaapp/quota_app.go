@@ -0,0 +1,58 @@
+/*
+Copyright 2022 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package quotaapp
+
+import (
+	"errors"
+
+	"github.com/codenotary/immudb/embedded/appendable"
+)
+
+var ErrIllegalArguments = errors.New("illegal arguments")
+var ErrQuotaExceeded = errors.New("appendable quota exceeded")
+
+// QuotaAppendable wraps an appendable and rejects appends once
+// the amount of bytes written through it would exceed the given quota
+type QuotaAppendable struct {
+	appendable.Appendable
+
+	quota int64
+}
+
+func Wrap(app appendable.Appendable, quota int64) (*QuotaAppendable, error) {
+	if app == nil || quota < 0 {
+		return nil, ErrIllegalArguments
+	}
+
+	return &QuotaAppendable{
+		Appendable: app,
+		quota:      quota,
+	}, nil
+}
+
+func (qa *QuotaAppendable) Quota() int64 {
+	return qa.quota
+}
+
+func (qa *QuotaAppendable) Append(bs []byte) (off int64, n int, err error) {
+	if qa.Appendable.WrittenBytes()+int64(len(bs)) > qa.quota {
+		return 0, 0, ErrQuotaExceeded
+	}
+
+	return qa.Appendable.Append(bs)
+}
+
+var _ appendable.Appendable = (*QuotaAppendable)(nil)
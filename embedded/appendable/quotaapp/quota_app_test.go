@@ -0,0 +1,61 @@
+/*
+Copyright 2022 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package quotaapp
+
+import (
+	"os"
+	"testing"
+
+	"github.com/codenotary/immudb/embedded/appendable/mocked"
+	"github.com/codenotary/immudb/embedded/appendable/singleapp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuotaApp(t *testing.T) {
+	_, err := Wrap(nil, 10)
+	require.ErrorIs(t, err, ErrIllegalArguments)
+
+	_, err = Wrap(&mocked.MockedAppendable{}, -1)
+	require.ErrorIs(t, err, ErrIllegalArguments)
+
+	app, err := singleapp.Open("testdata.aof", singleapp.DefaultOptions())
+	require.NoError(t, err)
+	defer os.Remove("testdata.aof")
+
+	qa, err := Wrap(app, 10)
+	require.NoError(t, err)
+	require.Equal(t, int64(10), qa.Quota())
+
+	off, n, err := qa.Append([]byte{0, 1, 2, 3})
+	require.NoError(t, err)
+	require.Equal(t, int64(0), off)
+	require.Equal(t, 4, n)
+
+	off, n, err = qa.Append([]byte{4, 5, 6, 7, 8, 9})
+	require.NoError(t, err)
+	require.Equal(t, int64(4), off)
+	require.Equal(t, 6, n)
+
+	require.Equal(t, int64(10), qa.WrittenBytes())
+
+	_, _, err = qa.Append([]byte{10})
+	require.ErrorIs(t, err, ErrQuotaExceeded)
+
+	require.Equal(t, int64(10), qa.WrittenBytes())
+
+	err = qa.Close()
+	require.NoError(t, err)
+}
@@ -518,6 +518,11 @@ func (r *RemoteStorageAppendable) OpenInitialAppendable(opts *multiapp.Options,
 	}
 
 	for _, fi := range fis {
+		if filepath.Ext(fi.Name()) == "."+multiapp.ChecksumFileExt {
+			// checksums kept next to local chunks are not chunks
+			continue
+		}
+
 		id, err := chunkIdFromName(fi.Name())
 		if err != nil {
 			return nil, 0, err
@@ -917,6 +917,32 @@ func TestRemoteStorageOpenInitialAppendableMissingRemoteChunk(t *testing.T) {
 	require.Nil(t, app)
 }
 
+func TestRemoteStorageOpenInitialAppendableChecksumFiles(t *testing.T) {
+	require.NoError(t, os.RemoveAll("testdata"))
+	defer os.RemoveAll("testdata")
+
+	mem := memory.Open()
+	opts := DefaultOptions()
+	opts.WithFileExt("tst")
+	opts.WithFileSize(10)
+
+	app, err := Open("testdata", "", mem, opts)
+	require.NoError(t, err)
+
+	err = app.Close()
+	require.NoError(t, err)
+
+	// checksums kept by multiapp next to local chunks must not be taken as chunks
+	err = ioutil.WriteFile("testdata/00000000.tst."+multiapp.ChecksumFileExt, make([]byte, 12), 0777)
+	require.NoError(t, err)
+
+	app, err = Open("testdata", "", mem, opts)
+	require.NoError(t, err)
+
+	err = app.Close()
+	require.NoError(t, err)
+}
+
 func TestRemoteStorageOpenInitialAppendableCorruptedLocalFile(t *testing.T) {
 	os.RemoveAll("testdata")
 	defer os.RemoveAll("testdata")
@@ -78,10 +78,22 @@ func (r *remoteStorageReader) DiscardUpto(off int64) error {
 	panic("unimplemented")
 }
 
+func (r *remoteStorageReader) PunchHole(off, length int64) error {
+	panic("unimplemented")
+}
+
 func (r *remoteStorageReader) Append(bs []byte) (off int64, n int, err error) {
 	panic("unimplemented")
 }
 
+func (r *remoteStorageReader) WrittenBytes() int64 {
+	return 0
+}
+
+func (r *remoteStorageReader) BytesWrittenSinceFlush() int64 {
+	return 0
+}
+
 func (r *remoteStorageReader) CompressionFormat() int {
 	panic("unimplemented")
 }
@@ -52,6 +52,11 @@ func TestRemoteStorageFlush(t *testing.T) {
 	require.NoError(t, r.Flush())
 }
 
+func TestRemoteStorageBytesWrittenSinceFlush(t *testing.T) {
+	r := remoteStorageReader{}
+	require.Zero(t, r.BytesWrittenSinceFlush())
+}
+
 func TestRemoteStorageSync(t *testing.T) {
 	r := remoteStorageReader{}
 	require.NoError(t, r.Sync())
@@ -0,0 +1,208 @@
+/*
+Copyright 2022 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package singleapp
+
+import (
+	"io"
+	"os"
+	"unsafe"
+)
+
+// directIOBlockSize is a multiple of the usual logical block sizes (512 and 4096 bytes)
+const directIOBlockSize = 4096
+
+// file is the subset of *os.File operations required by the single-file appendable
+type file interface {
+	io.Reader
+	io.Writer
+	io.ReaderAt
+	io.Seeker
+
+	Stat() (os.FileInfo, error)
+	Sync() error
+	Close() error
+}
+
+// directFile wraps a file opened so to bypass the page cache (e.g. O_DIRECT)
+// Such files only accept reads and writes of whole blocks from block-aligned
+// memory at block-aligned offsets, thus partial blocks are read, modified and
+// written back entirely. The file is truncated to its logical size after each
+// write so padding is never observable.
+type directFile struct {
+	f *os.File
+
+	size int64
+	pos  int64
+}
+
+func newDirectFile(f *os.File) (*directFile, error) {
+	stat, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	return &directFile{
+		f:    f,
+		size: stat.Size(),
+	}, nil
+}
+
+func alignDown(off int64) int64 {
+	return off &^ (directIOBlockSize - 1)
+}
+
+func alignUp(off int64) int64 {
+	return alignDown(off + directIOBlockSize - 1)
+}
+
+func alignedBuffer(size int) []byte {
+	b := make([]byte, size+directIOBlockSize)
+
+	o := int(uintptr(unsafe.Pointer(&b[0])) & (directIOBlockSize - 1))
+	if o > 0 {
+		o = directIOBlockSize - o
+	}
+
+	return b[o : o+size]
+}
+
+func (df *directFile) readAligned(b []byte, off int64) (int, error) {
+	n, err := df.f.ReadAt(b, off)
+	if err == io.EOF {
+		return n, nil
+	}
+
+	return n, err
+}
+
+func (df *directFile) ReadAt(bs []byte, off int64) (int, error) {
+	if off >= df.size {
+		return 0, io.EOF
+	}
+
+	start := alignDown(off)
+	end := alignUp(off + int64(len(bs)))
+
+	b := alignedBuffer(int(end - start))
+
+	n, err := df.readAligned(b, start)
+	if err != nil {
+		return 0, err
+	}
+
+	available := int64(n) - (off - start)
+	if available < 0 {
+		available = 0
+	}
+
+	c := copy(bs, b[off-start:off-start+available])
+	if c < len(bs) {
+		return c, io.EOF
+	}
+
+	return c, nil
+}
+
+func (df *directFile) Read(bs []byte) (int, error) {
+	n, err := df.ReadAt(bs, df.pos)
+	df.pos += int64(n)
+
+	if err == io.EOF && n > 0 {
+		return n, nil
+	}
+
+	return n, err
+}
+
+func (df *directFile) Write(bs []byte) (int, error) {
+	if len(bs) == 0 {
+		return 0, nil
+	}
+
+	start := alignDown(df.pos)
+	end := alignUp(df.pos + int64(len(bs)))
+
+	b := alignedBuffer(int(end - start))
+
+	if start < df.size {
+		// partially written blocks need to be preserved
+		_, err := df.readAligned(b, start)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	copy(b[df.pos-start:], bs)
+
+	_, err := df.f.WriteAt(b, start)
+	if err != nil {
+		return 0, err
+	}
+
+	df.pos += int64(len(bs))
+
+	if df.pos > df.size {
+		df.size = df.pos
+	}
+
+	if end > df.size {
+		err = df.f.Truncate(df.size)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return len(bs), nil
+}
+
+func (df *directFile) Seek(offset int64, whence int) (int64, error) {
+	var pos int64
+
+	switch whence {
+	case io.SeekStart:
+		pos = offset
+	case io.SeekCurrent:
+		pos = df.pos + offset
+	case io.SeekEnd:
+		pos = df.size + offset
+	default:
+		return 0, ErrIllegalArguments
+	}
+
+	if pos < 0 {
+		return 0, ErrIllegalArguments
+	}
+
+	df.pos = pos
+
+	return pos, nil
+}
+
+func (df *directFile) Stat() (os.FileInfo, error) {
+	return df.f.Stat()
+}
+
+func (df *directFile) Sync() error {
+	return df.f.Sync()
+}
+
+func (df *directFile) Fd() uintptr {
+	return df.f.Fd()
+}
+
+func (df *directFile) Close() error {
+	return df.f.Close()
+}
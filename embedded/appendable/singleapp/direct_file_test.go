@@ -0,0 +1,151 @@
+/*
+Copyright 2022 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package singleapp
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDirectFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "direct_file")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	f, err := os.OpenFile(filepath.Join(dir, "data"), os.O_CREATE|os.O_RDWR, 0644)
+	require.NoError(t, err)
+
+	df, err := newDirectFile(f)
+	require.NoError(t, err)
+
+	var expected []byte
+
+	for i := 0; i < 100; i++ {
+		bs := make([]byte, 1+i*37)
+		for j := range bs {
+			bs[j] = byte(i + j)
+		}
+
+		n, err := df.Write(bs)
+		require.NoError(t, err)
+		require.Equal(t, len(bs), n)
+
+		expected = append(expected, bs...)
+	}
+
+	stat, err := df.Stat()
+	require.NoError(t, err)
+	require.Equal(t, int64(len(expected)), stat.Size())
+
+	bs := make([]byte, len(expected))
+	n, err := df.ReadAt(bs, 0)
+	require.NoError(t, err)
+	require.Equal(t, len(expected), n)
+	require.Equal(t, expected, bs)
+
+	bs = make([]byte, 10)
+	n, err = df.ReadAt(bs, directIOBlockSize-5)
+	require.NoError(t, err)
+	require.Equal(t, 10, n)
+	require.Equal(t, expected[directIOBlockSize-5:directIOBlockSize+5], bs)
+
+	n, err = df.ReadAt(bs, int64(len(expected))-5)
+	require.ErrorIs(t, err, io.EOF)
+	require.Equal(t, 5, n)
+	require.Equal(t, expected[len(expected)-5:], bs[:5])
+
+	_, err = df.ReadAt(bs, int64(len(expected)))
+	require.ErrorIs(t, err, io.EOF)
+
+	// overwrite in the middle of the file
+	_, err = df.Seek(directIOBlockSize+3, io.SeekStart)
+	require.NoError(t, err)
+
+	_, err = df.Write([]byte{1, 2, 3})
+	require.NoError(t, err)
+	copy(expected[directIOBlockSize+3:], []byte{1, 2, 3})
+
+	off, err := df.Seek(0, io.SeekEnd)
+	require.NoError(t, err)
+	require.Equal(t, int64(len(expected)), off)
+
+	_, err = df.Seek(0, io.SeekStart)
+	require.NoError(t, err)
+
+	bs, err = ioutil.ReadAll(df)
+	require.NoError(t, err)
+	require.Equal(t, expected, bs)
+
+	_, err = df.Seek(-1, io.SeekStart)
+	require.ErrorIs(t, err, ErrIllegalArguments)
+
+	_, err = df.Seek(0, 99)
+	require.ErrorIs(t, err, ErrIllegalArguments)
+
+	err = df.Sync()
+	require.NoError(t, err)
+
+	err = df.Close()
+	require.NoError(t, err)
+}
+
+func TestSingleAppDirectIO(t *testing.T) {
+	dir, err := ioutil.TempDir("", "direct_io")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	fileName := filepath.Join(dir, "testdata.aof")
+
+	a, err := Open(fileName, DefaultOptions().WithDirectIO(true))
+	require.NoError(t, err)
+
+	off, n, err := a.Append([]byte{0, 1, 2, 3})
+	require.NoError(t, err)
+	require.Equal(t, int64(0), off)
+	require.Equal(t, 4, n)
+
+	err = a.Close()
+	require.NoError(t, err)
+
+	a, err = Open(fileName, DefaultOptions().WithDirectIO(true))
+	require.NoError(t, err)
+
+	require.Equal(t, int64(4), a.Offset())
+
+	off, _, err = a.Append([]byte{4, 5, 6})
+	require.NoError(t, err)
+	require.Equal(t, int64(4), off)
+
+	err = a.Flush()
+	require.NoError(t, err)
+
+	bs := make([]byte, 7)
+	_, err = a.ReadAt(bs, 0)
+	require.NoError(t, err)
+	require.Equal(t, []byte{0, 1, 2, 3, 4, 5, 6}, bs)
+
+	sz, err := a.Size()
+	require.NoError(t, err)
+	require.Equal(t, int64(7), sz)
+
+	err = a.Close()
+	require.NoError(t, err)
+}
@@ -0,0 +1,37 @@
+/*
+Copyright 2022 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package singleapp
+
+import (
+	"log"
+	"os"
+	"syscall"
+)
+
+func openFile(fileName string, flag int, fileMode os.FileMode, directIO bool) (file, error) {
+	f, err := os.OpenFile(fileName, flag, fileMode)
+	if err != nil || !directIO {
+		return f, err
+	}
+
+	// F_NOCACHE does not impose any alignment restriction
+	_, _, errno := syscall.Syscall(syscall.SYS_FCNTL, f.Fd(), syscall.F_NOCACHE, 1)
+	if errno != 0 {
+		log.Printf("WARNING: direct I/O not supported for '%s', falling back to buffered I/O: %v", fileName, errno)
+	}
+
+	return f, nil
+}
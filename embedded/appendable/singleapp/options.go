@@ -30,6 +30,7 @@ const DefaultWriteBufferSize = 4096
 type Options struct {
 	readOnly bool
 	synced   bool
+	directIO bool
 	fileMode os.FileMode
 
 	compressionFormat int
@@ -38,6 +39,8 @@ type Options struct {
 	readBufferSize  int
 	writeBufferSize int
 
+	mmapSize int64
+
 	metadata []byte
 }
 
@@ -56,7 +59,8 @@ func DefaultOptions() *Options {
 func (opts *Options) Valid() bool {
 	return opts != nil &&
 		opts.readBufferSize > 0 &&
-		opts.writeBufferSize > 0
+		opts.writeBufferSize > 0 &&
+		opts.mmapSize >= 0
 }
 
 func (opts *Options) WithReadOnly(readOnly bool) *Options {
@@ -69,6 +73,12 @@ func (opts *Options) WithSynced(synced bool) *Options {
 	return opts
 }
 
+// WithDirectIO makes the appendable bypass the OS page cache when supported by the platform
+func (opts *Options) WithDirectIO(directIO bool) *Options {
+	opts.directIO = directIO
+	return opts
+}
+
 func (opts *Options) WithFileMode(fileMode os.FileMode) *Options {
 	opts.fileMode = fileMode
 	return opts
@@ -105,6 +115,14 @@ func (opts *Options) WithMetadata(metadata []byte) *Options {
 	return opts
 }
 
+func (opts *Options) GetMetadata() []byte {
+	return opts.metadata
+}
+
+func (opts *Options) GetReadOnly() bool {
+	return opts.readOnly
+}
+
 func (opts *Options) WithReadBufferSize(size int) *Options {
 	opts.readBufferSize = size
 	return opts
@@ -114,3 +132,14 @@ func (opts *Options) WithWriteBufferSize(size int) *Options {
 	opts.writeBufferSize = size
 	return opts
 }
+
+// WithMmapSize makes reads of uncompressed files within the first size bytes to be served from a memory mapped region,
+// zero disables memory mapping
+func (opts *Options) WithMmapSize(size int64) *Options {
+	opts.mmapSize = size
+	return opts
+}
+
+func (opts *Options) GetMmapSize() int64 {
+	return opts.mmapSize
+}
@@ -34,20 +34,26 @@ func TestValidOptions(t *testing.T) {
 
 	require.Equal(t, DefaultFileMode, opts.WithFileMode(DefaultFileMode).fileMode)
 	require.Equal(t, []byte{}, opts.WithMetadata([]byte{}).metadata)
+	require.Equal(t, []byte{1}, opts.WithMetadata([]byte{1}).GetMetadata())
 	require.Equal(t, DefaultCompressionFormat, opts.WithCompressionFormat(DefaultCompressionFormat).compressionFormat)
 	require.Equal(t, DefaultCompressionFormat, opts.WithCompressionFormat(DefaultCompressionFormat).GetCompressionFormat())
 	require.Equal(t, DefaultCompressionLevel, opts.WithCompresionLevel(DefaultCompressionLevel).compressionLevel)
 	require.Equal(t, DefaultCompressionLevel, opts.WithCompresionLevel(DefaultCompressionLevel).GetCompressionLevel())
 
 	require.True(t, opts.WithSynced(true).synced)
+	require.True(t, opts.WithDirectIO(true).directIO)
 
 	require.False(t, opts.WithReadOnly(false).readOnly)
 
 	require.Equal(t, DefaultReadBufferSize+1, opts.WithReadBufferSize(DefaultReadBufferSize+1).GetReadBufferSize())
 	require.Equal(t, DefaultWriteBufferSize+2, opts.WithWriteBufferSize(DefaultWriteBufferSize+2).GetWriteBufferSize())
 
+	require.False(t, opts.WithMmapSize(-1).Valid())
+	require.Equal(t, int64(1024), opts.WithMmapSize(1024).GetMmapSize())
+
 	require.True(t, opts.Valid())
 
 	require.True(t, opts.WithReadOnly(true).readOnly)
+	require.True(t, opts.GetReadOnly())
 	require.True(t, opts.Valid())
 }
@@ -0,0 +1,41 @@
+/*
+Copyright 2022 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package singleapp
+
+import (
+	"syscall"
+)
+
+// fallocate(2) flags, not defined by the syscall package
+const (
+	fallocFlKeepSize  = 0x01
+	fallocFlPunchHole = 0x02
+)
+
+func punchHole(f file, off, length int64) error {
+	fd, ok := f.(interface{ Fd() uintptr })
+	if !ok {
+		return nil
+	}
+
+	err := syscall.Fallocate(int(fd.Fd()), fallocFlKeepSize|fallocFlPunchHole, off, length)
+	if err == syscall.EOPNOTSUPP || err == syscall.ENOSYS {
+		// e.g. tmpfs on older kernels or filesystems without sparse files
+		return nil
+	}
+
+	return err
+}
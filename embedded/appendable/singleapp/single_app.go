@@ -45,7 +45,7 @@ const (
 )
 
 type AppendableFile struct {
-	f *os.File
+	f file
 
 	compressionFormat int
 	compressionLevel  int
@@ -65,6 +65,15 @@ type AppendableFile struct {
 	baseOffset int64
 	offset     int64
 
+	writtenBytes int64
+
+	// bytes appended since the last flush or sync
+	unflushedBytes int64
+
+	// mmap holds the memory mapped region of the file, it grows up to mmapSize bytes
+	mmapSize int64
+	mmap     []byte
+
 	mutex sync.Mutex
 }
 
@@ -88,7 +97,7 @@ func Open(fileName string, opts *Options) (*AppendableFile, error) {
 		return nil, err
 	}
 
-	f, err := os.OpenFile(fileName, flag, opts.fileMode)
+	f, err := openFile(fileName, flag, opts.fileMode, opts.directIO)
 	if err != nil {
 		return nil, err
 	}
@@ -99,12 +108,7 @@ func Open(fileName string, opts *Options) (*AppendableFile, error) {
 	var baseOffset int64
 
 	if notExist {
-		m := appendable.NewMetadata(nil)
-		m.PutInt(metaCompressionFormat, opts.compressionFormat)
-		m.PutInt(metaCompressionLevel, opts.compressionLevel)
-		m.Put(metaWrappedMeta, opts.metadata)
-
-		mBs := m.Bytes()
+		mBs := headerMetadata(opts)
 		mLenBs := make([]byte, 4)
 		binary.BigEndian.PutUint32(mLenBs, uint32(len(mBs)))
 
@@ -177,7 +181,7 @@ func Open(fileName string, opts *Options) (*AppendableFile, error) {
 		w = bufio.NewWriterSize(f, opts.writeBufferSize)
 	}
 
-	return &AppendableFile{
+	aof := &AppendableFile{
 		f:                 f,
 		compressionFormat: compressionFormat,
 		compressionLevel:  compressionLevel,
@@ -190,7 +194,36 @@ func Open(fileName string, opts *Options) (*AppendableFile, error) {
 		baseOffset:        baseOffset,
 		offset:            off - baseOffset,
 		closed:            false,
-	}, nil
+	}
+
+	_, isOSFile := f.(*os.File)
+
+	// compressed data can not be read in place and direct I/O files bypass the page cache
+	if opts.mmapSize > 0 && mmapSupported && isOSFile && compressionFormat == appendable.NoCompression {
+		aof.mmapSize = opts.mmapSize
+
+		err = aof.remap()
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+
+	return aof, nil
+}
+
+func headerMetadata(opts *Options) []byte {
+	m := appendable.NewMetadata(nil)
+	m.PutInt(metaCompressionFormat, opts.compressionFormat)
+	m.PutInt(metaCompressionLevel, opts.compressionLevel)
+	m.Put(metaWrappedMeta, opts.metadata)
+
+	return m.Bytes()
+}
+
+// HeaderSize returns the number of bytes taken by the header of a file created with the provided options
+func HeaderSize(opts *Options) int {
+	return 4 + len(headerMetadata(opts))
 }
 
 func (aof *AppendableFile) Copy(dstPath string) error {
@@ -291,6 +324,40 @@ func (aof *AppendableFile) DiscardUpto(off int64) error {
 	return nil
 }
 
+// PunchHole releases the disk space used by the range [off, off+length), which is read as zeroes afterwards.
+// Buffered data is flushed first. It's a no-op when not supported by the platform or the filesystem
+func (aof *AppendableFile) PunchHole(off, length int64) error {
+	aof.mutex.Lock()
+	defer aof.mutex.Unlock()
+
+	if aof.closed {
+		return ErrAlreadyClosed
+	}
+
+	if aof.readOnly {
+		return ErrReadOnly
+	}
+
+	if off < 0 || length < 0 {
+		return ErrIllegalArguments
+	}
+
+	if aof.offset < off+length {
+		return fmt.Errorf("%w: hole beyond existent data boundaries", ErrIllegalArguments)
+	}
+
+	if length == 0 {
+		return nil
+	}
+
+	err := aof.flush()
+	if err != nil {
+		return err
+	}
+
+	return punchHole(aof.f, off+aof.baseOffset, length)
+}
+
 func (aof *AppendableFile) writer(w io.Writer) (cw io.Writer, err error) {
 	switch aof.compressionFormat {
 	case appendable.FlateCompression:
@@ -340,6 +407,8 @@ func (aof *AppendableFile) Append(bs []byte) (off int64, n int, err error) {
 	if aof.compressionFormat == appendable.NoCompression {
 		n, err = aof.w.Write(bs)
 		aof.offset += int64(n)
+		aof.writtenBytes += int64(n)
+		aof.unflushedBytes += int64(n)
 		return
 	}
 
@@ -374,10 +443,28 @@ func (aof *AppendableFile) Append(bs []byte) (off int64, n int, err error) {
 
 	n += 4
 	aof.offset += int64(n)
+	aof.writtenBytes += int64(n)
+	aof.unflushedBytes += int64(n)
 
 	return
 }
 
+// WrittenBytes returns the number of bytes appended through this instance since it was opened
+func (aof *AppendableFile) WrittenBytes() int64 {
+	aof.mutex.Lock()
+	defer aof.mutex.Unlock()
+
+	return aof.writtenBytes
+}
+
+// BytesWrittenSinceFlush returns the number of bytes appended since the last call to Flush or Sync
+func (aof *AppendableFile) BytesWrittenSinceFlush() int64 {
+	aof.mutex.Lock()
+	defer aof.mutex.Unlock()
+
+	return aof.unflushedBytes
+}
+
 func (aof *AppendableFile) ReadAt(bs []byte, off int64) (n int, err error) {
 	aof.mutex.Lock()
 	defer aof.mutex.Unlock()
@@ -391,6 +478,14 @@ func (aof *AppendableFile) ReadAt(bs []byte, off int64) (n int, err error) {
 	}
 
 	if aof.compressionFormat == appendable.NoCompression {
+		mapped, err := aof.readMmap(bs, off+aof.baseOffset)
+		if err != nil {
+			return 0, err
+		}
+		if mapped {
+			return len(bs), nil
+		}
+
 		return aof.f.ReadAt(bs, off+aof.baseOffset)
 	}
 
@@ -440,6 +535,65 @@ func (aof *AppendableFile) ReadAt(bs []byte, off int64) (n int, err error) {
 	return
 }
 
+// readMmap copies into bs the content of the file at fileOff if it lies within the memory mapped region,
+// the region is extended when the file has grown since it was mapped
+func (aof *AppendableFile) readMmap(bs []byte, fileOff int64) (bool, error) {
+	end := fileOff + int64(len(bs))
+
+	if end > aof.mmapSize {
+		return false, nil
+	}
+
+	if end > int64(len(aof.mmap)) {
+		err := aof.remap()
+		if err != nil {
+			return false, err
+		}
+	}
+
+	if end > int64(len(aof.mmap)) {
+		return false, nil
+	}
+
+	copy(bs, aof.mmap[fileOff:end])
+
+	return true, nil
+}
+
+// remap maps up to mmapSize bytes of the file, the current mapping is kept if the file has not grown
+func (aof *AppendableFile) remap() error {
+	fi, err := aof.f.Stat()
+	if err != nil {
+		return err
+	}
+
+	size := fi.Size()
+	if size > aof.mmapSize {
+		size = aof.mmapSize
+	}
+
+	if size <= int64(len(aof.mmap)) {
+		return nil
+	}
+
+	mmap, err := mmapFile(aof.f.(*os.File), int(size))
+	if err != nil {
+		return err
+	}
+
+	if aof.mmap != nil {
+		err = munmapFile(aof.mmap)
+		if err != nil {
+			munmapFile(mmap)
+			return err
+		}
+	}
+
+	aof.mmap = mmap
+
+	return nil
+}
+
 func (aof *AppendableFile) Flush() error {
 	aof.mutex.Lock()
 	defer aof.mutex.Unlock()
@@ -461,6 +615,8 @@ func (aof *AppendableFile) flush() error {
 		return err
 	}
 
+	aof.unflushedBytes = 0
+
 	if aof.synced {
 		return aof.f.Sync()
 	}
@@ -484,7 +640,14 @@ func (aof *AppendableFile) Sync() error {
 }
 
 func (aof *AppendableFile) sync() error {
-	return aof.f.Sync()
+	err := aof.f.Sync()
+	if err != nil {
+		return err
+	}
+
+	aof.unflushedBytes = 0
+
+	return nil
 }
 
 func (aof *AppendableFile) Close() error {
@@ -504,6 +667,15 @@ func (aof *AppendableFile) Close() error {
 
 	aof.closed = true
 
+	if aof.mmap != nil {
+		err := munmapFile(aof.mmap)
+		if err != nil {
+			return err
+		}
+
+		aof.mmap = nil
+	}
+
 	return aof.f.Close()
 }
 
@@ -17,6 +17,7 @@ package singleapp
 
 import (
 	"bufio"
+	"bytes"
 	"encoding/binary"
 	"io"
 	"io/ioutil"
@@ -74,9 +75,23 @@ func TestSingleApp(t *testing.T) {
 	require.Equal(t, int64(4), off)
 	require.Equal(t, 7, n)
 
+	require.Equal(t, int64(11), a.WrittenBytes())
+	require.Equal(t, int64(11), a.BytesWrittenSinceFlush())
+
 	err = a.Flush()
 	require.NoError(t, err)
 
+	require.Equal(t, int64(11), a.WrittenBytes())
+	require.Zero(t, a.BytesWrittenSinceFlush())
+
+	_, _, err = a.Append([]byte{11})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), a.BytesWrittenSinceFlush())
+
+	err = a.Sync()
+	require.NoError(t, err)
+	require.Zero(t, a.BytesWrittenSinceFlush())
+
 	bs := make([]byte, 4)
 	n, err = a.ReadAt(bs, 0)
 	require.NoError(t, err)
@@ -424,3 +439,156 @@ func TestSingleAppDiscard(t *testing.T) {
 	err = app.Close()
 	require.NoError(t, err)
 }
+
+func TestSingleAppPunchHole(t *testing.T) {
+	app, err := Open("testdata_punch_hole.aof", DefaultOptions().WithMetadata([]byte{1, 2, 3}))
+	require.NoError(t, err)
+
+	defer os.RemoveAll("testdata_punch_hole.aof")
+
+	err = app.PunchHole(-1, 1)
+	require.ErrorIs(t, err, ErrIllegalArguments)
+
+	err = app.PunchHole(0, 1)
+	require.ErrorIs(t, err, ErrIllegalArguments)
+
+	data := make([]byte, 3*4096)
+	for i := range data {
+		data[i] = byte(i%255) + 1
+	}
+
+	_, _, err = app.Append(data)
+	require.NoError(t, err)
+
+	// unflushed data is flushed before punching the hole
+	err = app.PunchHole(4096, 4096)
+	require.NoError(t, err)
+
+	err = app.PunchHole(0, 0)
+	require.NoError(t, err)
+
+	bs := make([]byte, len(data))
+	_, err = app.ReadAt(bs, 0)
+	require.NoError(t, err)
+	require.Equal(t, data[:4096], bs[:4096])
+	require.Equal(t, data[2*4096:], bs[2*4096:])
+
+	// holes are read as zeroes when supported, the content is kept otherwise
+	if !bytes.Equal(data[4096:2*4096], bs[4096:2*4096]) {
+		require.Equal(t, make([]byte, 4096), bs[4096:2*4096])
+	}
+
+	sz, err := app.Size()
+	require.NoError(t, err)
+	require.Equal(t, int64(len(data)), sz)
+
+	err = app.Close()
+	require.NoError(t, err)
+
+	err = app.PunchHole(0, 1)
+	require.ErrorIs(t, err, ErrAlreadyClosed)
+}
+
+func TestSingleAppHeaderSize(t *testing.T) {
+	opts := DefaultOptions().WithMetadata([]byte{1, 2, 3})
+
+	a, err := Open("testdata_header_size.aof", opts)
+	defer os.Remove("testdata_header_size.aof")
+	require.NoError(t, err)
+
+	err = a.Close()
+	require.NoError(t, err)
+
+	fi, err := os.Stat("testdata_header_size.aof")
+	require.NoError(t, err)
+	require.Equal(t, int64(HeaderSize(opts)), fi.Size())
+}
+
+func TestSingleAppMmap(t *testing.T) {
+	dir, err := ioutil.TempDir("", "singleapp_mmap")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	opts := DefaultOptions().WithCompressionFormat(appendable.NoCompression)
+
+	// the header plus the first 50 bytes of data are mapped
+	mmapSize := HeaderSize(opts) + 50
+	opts.WithMmapSize(int64(mmapSize))
+
+	a, err := Open(filepath.Join(dir, "00000000.aof"), opts)
+	require.NoError(t, err)
+
+	data := make([]byte, 100)
+	for i := range data {
+		data[i] = byte(i)
+	}
+
+	_, _, err = a.Append(data[:10])
+	require.NoError(t, err)
+
+	err = a.Flush()
+	require.NoError(t, err)
+
+	bs := make([]byte, 10)
+
+	// the mapped region is extended as the file grows
+	_, err = a.ReadAt(bs, 0)
+	require.NoError(t, err)
+	require.Equal(t, data[:10], bs)
+	require.Len(t, a.mmap, int(a.baseOffset)+10)
+
+	_, _, err = a.Append(data[10:])
+	require.NoError(t, err)
+
+	err = a.Flush()
+	require.NoError(t, err)
+
+	_, err = a.ReadAt(bs, 20)
+	require.NoError(t, err)
+	require.Equal(t, data[20:30], bs)
+	require.Len(t, a.mmap, mmapSize)
+
+	// reads beyond the mapped region fall back to regular reads
+	_, err = a.ReadAt(bs, 90)
+	require.NoError(t, err)
+	require.Equal(t, data[90:100], bs)
+
+	_, err = a.ReadAt(bs, 95)
+	require.ErrorIs(t, err, io.EOF)
+
+	err = a.Close()
+	require.NoError(t, err)
+	require.Nil(t, a.mmap)
+
+	t.Run("existing files should be mapped on open", func(t *testing.T) {
+		a, err := Open(filepath.Join(dir, "00000000.aof"), opts)
+		require.NoError(t, err)
+		require.Len(t, a.mmap, mmapSize)
+
+		_, err = a.ReadAt(bs, 0)
+		require.NoError(t, err)
+		require.Equal(t, data[:10], bs)
+
+		err = a.Close()
+		require.NoError(t, err)
+	})
+
+	t.Run("compressed files should not be mapped", func(t *testing.T) {
+		a, err := Open(filepath.Join(dir, "00000001.aof"), DefaultOptions().WithCompressionFormat(appendable.GZipCompression).WithMmapSize(int64(mmapSize)))
+		require.NoError(t, err)
+
+		_, _, err = a.Append(data)
+		require.NoError(t, err)
+
+		err = a.Flush()
+		require.NoError(t, err)
+
+		_, err = a.ReadAt(bs, 0)
+		require.NoError(t, err)
+		require.Equal(t, data[:10], bs)
+		require.Nil(t, a.mmap)
+
+		err = a.Close()
+		require.NoError(t, err)
+	})
+}
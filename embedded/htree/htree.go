@@ -17,13 +17,18 @@ package htree
 
 import (
 	"crypto/sha256"
+	"encoding/hex"
 	"errors"
+	"fmt"
 	"math/bits"
+	"strconv"
+	"strings"
 )
 
 var ErrMaxWidthExceeded = errors.New("max width exceeded")
 var ErrIllegalArguments = errors.New("illegal arguments")
 var ErrIllegalState = errors.New("illegal state")
+var ErrMalformedProof = errors.New("malformed inclusion proof")
 
 const LeafPrefix = byte(0)
 const NodePrefix = byte(1)
@@ -194,3 +199,51 @@ func VerifyInclusion(proof *InclusionProof, digest, root [sha256.Size]byte) bool
 
 	return i == r && root == calcRoot
 }
+
+// SerializeToHex encodes the terms of the proof as <numHashes>:<hash0>:<hash1>:... using lowercase hex.
+// Leaf and Width are not included, they must be provided separately when verifying the proof
+func (p *InclusionProof) SerializeToHex() string {
+	var sb strings.Builder
+
+	sb.WriteString(strconv.Itoa(len(p.Terms)))
+
+	for _, t := range p.Terms {
+		sb.WriteByte(':')
+		sb.WriteString(hex.EncodeToString(t[:]))
+	}
+
+	return sb.String()
+}
+
+// DeserializeFromHex decodes the terms of a proof encoded with SerializeToHex
+func DeserializeFromHex(s string) (*InclusionProof, error) {
+	parts := strings.Split(s, ":")
+
+	n, err := strconv.Atoi(parts[0])
+	if err != nil || n < 0 {
+		return nil, fmt.Errorf("%w: invalid number of hashes '%s'", ErrMalformedProof, parts[0])
+	}
+
+	if len(parts)-1 != n {
+		return nil, fmt.Errorf("%w: %d hashes expected but %d found", ErrMalformedProof, n, len(parts)-1)
+	}
+
+	proof := &InclusionProof{
+		Terms: make([][sha256.Size]byte, n),
+	}
+
+	for i, h := range parts[1:] {
+		b, err := hex.DecodeString(h)
+		if err != nil {
+			return nil, fmt.Errorf("%w: hash %d is not hex encoded: %v", ErrMalformedProof, i, err)
+		}
+
+		if len(b) != sha256.Size {
+			return nil, fmt.Errorf("%w: hash %d is %d bytes long but %d bytes are expected", ErrMalformedProof, i, len(b), sha256.Size)
+		}
+
+		copy(proof.Terms[i][:], b)
+	}
+
+	return proof, nil
+}
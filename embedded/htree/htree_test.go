@@ -18,6 +18,7 @@ package htree
 import (
 	"crypto/sha256"
 	"encoding/binary"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -81,3 +82,54 @@ func TestHTree(t *testing.T) {
 	_, err = tree.InclusionProof(maxWidth)
 	require.Equal(t, ErrIllegalArguments, err)
 }
+
+func TestInclusionProofHexSerialization(t *testing.T) {
+	tree, err := New(10)
+	require.NoError(t, err)
+
+	digests := make([][sha256.Size]byte, 10)
+
+	for i := 0; i < len(digests); i++ {
+		var b [8]byte
+		binary.BigEndian.PutUint64(b[:], uint64(i))
+		digests[i] = sha256.Sum256(b[:])
+	}
+
+	err = tree.BuildWith(digests)
+	require.NoError(t, err)
+
+	root, err := tree.Root()
+	require.NoError(t, err)
+
+	proof, err := tree.InclusionProof(3)
+	require.NoError(t, err)
+
+	s := proof.SerializeToHex()
+	require.Equal(t, strings.ToLower(s), s)
+	require.Len(t, strings.Split(s, ":"), len(proof.Terms)+1)
+
+	decoded, err := DeserializeFromHex(s)
+	require.NoError(t, err)
+	require.Equal(t, proof.Terms, decoded.Terms)
+
+	decoded.Leaf = 3
+	decoded.Width = len(digests)
+	require.True(t, VerifyInclusion(decoded, digests[3], root))
+
+	decoded, err = DeserializeFromHex((&InclusionProof{}).SerializeToHex())
+	require.NoError(t, err)
+	require.Empty(t, decoded.Terms)
+
+	for _, malformed := range []string{
+		"",
+		"x:" + strings.Repeat("00", sha256.Size),
+		"-1",
+		"2:" + strings.Repeat("00", sha256.Size),
+		"0:" + strings.Repeat("00", sha256.Size),
+		"1:" + strings.Repeat("zz", sha256.Size),
+		"1:" + strings.Repeat("00", sha256.Size-1),
+	} {
+		_, err = DeserializeFromHex(malformed)
+		require.ErrorIs(t, err, ErrMalformedProof, malformed)
+	}
+}
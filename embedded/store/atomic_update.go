@@ -0,0 +1,67 @@
+/*
+Copyright 2022 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package store
+
+// AtomicBatch gives access to the transaction of an AtomicMultiUpdate call.
+// Reads are served from the snapshot of the transaction and include the changes already set in the batch
+type AtomicBatch struct {
+	tx *OngoingTx
+}
+
+// Get returns the current value of key, ErrKeyNotFound is returned for missing, deleted or expired keys
+func (b *AtomicBatch) Get(key []byte) ([]byte, error) {
+	valRef, err := b.tx.Get(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return valRef.Resolve()
+}
+
+func (b *AtomicBatch) Set(key, value []byte) error {
+	return b.tx.Set(key, nil, value)
+}
+
+// AtomicMultiUpdate runs fn within a read-write transaction and commits it once fn returns,
+// the transaction is discarded if fn returns an error. Calls to AtomicMultiUpdate are serialized,
+// other transactions committed concurrently on keys read by fn make the commit fail with ErrTxReadConflict.
+// The id of the committed transaction is returned
+func (s *ImmuStore) AtomicMultiUpdate(fn func(*AtomicBatch) error) (uint64, error) {
+	if fn == nil {
+		return 0, ErrIllegalArguments
+	}
+
+	s.atomicUpdateMutex.Lock()
+	defer s.atomicUpdateMutex.Unlock()
+
+	tx, err := s.NewTx()
+	if err != nil {
+		return 0, err
+	}
+
+	err = fn(&AtomicBatch{tx: tx})
+	if err != nil {
+		tx.Cancel()
+		return 0, err
+	}
+
+	hdr, err := tx.Commit()
+	if err != nil {
+		return 0, err
+	}
+
+	return hdr.ID, nil
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2022 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package store
+
+import (
+	"encoding/binary"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestImmudbStoreAtomicMultiUpdate(t *testing.T) {
+	immuStore, cleanup, err := NewTempStore(DefaultOptions())
+	require.NoError(t, err)
+	defer cleanup()
+
+	_, err = immuStore.AtomicMultiUpdate(nil)
+	require.ErrorIs(t, err, ErrIllegalArguments)
+
+	balance := func(b *AtomicBatch, account string) uint64 {
+		v, err := b.Get([]byte(account))
+		require.NoError(t, err)
+		return binary.BigEndian.Uint64(v)
+	}
+
+	setBalance := func(b *AtomicBatch, account string, amount uint64) {
+		var v [8]byte
+		binary.BigEndian.PutUint64(v[:], amount)
+
+		err := b.Set([]byte(account), v[:])
+		require.NoError(t, err)
+	}
+
+	_, err = immuStore.AtomicMultiUpdate(func(b *AtomicBatch) error {
+		_, err := b.Get([]byte("A"))
+		require.ErrorIs(t, err, ErrKeyNotFound)
+
+		setBalance(b, "A", 1000)
+		setBalance(b, "B", 0)
+
+		// changes of the batch are visible within it
+		require.Equal(t, uint64(1000), balance(b, "A"))
+
+		return nil
+	})
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			_, err := immuStore.AtomicMultiUpdate(func(b *AtomicBatch) error {
+				setBalance(b, "A", balance(b, "A")-10)
+				setBalance(b, "B", balance(b, "B")+10)
+				return nil
+			})
+			require.NoError(t, err)
+		}()
+	}
+
+	wg.Wait()
+
+	errAborted := errors.New("insufficient funds")
+
+	_, err = immuStore.AtomicMultiUpdate(func(b *AtomicBatch) error {
+		setBalance(b, "B", balance(b, "B")+5000)
+		return errAborted
+	})
+	require.ErrorIs(t, err, errAborted)
+
+	_, err = immuStore.AtomicMultiUpdate(func(b *AtomicBatch) error {
+		require.Equal(t, uint64(900), balance(b, "A"))
+		require.Equal(t, uint64(100), balance(b, "B"))
+		return nil
+	})
+	require.ErrorIs(t, err, ErrorNoEntriesProvided)
+}
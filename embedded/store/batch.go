@@ -0,0 +1,149 @@
+/*
+Copyright 2022 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package store
+
+import (
+	"errors"
+)
+
+var ErrCyclicDependency = errors.New("cyclic dependency between transactions")
+
+// PendingTx is a transaction to be committed as part of a batch.
+// ID identifies the transaction within the batch and Deps holds the IDs of the transactions
+// which must be committed before it. Dependencies not included in the batch are considered satisfied
+type PendingTx struct {
+	ID      uint64
+	Entries []*EntrySpec
+	Deps    []uint64
+}
+
+type BatchCommitOptions struct {
+	// WaitForIndexing makes each commit wait until the transaction is indexed
+	WaitForIndexing bool
+}
+
+// CommitBatch commits the transactions in txs honoring their dependencies, independent transactions
+// are committed in the order they were provided.
+// The returned slice holds the ID assigned to each transaction, aligned with txs.
+// When a commit fails, previously committed transactions remain committed and their IDs are returned
+// along with the error
+func (s *ImmuStore) CommitBatch(txs []PendingTx, opts BatchCommitOptions) ([]uint64, error) {
+	order, err := dependencyOrder(txs)
+	if err != nil {
+		return nil, err
+	}
+
+	committed := make([]uint64, len(txs))
+
+	for _, i := range order {
+		tx, err := s.NewWriteOnlyTx()
+		if err != nil {
+			return committed, err
+		}
+
+		for _, e := range txs[i].Entries {
+			err = tx.Set(e.Key, e.Metadata, e.Value)
+			if err != nil {
+				tx.Cancel()
+				return committed, err
+			}
+		}
+
+		var hdr *TxHeader
+
+		if opts.WaitForIndexing {
+			hdr, err = tx.Commit()
+		} else {
+			hdr, err = tx.AsyncCommit()
+		}
+		if err != nil {
+			return committed, err
+		}
+
+		committed[i] = hdr.ID
+	}
+
+	return committed, nil
+}
+
+// dependencyOrder returns the positions of txs sorted topologically (Kahn's algorithm),
+// ties are resolved by position so independent transactions keep the provided order
+func dependencyOrder(txs []PendingTx) ([]int, error) {
+	pos := make(map[uint64]int, len(txs))
+
+	for i, tx := range txs {
+		_, duplicated := pos[tx.ID]
+		if duplicated {
+			return nil, ErrIllegalArguments
+		}
+
+		pos[tx.ID] = i
+	}
+
+	pendingDeps := make([]int, len(txs))
+	dependants := make([][]int, len(txs))
+
+	for i, tx := range txs {
+		for _, dep := range tx.Deps {
+			j, ok := pos[dep]
+			if !ok {
+				continue
+			}
+
+			if j == i {
+				return nil, ErrCyclicDependency
+			}
+
+			pendingDeps[i]++
+			dependants[j] = append(dependants[j], i)
+		}
+	}
+
+	order := make([]int, 0, len(txs))
+	ready := make([]bool, len(txs))
+
+	for i := range txs {
+		ready[i] = pendingDeps[i] == 0
+	}
+
+	for len(order) < len(txs) {
+		next := -1
+
+		for i := range txs {
+			if ready[i] {
+				next = i
+				break
+			}
+		}
+
+		if next < 0 {
+			return nil, ErrCyclicDependency
+		}
+
+		ready[next] = false
+		order = append(order, next)
+
+		for _, d := range dependants[next] {
+			pendingDeps[d]--
+
+			if pendingDeps[d] == 0 {
+				ready[d] = true
+			}
+		}
+	}
+
+	return order, nil
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2022 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package store
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommitBatch(t *testing.T) {
+	dir, err := ioutil.TempDir("", "data_commit_batch")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	immuStore, err := Open(dir, DefaultOptions())
+	require.NoError(t, err)
+	defer immustoreClose(t, immuStore)
+
+	pendingTx := func(id uint64, key string, deps ...uint64) PendingTx {
+		return PendingTx{
+			ID:      id,
+			Entries: []*EntrySpec{{Key: []byte(key), Value: []byte(key)}},
+			Deps:    deps,
+		}
+	}
+
+	t.Run("duplicated ids should fail", func(t *testing.T) {
+		_, err := immuStore.CommitBatch([]PendingTx{
+			pendingTx(1, "key1"),
+			pendingTx(1, "key2"),
+		}, BatchCommitOptions{})
+		require.ErrorIs(t, err, ErrIllegalArguments)
+	})
+
+	t.Run("cyclic dependencies should fail", func(t *testing.T) {
+		_, err := immuStore.CommitBatch([]PendingTx{
+			pendingTx(1, "key1", 1),
+		}, BatchCommitOptions{})
+		require.ErrorIs(t, err, ErrCyclicDependency)
+
+		_, err = immuStore.CommitBatch([]PendingTx{
+			pendingTx(1, "key1", 3),
+			pendingTx(2, "key2", 1),
+			pendingTx(3, "key3", 2),
+		}, BatchCommitOptions{})
+		require.ErrorIs(t, err, ErrCyclicDependency)
+
+		require.Zero(t, immuStore.TxCount())
+	})
+
+	t.Run("transactions should be committed in dependency order", func(t *testing.T) {
+		txIDs, err := immuStore.CommitBatch([]PendingTx{
+			pendingTx(13, "key13", 12),
+			pendingTx(11, "key11", 10),
+			pendingTx(12, "key12", 11),
+			pendingTx(20, "key20"),
+		}, BatchCommitOptions{WaitForIndexing: true})
+		require.NoError(t, err)
+		require.Equal(t, []uint64{3, 1, 2, 4}, txIDs)
+
+		for _, key := range []string{"key11", "key12", "key13", "key20"} {
+			_, err := immuStore.Get([]byte(key))
+			require.NoError(t, err)
+		}
+	})
+
+	t.Run("failing commits should return already committed transactions", func(t *testing.T) {
+		txIDs, err := immuStore.CommitBatch([]PendingTx{
+			pendingTx(1, "key1"),
+			{ID: 2},
+		}, BatchCommitOptions{})
+		require.ErrorIs(t, err, ErrorNoEntriesProvided)
+		require.Equal(t, []uint64{5, 0}, txIDs)
+	})
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2022 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package store
+
+import (
+	"crypto/sha256"
+	"runtime"
+	"sync"
+
+	"github.com/codenotary/immudb/embedded/htree"
+)
+
+// EntryProof holds what is needed to verify an entry is included in a transaction
+type EntryProof struct {
+	InclusionProof *htree.InclusionProof
+	// EntryIndex is the position of the entry in the transaction
+	EntryIndex int
+	NEntries   int
+	// Eh is the root of the tree built with the entries of the transaction
+	Eh [sha256.Size]byte
+	// Digest is the digest of the entry as returned by TxHeader.TxEntryDigest
+	Digest [sha256.Size]byte
+}
+
+func (p *EntryProof) verify() bool {
+	if p.InclusionProof == nil ||
+		p.InclusionProof.Leaf != p.EntryIndex ||
+		p.InclusionProof.Width != p.NEntries {
+		return false
+	}
+
+	return VerifyInclusion(p.InclusionProof, p.Digest, p.Eh)
+}
+
+// BatchVerify verifies the inclusion proofs in parallel, using as many workers as runtime.GOMAXPROCS(0).
+// The returned slice holds the outcome of the verification of each proof, in the same order
+func (s *ImmuStore) BatchVerify(proofs []*EntryProof) ([]bool, error) {
+	for _, p := range proofs {
+		if p == nil {
+			return nil, ErrIllegalArguments
+		}
+	}
+
+	verified := make([]bool, len(proofs))
+
+	workers := runtime.GOMAXPROCS(0)
+	if workers > len(proofs) {
+		workers = len(proofs)
+	}
+
+	proofCh := make(chan int)
+
+	var wg sync.WaitGroup
+
+	wg.Add(workers)
+
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+
+			for i := range proofCh {
+				verified[i] = proofs[i].verify()
+			}
+		}()
+	}
+
+	for i := range proofs {
+		proofCh <- i
+	}
+
+	close(proofCh)
+
+	wg.Wait()
+
+	return verified, nil
+}
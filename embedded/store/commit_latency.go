@@ -0,0 +1,116 @@
+/*
+Copyright 2022 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package store
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const commitLatencyAlpha = 0.1
+
+const commitLatencySamples = 1000
+
+type commitLatencyTracker struct {
+	// ewma holds the float64 bits of the moving average in nanoseconds, 0 until the first sample
+	ewma uint64
+
+	samples     [commitLatencySamples]time.Duration
+	samplesLen  int
+	samplesNext int
+
+	mutex sync.Mutex
+}
+
+func newCommitLatencyTracker() *commitLatencyTracker {
+	return &commitLatencyTracker{}
+}
+
+func (t *commitLatencyTracker) observe(d time.Duration) {
+	for {
+		oldBits := atomic.LoadUint64(&t.ewma)
+
+		newAvg := float64(d)
+		if oldBits != 0 {
+			oldAvg := math.Float64frombits(oldBits)
+			newAvg = commitLatencyAlpha*float64(d) + (1-commitLatencyAlpha)*oldAvg
+		}
+
+		newBits := math.Float64bits(newAvg)
+		if newBits == 0 {
+			// keep zero reserved for the empty state
+			newBits = math.Float64bits(math.SmallestNonzeroFloat64)
+		}
+
+		if atomic.CompareAndSwapUint64(&t.ewma, oldBits, newBits) {
+			break
+		}
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.samples[t.samplesNext] = d
+	t.samplesNext = (t.samplesNext + 1) % commitLatencySamples
+
+	if t.samplesLen < commitLatencySamples {
+		t.samplesLen++
+	}
+}
+
+func (t *commitLatencyTracker) avg() time.Duration {
+	bits := atomic.LoadUint64(&t.ewma)
+	if bits == 0 {
+		return 0
+	}
+
+	return time.Duration(math.Float64frombits(bits))
+}
+
+func (t *commitLatencyTracker) p99() time.Duration {
+	t.mutex.Lock()
+
+	if t.samplesLen == 0 {
+		t.mutex.Unlock()
+		return 0
+	}
+
+	samples := make([]time.Duration, t.samplesLen)
+	copy(samples, t.samples[:t.samplesLen])
+
+	t.mutex.Unlock()
+
+	sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+
+	i := int(math.Ceil(0.99*float64(len(samples)))) - 1
+
+	return samples[i]
+}
+
+// AvgCommitLatency returns the exponentially-weighted moving average (alpha 0.1) of the latency
+// of successful commits, 0 if no transaction was committed since the store was opened
+func (s *ImmuStore) AvgCommitLatency() time.Duration {
+	return s.commitLatency.avg()
+}
+
+// P99CommitLatency returns the 99th percentile latency of the last 1000 successful commits,
+// 0 if no transaction was committed since the store was opened
+func (s *ImmuStore) P99CommitLatency() time.Duration {
+	return s.commitLatency.p99()
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2022 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommitLatencyTracker(t *testing.T) {
+	tracker := newCommitLatencyTracker()
+
+	require.Zero(t, tracker.avg())
+	require.Zero(t, tracker.p99())
+
+	tracker.observe(100 * time.Millisecond)
+	require.Equal(t, 100*time.Millisecond, tracker.avg())
+	require.Equal(t, 100*time.Millisecond, tracker.p99())
+
+	tracker.observe(200 * time.Millisecond)
+	require.Equal(t, 110*time.Millisecond, tracker.avg())
+	require.Equal(t, 200*time.Millisecond, tracker.p99())
+
+	for i := 1; i <= commitLatencySamples; i++ {
+		tracker.observe(time.Duration(i) * time.Millisecond)
+	}
+
+	// only the last samples are kept
+	require.Equal(t, 990*time.Millisecond, tracker.p99())
+}
+
+func TestImmudbStoreCommitLatency(t *testing.T) {
+	immuStore, cleanup, err := NewTempStore(DefaultOptions())
+	require.NoError(t, err)
+	defer cleanup()
+
+	require.Zero(t, immuStore.AvgCommitLatency())
+	require.Zero(t, immuStore.P99CommitLatency())
+
+	for i := 0; i < 10; i++ {
+		tx, err := immuStore.NewWriteOnlyTx()
+		require.NoError(t, err)
+
+		err = tx.Set([]byte("key"), nil, []byte("value"))
+		require.NoError(t, err)
+
+		_, err = tx.Commit()
+		require.NoError(t, err)
+	}
+
+	require.Greater(t, int64(immuStore.AvgCommitLatency()), int64(0))
+	require.Greater(t, int64(immuStore.P99CommitLatency()), int64(0))
+}
@@ -0,0 +1,195 @@
+/*
+Copyright 2022 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package store
+
+import (
+	"crypto/sha256"
+	"sync"
+	"time"
+
+	"github.com/codenotary/immudb/embedded/watchers"
+)
+
+// CommitResult holds the outcome of a transaction submitted to a CommitPipeline
+type CommitResult struct {
+	TxID uint64
+	Alh  [sha256.Size]byte
+	Err  error
+}
+
+type pipelinedTx struct {
+	otx      *OngoingTx
+	hdr      *TxHeader
+	resultCh chan CommitResult
+
+	// when the commit started, used to track the commit latency as done for regular commits
+	start time.Time
+}
+
+// CommitPipeline commits submitted transactions asynchronously. Transactions are pre-committed
+// as soon as they are dequeued while durability is awaited separately, so transactions pending
+// at the same time are synced together
+type CommitPipeline struct {
+	st *ImmuStore
+
+	// pendingSem bounds the number of submitted transactions whose result was not yet delivered
+	pendingSem chan struct{}
+
+	precommitCh chan *pipelinedTx
+	commitCh    chan *pipelinedTx
+
+	wg sync.WaitGroup
+
+	closed bool
+	mutex  sync.RWMutex
+}
+
+// PipelineCommit creates a CommitPipeline accepting up to maxPending transactions
+// not yet committed, further submissions block until a pending commit completes
+func (s *ImmuStore) PipelineCommit(maxPending int) (*CommitPipeline, error) {
+	if maxPending <= 0 {
+		return nil, ErrIllegalArguments
+	}
+
+	s.mutex.Lock()
+	closed := s.closed
+	s.mutex.Unlock()
+
+	if closed {
+		return nil, ErrAlreadyClosed
+	}
+
+	p := &CommitPipeline{
+		st:          s,
+		pendingSem:  make(chan struct{}, maxPending),
+		precommitCh: make(chan *pipelinedTx, maxPending),
+		commitCh:    make(chan *pipelinedTx, maxPending),
+	}
+
+	p.wg.Add(2)
+
+	go p.precommitter()
+	go p.committer()
+
+	return p, nil
+}
+
+// Submit enqueues a transaction with the provided entries. Invalid entries are reported right away,
+// otherwise the returned channel receives the result once the transaction is committed
+func (p *CommitPipeline) Submit(entries []*EntrySpec) (<-chan CommitResult, error) {
+	if len(entries) == 0 {
+		return nil, ErrIllegalArguments
+	}
+
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	if p.closed {
+		return nil, ErrAlreadyClosed
+	}
+
+	otx, err := p.st.NewWriteOnlyTx()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, e := range entries {
+		if e == nil {
+			otx.Cancel()
+			return nil, ErrIllegalArguments
+		}
+
+		err = otx.Set(e.Key, e.Metadata, e.Value)
+		if err != nil {
+			otx.Cancel()
+			return nil, err
+		}
+	}
+
+	ptx := &pipelinedTx{
+		otx:      otx,
+		resultCh: make(chan CommitResult, 1),
+	}
+
+	p.pendingSem <- struct{}{}
+	p.precommitCh <- ptx
+
+	return ptx.resultCh, nil
+}
+
+func (p *CommitPipeline) precommitter() {
+	defer p.wg.Done()
+	defer close(p.commitCh)
+
+	for ptx := range p.precommitCh {
+		ptx.start = time.Now()
+
+		hdr, err := p.st.precommit(ptx.otx, nil, false)
+		if err != nil {
+			p.deliver(ptx, CommitResult{Err: err})
+			continue
+		}
+
+		ptx.hdr = hdr
+
+		p.commitCh <- ptx
+	}
+}
+
+func (p *CommitPipeline) committer() {
+	defer p.wg.Done()
+
+	for ptx := range p.commitCh {
+		err := p.st.commitWHub.WaitFor(ptx.hdr.ID, nil)
+		if err == watchers.ErrAlreadyClosed {
+			err = ErrAlreadyClosed
+		}
+		if err != nil {
+			p.deliver(ptx, CommitResult{TxID: ptx.hdr.ID, Err: err})
+			continue
+		}
+
+		p.st.commitLatency.observe(time.Since(ptx.start))
+
+		p.deliver(ptx, CommitResult{TxID: ptx.hdr.ID, Alh: ptx.hdr.Alh()})
+	}
+}
+
+func (p *CommitPipeline) deliver(ptx *pipelinedTx, result CommitResult) {
+	ptx.resultCh <- result
+	close(ptx.resultCh)
+
+	<-p.pendingSem
+}
+
+// Close stops accepting new transactions and waits until every pending one is committed
+func (p *CommitPipeline) Close() error {
+	p.mutex.Lock()
+
+	if p.closed {
+		p.mutex.Unlock()
+		return ErrAlreadyClosed
+	}
+
+	p.closed = true
+	close(p.precommitCh)
+
+	p.mutex.Unlock()
+
+	p.wg.Wait()
+
+	return nil
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2022 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package store
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestImmudbStoreCommitPipeline(t *testing.T) {
+	immuStore, cleanup, err := NewTempStore(DefaultOptions())
+	require.NoError(t, err)
+	defer cleanup()
+
+	_, err = immuStore.PipelineCommit(0)
+	require.ErrorIs(t, err, ErrIllegalArguments)
+
+	pipeline, err := immuStore.PipelineCommit(4)
+	require.NoError(t, err)
+
+	_, err = pipeline.Submit(nil)
+	require.ErrorIs(t, err, ErrIllegalArguments)
+
+	_, err = pipeline.Submit([]*EntrySpec{{Key: nil, Value: []byte("value")}})
+	require.ErrorIs(t, err, ErrNullKey)
+
+	const producers = 4
+	const txsPerProducer = 25
+
+	var wg sync.WaitGroup
+	results := make(chan CommitResult, producers*txsPerProducer)
+
+	for p := 0; p < producers; p++ {
+		wg.Add(1)
+
+		go func(p int) {
+			defer wg.Done()
+
+			var resultChs []<-chan CommitResult
+
+			for i := 0; i < txsPerProducer; i++ {
+				resultCh, err := pipeline.Submit([]*EntrySpec{{
+					Key:   []byte(fmt.Sprintf("key_%d_%d", p, i)),
+					Value: []byte("value"),
+				}})
+				require.NoError(t, err)
+
+				resultChs = append(resultChs, resultCh)
+			}
+
+			for _, resultCh := range resultChs {
+				results <- <-resultCh
+			}
+		}(p)
+	}
+
+	wg.Wait()
+	close(results)
+
+	txIDs := make(map[uint64]struct{})
+
+	for result := range results {
+		require.NoError(t, result.Err)
+
+		hdr, err := immuStore.ReadTxHeader(result.TxID)
+		require.NoError(t, err)
+		require.Equal(t, hdr.Alh(), result.Alh)
+
+		txIDs[result.TxID] = struct{}{}
+	}
+
+	require.Len(t, txIDs, producers*txsPerProducer)
+
+	// pipelined commits are tracked as regular commits
+	require.Greater(t, int64(immuStore.AvgCommitLatency()), int64(0))
+	require.Greater(t, int64(immuStore.P99CommitLatency()), int64(0))
+
+	resultCh, err := pipeline.Submit([]*EntrySpec{{Key: []byte("last"), Value: []byte("value")}})
+	require.NoError(t, err)
+
+	err = pipeline.Close()
+	require.NoError(t, err)
+
+	// pending transactions are committed before Close returns
+	result := <-resultCh
+	require.NoError(t, result.Err)
+	require.Equal(t, uint64(producers*txsPerProducer+1), immuStore.TxCount())
+
+	_, err = pipeline.Submit([]*EntrySpec{{Key: []byte("key"), Value: []byte("value")}})
+	require.ErrorIs(t, err, ErrAlreadyClosed)
+
+	err = pipeline.Close()
+	require.ErrorIs(t, err, ErrAlreadyClosed)
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2022 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package store
+
+import (
+	"crypto/sha256"
+)
+
+// DiffReport describes the first transaction at which two stores diverge.
+// A zero Alh means the transaction is not present in the corresponding store
+type DiffReport struct {
+	FirstMismatchTxID uint64
+	PrimaryAlh        [sha256.Size]byte
+	ReplicaAlh        [sha256.Size]byte
+}
+
+// CompareStores compares the Alh of each transaction from fromTxID up to toTxID in both stores.
+// The first mismatch found is reported, nil is returned when both stores hold the same transactions.
+// Transactions not yet committed in other are reported as mismatches
+func (s *ImmuStore) CompareStores(other *ImmuStore, fromTxID, toTxID uint64) (*DiffReport, error) {
+	if other == nil || fromTxID == 0 || fromTxID > toTxID {
+		return nil, ErrIllegalArguments
+	}
+
+	if toTxID > s.lastCommittedTxID() {
+		return nil, ErrTxNotFound
+	}
+
+	replicaAlhAt := func(txID uint64) ([sha256.Size]byte, error) {
+		if txID > other.lastCommittedTxID() {
+			return [sha256.Size]byte{}, nil
+		}
+
+		hdr, err := other.ReadTxHeader(txID)
+		if err != nil {
+			return [sha256.Size]byte{}, err
+		}
+
+		return hdr.Alh(), nil
+	}
+
+	// as Alh values are chained, a match at toTxID means every previous transaction also matches
+	hdr, err := s.ReadTxHeader(toTxID)
+	if err != nil {
+		return nil, err
+	}
+
+	replicaAlh, err := replicaAlhAt(toTxID)
+	if err != nil {
+		return nil, err
+	}
+
+	if hdr.Alh() == replicaAlh {
+		return nil, nil
+	}
+
+	for txID := fromTxID; txID <= toTxID; txID++ {
+		hdr, err := s.ReadTxHeader(txID)
+		if err != nil {
+			return nil, err
+		}
+
+		replicaAlh, err := replicaAlhAt(txID)
+		if err != nil {
+			return nil, err
+		}
+
+		if hdr.Alh() != replicaAlh {
+			return &DiffReport{
+				FirstMismatchTxID: txID,
+				PrimaryAlh:        hdr.Alh(),
+				ReplicaAlh:        replicaAlh,
+			}, nil
+		}
+	}
+
+	return nil, nil
+}
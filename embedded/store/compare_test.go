@@ -0,0 +1,99 @@
+/*
+Copyright 2022 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package store
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompareStores(t *testing.T) {
+	openStore := func(name string) *ImmuStore {
+		dir, err := ioutil.TempDir("", name)
+		require.NoError(t, err)
+		t.Cleanup(func() { os.RemoveAll(dir) })
+
+		// a fixed time makes transactions of both stores identical
+		opts := DefaultOptions().WithTimeFunc(func() time.Time { return time.Unix(1000, 0) })
+
+		st, err := Open(dir, opts)
+		require.NoError(t, err)
+		t.Cleanup(func() { st.Close() })
+
+		return st
+	}
+
+	set := func(st *ImmuStore, key, value string) {
+		tx, err := st.NewWriteOnlyTx()
+		require.NoError(t, err)
+
+		err = tx.Set([]byte(key), nil, []byte(value))
+		require.NoError(t, err)
+
+		_, err = tx.Commit()
+		require.NoError(t, err)
+	}
+
+	primary := openStore("data_compare_primary")
+	replica := openStore("data_compare_replica")
+
+	for i := 0; i < 5; i++ {
+		set(primary, fmt.Sprintf("key%d", i), "value")
+		set(replica, fmt.Sprintf("key%d", i), "value")
+	}
+
+	_, err := primary.CompareStores(nil, 1, 5)
+	require.ErrorIs(t, err, ErrIllegalArguments)
+
+	_, err = primary.CompareStores(replica, 0, 5)
+	require.ErrorIs(t, err, ErrIllegalArguments)
+
+	_, err = primary.CompareStores(replica, 3, 2)
+	require.ErrorIs(t, err, ErrIllegalArguments)
+
+	_, err = primary.CompareStores(replica, 1, 6)
+	require.ErrorIs(t, err, ErrTxNotFound)
+
+	report, err := primary.CompareStores(replica, 1, 5)
+	require.NoError(t, err)
+	require.Nil(t, report)
+
+	set(primary, "key5", "value")
+	set(primary, "key6", "value")
+	set(replica, "key5", "diverging_value")
+
+	report, err = primary.CompareStores(replica, 1, 7)
+	require.NoError(t, err)
+	require.NotNil(t, report)
+	require.Equal(t, uint64(6), report.FirstMismatchTxID)
+
+	primaryHdr, err := primary.ReadTxHeader(6)
+	require.NoError(t, err)
+	require.Equal(t, primaryHdr.Alh(), report.PrimaryAlh)
+
+	replicaHdr, err := replica.ReadTxHeader(6)
+	require.NoError(t, err)
+	require.Equal(t, replicaHdr.Alh(), report.ReplicaAlh)
+
+	report, err = replica.CompareStores(primary, 1, 5)
+	require.NoError(t, err)
+	require.Nil(t, report)
+}
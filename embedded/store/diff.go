@@ -0,0 +1,120 @@
+/*
+Copyright 2022 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package store
+
+import (
+	"bytes"
+	"sort"
+)
+
+// KVDiff describes a key whose value changed between two transactions
+type KVDiff struct {
+	Key []byte
+	// ValueAtTx1 and ValueAtTx2 are nil when the key was not present, deleted or expired at such transaction
+	ValueAtTx1 []byte
+	ValueAtTx2 []byte
+	// TxIDs are the transactions after txID1 and up to txID2 in which the key was written, in ascending order
+	TxIDs []uint64
+}
+
+// Diff returns, in key order, the keys whose value at txID2 differs from their value at txID1.
+// Keys written in between but restored to their previous value are not included.
+// Note: every transaction after txID1 and up to txID2 is read
+func (s *ImmuStore) Diff(txID1, txID2 uint64) ([]*KVDiff, error) {
+	if txID1 == 0 || txID1 > txID2 {
+		return nil, ErrIllegalArguments
+	}
+
+	if txID2 > s.lastCommittedTxID() {
+		return nil, ErrTxNotFound
+	}
+
+	if txID1 == txID2 {
+		return nil, nil
+	}
+
+	tx, err := s.fetchAllocTx()
+	if err != nil {
+		return nil, err
+	}
+	defer s.releaseAllocTx(tx)
+
+	r, err := s.NewTxReader(txID1+1, false, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	writes := make(map[string][]uint64)
+
+	for txID := txID1 + 1; txID <= txID2; txID++ {
+		tx, err := r.Read()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, e := range tx.Entries() {
+			writes[string(e.key())] = append(writes[string(e.key())], txID)
+		}
+	}
+
+	keys := make([][]byte, 0, len(writes))
+	for k := range writes {
+		keys = append(keys, []byte(k))
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return bytes.Compare(keys[i], keys[j]) < 0
+	})
+
+	kvs1, err := s.GetManyWithMVCC(keys, txID1)
+	if err != nil {
+		return nil, err
+	}
+
+	kvs2, err := s.GetManyWithMVCC(keys, txID2)
+	if err != nil {
+		return nil, err
+	}
+
+	var diffs []*KVDiff
+
+	for i, key := range keys {
+		if kvs1[i] == nil && kvs2[i] == nil {
+			continue
+		}
+
+		if kvs1[i] != nil && kvs2[i] != nil && bytes.Equal(kvs1[i].Value, kvs2[i].Value) {
+			continue
+		}
+
+		diff := &KVDiff{
+			Key:   key,
+			TxIDs: writes[string(key)],
+		}
+
+		if kvs1[i] != nil {
+			diff.ValueAtTx1 = kvs1[i].Value
+		}
+
+		if kvs2[i] != nil {
+			diff.ValueAtTx2 = kvs2[i].Value
+		}
+
+		diffs = append(diffs, diff)
+	}
+
+	return diffs, nil
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2022 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package store
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestImmudbStoreDiff(t *testing.T) {
+	immuStore, cleanup, err := NewTempStore(DefaultOptions())
+	require.NoError(t, err)
+	defer cleanup()
+
+	_, err = immuStore.Diff(0, 1)
+	require.ErrorIs(t, err, ErrIllegalArguments)
+
+	_, err = immuStore.Diff(2, 1)
+	require.ErrorIs(t, err, ErrIllegalArguments)
+
+	_, err = immuStore.Diff(1, 2)
+	require.ErrorIs(t, err, ErrTxNotFound)
+
+	set := func(kvs ...string) uint64 {
+		tx, err := immuStore.NewWriteOnlyTx()
+		require.NoError(t, err)
+
+		for i := 0; i < len(kvs); i += 2 {
+			err = tx.Set([]byte(kvs[i]), nil, []byte(kvs[i+1]))
+			require.NoError(t, err)
+		}
+
+		hdr, err := tx.Commit()
+		require.NoError(t, err)
+
+		return hdr.ID
+	}
+
+	txID1 := set("key1", "value1", "key2", "value2", "key3", "value3")
+
+	set("key1", "value1_1", "key2", "value2_1")
+	set("key2", "value2", "key4", "value4")
+
+	tx, err := immuStore.NewTx()
+	require.NoError(t, err)
+
+	err = tx.Delete([]byte("key3"))
+	require.NoError(t, err)
+
+	hdr, err := tx.Commit()
+	require.NoError(t, err)
+
+	txID2 := hdr.ID
+
+	set("key1", "value1_2")
+
+	diffs, err := immuStore.Diff(txID1, txID1)
+	require.NoError(t, err)
+	require.Empty(t, diffs)
+
+	diffs, err = immuStore.Diff(txID1, txID2)
+	require.NoError(t, err)
+	require.Len(t, diffs, 3)
+
+	require.Equal(t, []byte("key1"), diffs[0].Key)
+	require.Equal(t, []byte("value1"), diffs[0].ValueAtTx1)
+	require.Equal(t, []byte("value1_1"), diffs[0].ValueAtTx2)
+	require.Equal(t, []uint64{2}, diffs[0].TxIDs)
+
+	// key2 was restored to its previous value
+	require.Equal(t, []byte("key3"), diffs[1].Key)
+	require.Equal(t, []byte("value3"), diffs[1].ValueAtTx1)
+	require.Nil(t, diffs[1].ValueAtTx2)
+	require.Equal(t, []uint64{4}, diffs[1].TxIDs)
+
+	require.Equal(t, []byte("key4"), diffs[2].Key)
+	require.Nil(t, diffs[2].ValueAtTx1)
+	require.Equal(t, []byte("value4"), diffs[2].ValueAtTx2)
+	require.Equal(t, []uint64{3}, diffs[2].TxIDs)
+}
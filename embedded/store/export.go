@@ -0,0 +1,139 @@
+/*
+Copyright 2022 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package store
+
+import (
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+)
+
+type ExportFormat int
+
+const (
+	ExportFormatCSV ExportFormat = iota
+	ExportFormatJSON
+)
+
+var exportCSVHeader = []string{"txID", "ts", "key_hex", "value_hex", "value_hash"}
+
+type exportedEntry struct {
+	TxID      uint64 `json:"txID"`
+	Ts        int64  `json:"ts"`
+	KeyHex    string `json:"key_hex"`
+	ValueHex  string `json:"value_hex"`
+	ValueHash string `json:"value_hash"`
+}
+
+// ExportEntries writes to w every entry of the transactions from fromTxID up to toTxID (both included)
+// along with the id and timestamp of the transaction, one row per entry in commit order.
+// Rows are written as they are read, CSV output starts with a header row and JSON output is one object per line.
+// Entries already expired at the time of the export are not included
+func (s *ImmuStore) ExportEntries(w io.Writer, fromTxID, toTxID uint64, format ExportFormat) error {
+	if w == nil || fromTxID == 0 || fromTxID > toTxID {
+		return ErrIllegalArguments
+	}
+
+	if format != ExportFormatCSV && format != ExportFormatJSON {
+		return ErrIllegalArguments
+	}
+
+	if toTxID > s.lastCommittedTxID() {
+		return ErrTxNotFound
+	}
+
+	tx, err := s.fetchAllocTx()
+	if err != nil {
+		return err
+	}
+	defer s.releaseAllocTx(tx)
+
+	r, err := s.NewTxReader(fromTxID, false, tx)
+	if err != nil {
+		return err
+	}
+
+	var csvWriter *csv.Writer
+	var jsonEncoder *json.Encoder
+
+	if format == ExportFormatCSV {
+		csvWriter = csv.NewWriter(w)
+
+		err = csvWriter.Write(exportCSVHeader)
+		if err != nil {
+			return err
+		}
+	} else {
+		jsonEncoder = json.NewEncoder(w)
+	}
+
+	for txID := fromTxID; txID <= toTxID; txID++ {
+		tx, err := r.Read()
+		if err != nil {
+			return err
+		}
+
+		for _, e := range tx.Entries() {
+			val, err := s.ReadValue(e)
+			if errors.Is(err, ErrExpiredEntry) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+
+			hVal := e.HVal()
+
+			row := &exportedEntry{
+				TxID:      tx.header.ID,
+				Ts:        tx.header.Ts,
+				KeyHex:    hex.EncodeToString(e.key()),
+				ValueHex:  hex.EncodeToString(val),
+				ValueHash: hex.EncodeToString(hVal[:]),
+			}
+
+			if csvWriter != nil {
+				err = csvWriter.Write([]string{
+					strconv.FormatUint(row.TxID, 10),
+					strconv.FormatInt(row.Ts, 10),
+					row.KeyHex,
+					row.ValueHex,
+					row.ValueHash,
+				})
+			} else {
+				err = jsonEncoder.Encode(row)
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		// rows are flushed once per transaction so the output is not held in memory
+		if csvWriter != nil {
+			csvWriter.Flush()
+
+			err = csvWriter.Error()
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
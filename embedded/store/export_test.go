@@ -0,0 +1,122 @@
+/*
+Copyright 2022 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package store
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestImmudbStoreExportEntries(t *testing.T) {
+	immuStore, cleanup, err := NewTempStore(DefaultOptions())
+	require.NoError(t, err)
+	defer cleanup()
+
+	var hdrs []*TxHeader
+
+	for i := 0; i < 3; i++ {
+		tx, err := immuStore.NewWriteOnlyTx()
+		require.NoError(t, err)
+
+		err = tx.Set([]byte("key"), nil, []byte{byte(i)})
+		require.NoError(t, err)
+
+		err = tx.Set([]byte("key"+strconv.Itoa(i)), nil, []byte("value"))
+		require.NoError(t, err)
+
+		hdr, err := tx.Commit()
+		require.NoError(t, err)
+
+		hdrs = append(hdrs, hdr)
+	}
+
+	var buf bytes.Buffer
+
+	t.Run("invalid arguments", func(t *testing.T) {
+		err := immuStore.ExportEntries(nil, 1, 1, ExportFormatCSV)
+		require.ErrorIs(t, err, ErrIllegalArguments)
+
+		err = immuStore.ExportEntries(&buf, 0, 1, ExportFormatCSV)
+		require.ErrorIs(t, err, ErrIllegalArguments)
+
+		err = immuStore.ExportEntries(&buf, 2, 1, ExportFormatCSV)
+		require.ErrorIs(t, err, ErrIllegalArguments)
+
+		err = immuStore.ExportEntries(&buf, 1, 1, ExportFormat(-1))
+		require.ErrorIs(t, err, ErrIllegalArguments)
+
+		err = immuStore.ExportEntries(&buf, 1, 4, ExportFormatCSV)
+		require.ErrorIs(t, err, ErrTxNotFound)
+
+		require.Zero(t, buf.Len())
+	})
+
+	t.Run("csv", func(t *testing.T) {
+		buf.Reset()
+
+		err := immuStore.ExportEntries(&buf, 2, 3, ExportFormatCSV)
+		require.NoError(t, err)
+
+		records, err := csv.NewReader(&buf).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, records, 5)
+		require.Equal(t, []string{"txID", "ts", "key_hex", "value_hex", "value_hash"}, records[0])
+
+		hash := sha256.Sum256([]byte{1})
+
+		require.Equal(t, []string{
+			"2",
+			strconv.FormatInt(hdrs[1].Ts, 10),
+			hex.EncodeToString([]byte("key")),
+			"01",
+			hex.EncodeToString(hash[:]),
+		}, records[1])
+
+		require.Equal(t, "3", records[4][0])
+		require.Equal(t, hex.EncodeToString([]byte("key2")), records[4][2])
+		require.Equal(t, hex.EncodeToString([]byte("value")), records[4][3])
+	})
+
+	t.Run("json", func(t *testing.T) {
+		buf.Reset()
+
+		err := immuStore.ExportEntries(&buf, 1, 1, ExportFormatJSON)
+		require.NoError(t, err)
+
+		lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+		require.Len(t, lines, 2)
+
+		var row map[string]interface{}
+		err = json.Unmarshal([]byte(lines[1]), &row)
+		require.NoError(t, err)
+
+		hash := sha256.Sum256([]byte("value"))
+
+		require.EqualValues(t, 1, row["txID"])
+		require.EqualValues(t, hdrs[0].Ts, row["ts"])
+		require.Equal(t, hex.EncodeToString([]byte("key0")), row["key_hex"])
+		require.Equal(t, hex.EncodeToString([]byte("value")), row["value_hex"])
+		require.Equal(t, hex.EncodeToString(hash[:]), row["value_hash"])
+	})
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2022 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package store
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrHistoryTampered = errors.New("history tampered")
+
+const historyVerificationPageSize = 256
+
+// VerifyHistoryConsistency validates every indexed entry of key: transaction ids must be strictly increasing,
+// each transaction must contain the key, the entry must be included in the transaction and the stored value
+// must match its hash. ErrHistoryTampered is returned when any of those checks fails
+func VerifyHistoryConsistency(key []byte, st *ImmuStore) error {
+	if st == nil {
+		return ErrIllegalArguments
+	}
+
+	if len(key) == 0 {
+		return ErrNullKey
+	}
+
+	tx, err := st.fetchAllocTx()
+	if err != nil {
+		return err
+	}
+	defer st.releaseAllocTx(tx)
+
+	var prevTxID uint64
+
+	for offset := uint64(0); ; {
+		txIDs, hCount, err := st.History(key, offset, false, historyVerificationPageSize)
+		if err == ErrOffsetOutOfRange && offset > 0 {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		for _, txID := range txIDs {
+			if txID <= prevTxID {
+				return fmt.Errorf("%w: tx %d listed after tx %d", ErrHistoryTampered, txID, prevTxID)
+			}
+
+			err = verifyHistoryEntry(st, tx, txID, key)
+			if err != nil {
+				return err
+			}
+
+			prevTxID = txID
+		}
+
+		offset += uint64(len(txIDs))
+
+		if offset >= hCount {
+			return nil
+		}
+	}
+}
+
+func verifyHistoryEntry(st *ImmuStore, tx *Tx, txID uint64, key []byte) error {
+	err := st.ReadTx(txID, tx)
+	if errors.Is(err, ErrorCorruptedTxData) {
+		return fmt.Errorf("%w: %v", ErrHistoryTampered, err)
+	}
+	if err != nil {
+		return err
+	}
+
+	entry, err := tx.EntryOf(key)
+	if errors.Is(err, ErrKeyNotFound) {
+		return fmt.Errorf("%w: key not found in tx %d", ErrHistoryTampered, txID)
+	}
+	if err != nil {
+		return err
+	}
+
+	proof, err := tx.Proof(key)
+	if err != nil {
+		return err
+	}
+
+	txEntryDigest, err := tx.header.TxEntryDigest()
+	if err != nil {
+		return err
+	}
+
+	digest, err := txEntryDigest(entry)
+	if err != nil {
+		return err
+	}
+
+	if !VerifyInclusion(proof, digest, tx.header.Eh) {
+		return fmt.Errorf("%w: inclusion proof of tx %d failed", ErrHistoryTampered, txID)
+	}
+
+	b := make([]byte, entry.vLen)
+
+	_, err = st.readValueAt(b, entry.vOff, entry.hVal)
+	if errors.Is(err, ErrCorruptedData) {
+		return fmt.Errorf("%w: value hash mismatch at tx %d", ErrHistoryTampered, txID)
+	}
+
+	return err
+}
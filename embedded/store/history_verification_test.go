@@ -0,0 +1,74 @@
+/*
+Copyright 2022 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyHistoryConsistency(t *testing.T) {
+	immuStore, cleanup, err := NewTempStore(DefaultOptions())
+	require.NoError(t, err)
+	defer cleanup()
+
+	err = VerifyHistoryConsistency([]byte("key"), nil)
+	require.ErrorIs(t, err, ErrIllegalArguments)
+
+	err = VerifyHistoryConsistency(nil, immuStore)
+	require.ErrorIs(t, err, ErrNullKey)
+
+	err = VerifyHistoryConsistency([]byte("key"), immuStore)
+	require.ErrorIs(t, err, ErrKeyNotFound)
+
+	for i := 0; i < 300; i++ {
+		tx, err := immuStore.NewWriteOnlyTx()
+		require.NoError(t, err)
+
+		err = tx.Set([]byte("key"), nil, []byte{byte(i), byte(i >> 8)})
+		require.NoError(t, err)
+
+		err = tx.Set([]byte("other"), nil, []byte("value"))
+		require.NoError(t, err)
+
+		_, err = tx.Commit()
+		require.NoError(t, err)
+	}
+
+	err = VerifyHistoryConsistency([]byte("key"), immuStore)
+	require.NoError(t, err)
+
+	// tamper the stored values
+	vLogPath := filepath.Join(immuStore.path, "val_0", "00000000.val")
+
+	info, err := os.Stat(vLogPath)
+	require.NoError(t, err)
+
+	f, err := os.OpenFile(vLogPath, os.O_WRONLY, 0)
+	require.NoError(t, err)
+
+	_, err = f.WriteAt(make([]byte, 64), info.Size()-64)
+	require.NoError(t, err)
+
+	err = f.Close()
+	require.NoError(t, err)
+
+	err = VerifyHistoryConsistency([]byte("key"), immuStore)
+	require.ErrorIs(t, err, ErrHistoryTampered)
+}
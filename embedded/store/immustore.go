@@ -21,11 +21,16 @@ import (
 	"crypto/sha256"
 	"encoding/base64"
 	"encoding/binary"
+	"encoding/csv"
 	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
+	"math"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"sync"
 	"time"
 
@@ -74,11 +79,21 @@ var ErrTxNotFound = errors.New("tx not found")
 var ErrNoMoreEntries = tbtree.ErrNoMoreEntries
 var ErrIllegalState = tbtree.ErrIllegalState
 var ErrOffsetOutOfRange = tbtree.ErrOffsetOutOfRange
+var ErrNoSnapshotAvailable = tbtree.ErrNoSnapshotAvailable
+var ErrIndexerAlreadyDetached = errors.New("indexer already detached")
+var ErrIndexerNotDetached = errors.New("indexer not detached")
 var ErrUnexpectedError = errors.New("unexpected error")
 var ErrUnsupportedTxVersion = errors.New("unsupported tx version")
 var ErrNewerVersionOrCorruptedData = errors.New("tx created with a newer version or data is corrupted")
 var ErrInvalidOptions = errors.New("invalid options")
 var ErrTxPoolExhausted = errors.New("transaction pool exhausted")
+var ErrStoreLocked = errors.New("store is locked by another process")
+var ErrFlockUnsupported = errors.New("file locking is not supported on this platform")
+var ErrConflict = errors.New("too many concurrent updates")
+var ErrInvalidCounter = errors.New("value is not a valid counter")
+var ErrReservedKey = errors.New("key prefix is reserved by the store")
+var ErrVersionConflict = errors.New("key was modified by a different transaction")
+var ErrHashNotFound = errors.New("no transaction found with the specified hash")
 
 var ErrInvalidPrecondition = errors.New("invalid precondition")
 var ErrInvalidPreconditionTooMany = fmt.Errorf("%w: too many preconditions", ErrInvalidPrecondition)
@@ -89,6 +104,8 @@ var ErrInvalidPreconditionInvalidTxID = fmt.Errorf("%w: invalid transaction ID",
 
 var ErrSourceTxNewerThanTargetTx = errors.New("source tx is newer than target tx")
 var ErrLinearProofMaxLenExceeded = errors.New("max linear proof length limit exceeded")
+var ErrInvalidProof = errors.New("invalid proof")
+var ErrBlRootMismatch = errors.New("binary linking root mismatch")
 
 var ErrCompactionUnsupported = errors.New("compaction is unsupported when remote storage is used")
 
@@ -123,6 +140,10 @@ const (
 
 const indexDirname = "index"
 const ahtDirname = "aht"
+const tagsDirname = "tags"
+const migrationMappingFilename = "txID_mapping.csv"
+
+const flockRetryInterval = 100 * time.Millisecond
 
 type ImmuStore struct {
 	path string
@@ -131,6 +152,9 @@ type ImmuStore struct {
 	lastNotification time.Time
 	notifyMutex      sync.Mutex
 
+	onErrorHook      func(err error)
+	onErrorHookMutex sync.RWMutex
+
 	vLogs            map[byte]*refVLog
 	vLogUnlockedList *list.List
 	vLogsCond        *sync.Cond
@@ -138,8 +162,17 @@ type ImmuStore struct {
 	txLog      appendable.Appendable
 	txLogCache *cache.LRUCache
 
+	// vLogCache holds value log pages, nil when disabled
+	vLogCache *cache.LRUCache
+
+	keyMetricsCache *cache.LRUCache
+
+	commitLatency *commitLatencyTracker
+
 	cLog    appendable.Appendable
 	cLogBuf []byte
+	// cLogAlhBuf holds the Alh of the transactions in cLogBuf, passed to post-commit hooks
+	cLogAlhBuf [][sha256.Size]byte
 
 	committedTxID      uint64
 	committedAlh       [sha256.Size]byte
@@ -153,6 +186,7 @@ type ImmuStore struct {
 
 	readOnly              bool
 	synced                bool
+	fileMode              os.FileMode
 	syncFrequency         time.Duration
 	maxActiveTransactions int
 	maxWaitees            int
@@ -162,6 +196,23 @@ type ImmuStore struct {
 	maxKeyLen             int
 	maxValueLen           int
 	maxLinearProofLen     int
+	maxIncrementRetries   int
+
+	overwriteOnRename bool
+
+	// serializes AtomicMultiUpdate calls
+	atomicUpdateMutex sync.Mutex
+
+	// replication sessions started with StartReplication and not yet stopped
+	replicationSessions      map[*ReplicationSession]struct{}
+	replicationSessionsMutex sync.Mutex
+
+	postCommitHooks      []postCommitHook
+	lastPostCommitHookID HookID
+	postCommitHooksMutex sync.RWMutex
+
+	// readDeadline bounds the duration of each read (nanoseconds), zero when unbounded
+	readDeadline int64
 
 	maxTxSize int
 
@@ -183,15 +234,20 @@ type ImmuStore struct {
 
 	precommitWHub *watchers.WatchersHub
 	commitWHub    *watchers.WatchersHub
+	observersWHub *watchers.WatchersHub // key observers are not subject to the waitees limit
 
 	indexer *indexer
 
+	tags *tagStore
+
 	closed bool
 	blDone chan (struct{})
 
 	mutex sync.Mutex
 
 	compactionDisabled bool
+
+	flock *os.File
 }
 
 type refVLog struct {
@@ -199,6 +255,28 @@ type refVLog struct {
 	unlockedRef *list.Element // unlockedRef == nil <-> vLog is locked
 }
 
+// NewTempStore opens a store in a newly created temporary directory.
+// The returned cleanup function closes the store and removes the directory
+func NewTempStore(opts *Options) (*ImmuStore, func(), error) {
+	path, err := ioutil.TempDir("", "immudb_store")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	st, err := Open(path, opts)
+	if err != nil {
+		os.RemoveAll(path)
+		return nil, nil, err
+	}
+
+	cleanup := func() {
+		st.Close()
+		os.RemoveAll(path)
+	}
+
+	return st, cleanup, nil
+}
+
 func Open(path string, opts *Options) (*ImmuStore, error) {
 	err := opts.Validate()
 	if err != nil {
@@ -219,6 +297,51 @@ func Open(path string, opts *Options) (*ImmuStore, error) {
 		return nil, ErrorPathIsNotADirectory
 	}
 
+	if !opts.UseFlock {
+		return open(path, opts)
+	}
+
+	flock, err := acquireFlock(path, opts.FileMode)
+	if err != nil {
+		return nil, err
+	}
+
+	store, err := open(path, opts)
+	if err != nil {
+		releaseFlock(flock)
+		return nil, err
+	}
+
+	store.flock = flock
+
+	return store, nil
+}
+
+// TryOpen behaves as Open but when the store is locked by another process
+// it keeps retrying until the lock is released or the timeout is reached
+func TryOpen(path string, opts *Options, timeout time.Duration) (*ImmuStore, error) {
+	deadline := time.Now().Add(timeout)
+
+	for {
+		store, err := Open(path, opts)
+		if !errors.Is(err, ErrStoreLocked) {
+			return store, err
+		}
+
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return nil, err
+		}
+
+		if remaining > flockRetryInterval {
+			remaining = flockRetryInterval
+		}
+
+		time.Sleep(remaining)
+	}
+}
+
+func open(path string, opts *Options) (*ImmuStore, error) {
 	metadata := appendable.NewMetadata(nil)
 	metadata.PutInt(metaVersion, Version)
 	metadata.PutInt(metaMaxTxEntries, opts.MaxTxEntries)
@@ -265,6 +388,7 @@ func Open(path string, opts *Options) (*ImmuStore, error) {
 		appendableOpts.WithCompressionFormat(opts.CompressionFormat)
 		appendableOpts.WithCompresionLevel(opts.CompressionLevel)
 		appendableOpts.WithMaxOpenedFiles(opts.VLogMaxOpenedFiles)
+		appendableOpts.WithMmapSize(opts.VLogMmapSize)
 		vLog, err := appFactory(path, fmt.Sprintf("val_%d", i), appendableOpts)
 		if err != nil {
 			return nil, err
@@ -408,6 +532,34 @@ func OpenWith(path string, vLogs []appendable.Appendable, txLog, cLog appendable
 		return nil, fmt.Errorf("could not open aht: %w", err)
 	}
 
+	tagsAppFactory := opts.appFactory
+	if tagsAppFactory == nil {
+		tagsAppFactory = func(rootPath, subPath string, opts *multiapp.Options) (appendable.Appendable, error) {
+			return multiapp.Open(filepath.Join(rootPath, subPath), opts)
+		}
+	}
+
+	tagsLogOpts := multiapp.DefaultOptions().
+		WithReadOnly(opts.ReadOnly).
+		WithSynced(false).
+		WithFileMode(opts.FileMode).
+		WithFileSize(fileSize).
+		WithFileExt("tag")
+
+	tagsLog, err := tagsAppFactory(path, tagsDirname, tagsLogOpts)
+	if errors.Is(err, os.ErrNotExist) && opts.ReadOnly {
+		// stores created before tags were introduced have no tags log and it can not be created when read-only
+		tagsLog, err = nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not open tags log: %w", err)
+	}
+
+	tags, err := openTagStore(tagsLog, opts.Synced)
+	if err != nil {
+		return nil, fmt.Errorf("could not open tags: %w", err)
+	}
+
 	kvs := make([]*tbtree.KV, maxTxEntries)
 	for i := range kvs {
 		// vLen + vOff + vHash + txmdLen + txmd + kvmdLen + kvmd
@@ -422,6 +574,26 @@ func OpenWith(path string, vLogs []appendable.Appendable, txLog, cLog appendable
 
 	txLogCache, err := cache.NewLRUCache(opts.TxLogCacheSize)
 	if err != nil {
+		tags.Close()
+		return nil, err
+	}
+
+	var vLogCache *cache.LRUCache
+	if opts.VLogCacheSize > 0 {
+		vLogCache, err = cache.NewLRUCache(opts.VLogCacheSize)
+		if err != nil {
+			tags.Close()
+			return nil, err
+		}
+
+		for id, ref := range vLogsMap {
+			ref.vLog = &cachedVLog{Appendable: ref.vLog, vLogID: id + 1, cache: vLogCache}
+		}
+	}
+
+	keyMetricsCache, err := cache.NewLRUCache(keyMetricsCacheSize)
+	if err != nil {
+		tags.Close()
 		return nil, err
 	}
 
@@ -430,6 +602,9 @@ func OpenWith(path string, vLogs []appendable.Appendable, txLog, cLog appendable
 		logger:           opts.logger,
 		txLog:            txLog,
 		txLogCache:       txLogCache,
+		vLogCache:        vLogCache,
+		keyMetricsCache:  keyMetricsCache,
+		commitLatency:    newCommitLatencyTracker(),
 		vLogs:            vLogsMap,
 		vLogUnlockedList: vLogUnlockedList,
 		vLogsCond:        sync.NewCond(&sync.Mutex{}),
@@ -446,6 +621,7 @@ func OpenWith(path string, vLogs []appendable.Appendable, txLog, cLog appendable
 
 		readOnly:              opts.ReadOnly,
 		synced:                opts.Synced,
+		fileMode:              opts.FileMode,
 		syncFrequency:         opts.SyncFrequency,
 		maxActiveTransactions: opts.MaxActiveTransactions,
 		maxWaitees:            opts.MaxWaitees,
@@ -455,6 +631,9 @@ func OpenWith(path string, vLogs []appendable.Appendable, txLog, cLog appendable
 		maxKeyLen:             maxKeyLen,
 		maxValueLen:           maxInt(maxValueLen, opts.MaxValueLen),
 		maxLinearProofLen:     opts.MaxLinearProofLen,
+		maxIncrementRetries:   opts.MaxIncrementRetries,
+
+		overwriteOnRename: opts.OverwriteOnRename,
 
 		maxTxSize: maxTxSize,
 
@@ -465,8 +644,11 @@ func OpenWith(path string, vLogs []appendable.Appendable, txLog, cLog appendable
 		aht:      aht,
 		blBuffer: blBuffer,
 
+		tags: tags,
+
 		precommitWHub: watchers.New(0, 1),                                            // syncer (TODO: indexer may wait here instead)
 		commitWHub:    watchers.New(0, 1+opts.MaxActiveTransactions+opts.MaxWaitees), // including indexer
+		observersWHub: watchers.New(0, math.MaxInt32),
 
 		txPool: txPool,
 		_kvs:   kvs,
@@ -500,11 +682,19 @@ func OpenWith(path string, vLogs []appendable.Appendable, txLog, cLog appendable
 
 	err = store.precommitWHub.DoneUpto(committedTxID)
 	if err != nil {
+		store.Close()
 		return nil, err
 	}
 
 	err = store.commitWHub.DoneUpto(committedTxID)
 	if err != nil {
+		store.Close()
+		return nil, err
+	}
+
+	err = store.observersWHub.DoneUpto(committedTxID)
+	if err != nil {
+		store.Close()
 		return nil, err
 	}
 
@@ -543,7 +733,7 @@ func OpenWith(path string, vLogs []appendable.Appendable, txLog, cLog appendable
 
 	indexPath := filepath.Join(store.path, indexDirname)
 
-	store.indexer, err = newIndexer(indexPath, store, indexOpts, opts.MaxWaitees)
+	store.indexer, err = newIndexer(indexPath, store, indexOpts, opts.MaxWaitees, opts.IndexOpts.FlushTxThld)
 	if err != nil {
 		store.Close()
 		return nil, fmt.Errorf("could not open indexer: %w", err)
@@ -559,6 +749,7 @@ func OpenWith(path string, vLogs []appendable.Appendable, txLog, cLog appendable
 
 	if store.synced {
 		store.cLogBuf = make([]byte, cLogEntrySize*opts.MaxActiveTransactions)
+		store.cLogAlhBuf = make([][sha256.Size]byte, opts.MaxActiveTransactions)
 
 		go func() {
 			for {
@@ -591,7 +782,7 @@ func OpenWith(path string, vLogs []appendable.Appendable, txLog, cLog appendable
 					return
 				}
 				if err != nil {
-					store.notify(Error, true, "%w: while syncing transactions", err)
+					store.reportError(fmt.Errorf("%w: while syncing transactions at '%s'", err, store.path))
 				}
 			}
 		}()
@@ -632,10 +823,60 @@ func (s *ImmuStore) notify(nType NotificationType, mandatory bool, formattedMess
 	}
 }
 
+// SetOnErrorHook sets the function to be called whenever a non-fatal error occurs during background processing
+// (e.g. indexing, binary linking or syncing). By default such errors are logged.
+// Setting a nil hook restores the default behaviour.
+func (s *ImmuStore) SetOnErrorHook(fn func(err error)) {
+	s.onErrorHookMutex.Lock()
+	defer s.onErrorHookMutex.Unlock()
+
+	s.onErrorHook = fn
+}
+
+func (s *ImmuStore) reportError(err error) {
+	s.onErrorHookMutex.RLock()
+	onErrorHook := s.onErrorHook
+	s.onErrorHookMutex.RUnlock()
+
+	if onErrorHook == nil {
+		s.logger.Errorf("%v", err)
+		return
+	}
+
+	onErrorHook(err)
+}
+
 func (s *ImmuStore) IndexInfo() uint64 {
 	return s.indexer.Ts()
 }
 
+// IndexedUpToTxID returns the id of the last transaction fully processed by the indexer,
+// i.e. IndexedUpToTxID() == TransactionCount() once every committed transaction is indexed.
+// Unlike IndexInfo, it's read atomically without contending with the indexer
+func (s *ImmuStore) IndexedUpToTxID() uint64 {
+	return s.indexer.IndexedUpToTxID()
+}
+
+// WarmUpIndex pre-loads up to fraction * cacheSize index nodes into memory.
+// It's meant to be called after the store is opened and before serving requests
+func (s *ImmuStore) WarmUpIndex(fraction float64) error {
+	if fraction == 0 {
+		return nil
+	}
+
+	s.logger.Infof("Warming up index at '%s' {fraction=%.2f}...", s.path, fraction)
+
+	loaded, err := s.indexer.WarmUp(fraction)
+	if err != nil {
+		s.logger.Warningf("%v: while warming up index at '%s'", err, s.path)
+		return err
+	}
+
+	s.logger.Infof("Index at '%s' successfully warmed up {loaded_nodes=%d}", s.path, loaded)
+
+	return nil
+}
+
 func (s *ImmuStore) ExistKeyWith(prefix []byte, neq []byte) (bool, error) {
 	return s.indexer.ExistKeyWith(prefix, neq)
 }
@@ -644,6 +885,22 @@ func (s *ImmuStore) Get(key []byte) (valRef ValueRef, err error) {
 	return s.GetWith(key, IgnoreExpired, IgnoreDeleted)
 }
 
+// GetWithVersion returns the current value of key together with the id of the transaction in which it was set,
+// as required to perform optimistic concurrency control (see CAS)
+func (s *ImmuStore) GetWithVersion(key []byte) (value []byte, txID uint64, err error) {
+	valRef, err := s.Get(key)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	value, err = valRef.Resolve()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return value, valRef.Tx(), nil
+}
+
 func (s *ImmuStore) GetWith(key []byte, filters ...FilterFn) (valRef ValueRef, err error) {
 	indexedVal, tx, hc, err := s.indexer.Get(key)
 	if err != nil {
@@ -671,10 +928,432 @@ func (s *ImmuStore) GetWith(key []byte, filters ...FilterFn) (valRef ValueRef, e
 	return valRef, nil
 }
 
+// KVResult holds the entry of a key as it was at a given transaction
+type KVResult struct {
+	Key      []byte
+	Value    []byte
+	Metadata *KVMetadata
+	TxID     uint64
+}
+
+// GetManyWithMVCC resolves every key as it was right after transaction atTxID was committed.
+// Results are positionally aligned with keys, nil is set for keys not present, deleted or expired at atTxID
+func (s *ImmuStore) GetManyWithMVCC(keys [][]byte, atTxID uint64) ([]*KVResult, error) {
+	if len(keys) == 0 || atTxID == 0 {
+		return nil, ErrIllegalArguments
+	}
+
+	for _, key := range keys {
+		if len(key) == 0 {
+			return nil, ErrNullKey
+		}
+	}
+
+	if atTxID > s.lastCommittedTxID() {
+		return nil, ErrTxNotFound
+	}
+
+	err := s.WaitForIndexingUpto(atTxID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	hdr, err := s.ReadTxHeader(atTxID)
+	if err != nil {
+		return nil, err
+	}
+
+	// expiration is evaluated as of the commit time of atTxID
+	atTs := time.Unix(hdr.Ts, 0)
+
+	snap, err := s.SnapshotSince(atTxID)
+	if err != nil {
+		return nil, err
+	}
+	defer snap.Close()
+
+	results := make([]*KVResult, len(keys))
+
+	for i, key := range keys {
+		txID, err := lastTxUpto(snap, key, atTxID)
+		if err == ErrKeyNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		e, _, err := s.ReadTxEntry(txID, key)
+		if err != nil {
+			return nil, err
+		}
+
+		if e.md != nil && (e.md.Deleted() || e.md.ExpiredAt(atTs)) {
+			continue
+		}
+
+		val := make([]byte, e.vLen)
+
+		_, err = s.readValueAt(val, e.vOff, e.hVal)
+		if err != nil {
+			return nil, err
+		}
+
+		k := make([]byte, len(key))
+		copy(k, key)
+
+		results[i] = &KVResult{
+			Key:      k,
+			Value:    val,
+			Metadata: e.md,
+			TxID:     txID,
+		}
+	}
+
+	return results, nil
+}
+
+// lastTxUpto returns the id of the latest transaction up to atTxID in which key was written
+func lastTxUpto(snap *Snapshot, key []byte, atTxID uint64) (uint64, error) {
+	const pageSize = 64
+
+	for offset := uint64(0); ; offset += pageSize {
+		txs, _, err := snap.History(key, offset, true, pageSize)
+		if err == ErrOffsetOutOfRange {
+			return 0, ErrKeyNotFound
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		for _, txID := range txs {
+			if txID <= atTxID {
+				return txID, nil
+			}
+		}
+
+		if len(txs) < pageSize {
+			return 0, ErrKeyNotFound
+		}
+	}
+}
+
+// CloneKey copies the current value of srcKey into dstKey within a single transaction
+// ErrKeyNotFound is returned if srcKey does not exist, it was deleted or it's expired
+func (s *ImmuStore) CloneKey(srcKey, dstKey []byte) (uint64, error) {
+	tx, err := s.NewTx()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Cancel()
+
+	valRef, err := tx.Get(srcKey)
+	if err != nil {
+		return 0, err
+	}
+
+	val, err := valRef.Resolve()
+	if err != nil {
+		return 0, err
+	}
+
+	err = tx.Set(dstKey, nil, val)
+	if err != nil {
+		return 0, err
+	}
+
+	hdr, err := tx.Commit()
+	if err != nil {
+		return 0, err
+	}
+
+	return hdr.ID, nil
+}
+
+// AtomicIncrement adds delta to the 8-byte big-endian counter stored at key and returns the updated value.
+// An absent key is considered to hold a zero value. Conflicting updates are retried up to
+// MaxIncrementRetries times before returning ErrConflict
+func (s *ImmuStore) AtomicIncrement(key []byte, delta int64) (int64, error) {
+	return s.atomicIncrement(key, delta, false)
+}
+
+func (s *ImmuStore) atomicIncrement(key []byte, delta int64, reservedKeysAllowed bool) (int64, error) {
+	if len(key) == 0 {
+		return 0, ErrNullKey
+	}
+
+	for i := 0; i <= s.maxIncrementRetries; i++ {
+		v, err := s.increment(key, delta, reservedKeysAllowed)
+		if errors.Is(err, ErrTxReadConflict) {
+			continue
+		}
+
+		return v, err
+	}
+
+	return 0, ErrConflict
+}
+
+func (s *ImmuStore) increment(key []byte, delta int64, reservedKeysAllowed bool) (int64, error) {
+	tx, err := s.NewTx()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Cancel()
+
+	tx.reservedKeysAllowed = reservedKeysAllowed
+
+	var v int64
+
+	valRef, err := tx.Get(key)
+	if err != nil && !errors.Is(err, ErrKeyNotFound) {
+		return 0, err
+	}
+	if err == nil {
+		val, err := valRef.Resolve()
+		if err != nil {
+			return 0, err
+		}
+
+		if len(val) != 8 {
+			return 0, ErrInvalidCounter
+		}
+
+		v = int64(binary.BigEndian.Uint64(val))
+	}
+
+	v += delta
+
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], uint64(v))
+
+	err = tx.Set(key, nil, b[:])
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = tx.Commit()
+	if err != nil {
+		return 0, err
+	}
+
+	return v, nil
+}
+
+// CAS sets newValue on key only if its latest entry was written by the transaction expectedTxID,
+// an expectedTxID equal to zero means the key must have never been set.
+// ErrVersionConflict is returned otherwise. The id of the committed transaction is returned
+func (s *ImmuStore) CAS(key []byte, expectedTxID uint64, newValue []byte) (uint64, error) {
+	if len(key) == 0 {
+		return 0, ErrNullKey
+	}
+
+	err := s.WaitForIndexingUpto(s.lastPreCommittedTxID(), nil)
+	if err != nil {
+		return 0, err
+	}
+
+	var currTxID uint64
+
+	// latest entry, it could be deleted or expired
+	valRef, err := s.GetWith(key)
+	if err != nil && !errors.Is(err, ErrKeyNotFound) {
+		return 0, err
+	}
+	if err == nil {
+		currTxID = valRef.Tx()
+	}
+
+	if currTxID != expectedTxID {
+		return 0, ErrVersionConflict
+	}
+
+	tx, err := s.NewWriteOnlyTx()
+	if err != nil {
+		return 0, err
+	}
+
+	err = tx.Set(key, nil, newValue)
+	if err != nil {
+		return 0, err
+	}
+
+	if currTxID == 0 {
+		err = tx.AddPrecondition(&PreconditionKeyMustNotExist{Key: key})
+	} else {
+		err = tx.AddPrecondition(&PreconditionKeyNotModifiedAfterTx{Key: key, TxID: currTxID})
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	hdr, err := tx.Commit()
+	if errors.Is(err, ErrPreconditionFailed) {
+		return 0, ErrVersionConflict
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return hdr.ID, nil
+}
+
+// RenameKey moves the current value of oldKey to newKey in a single transaction that also deletes oldKey.
+// ErrKeyAlreadyExists is returned if newKey is already set, unless the store was opened with OverwriteOnRename.
+// Only the value is moved, metadata of the original entry is not carried over. The id of the committed transaction is returned
+func (s *ImmuStore) RenameKey(oldKey, newKey []byte) (uint64, error) {
+	if len(oldKey) == 0 || len(newKey) == 0 {
+		return 0, ErrNullKey
+	}
+
+	if bytes.Equal(oldKey, newKey) {
+		return 0, ErrIllegalArguments
+	}
+
+	tx, err := s.NewTx()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Cancel()
+
+	valRef, err := tx.Get(oldKey)
+	if err != nil {
+		return 0, err
+	}
+
+	val, err := valRef.Resolve()
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = tx.Get(newKey)
+	if err == nil && !s.overwriteOnRename {
+		return 0, ErrKeyAlreadyExists
+	}
+	if err != nil && !errors.Is(err, ErrKeyNotFound) {
+		return 0, err
+	}
+
+	err = tx.Set(newKey, nil, val)
+	if err != nil {
+		return 0, err
+	}
+
+	err = tx.Delete(oldKey)
+	if err != nil {
+		return 0, err
+	}
+
+	hdr, err := tx.Commit()
+	if err != nil {
+		return 0, err
+	}
+
+	return hdr.ID, nil
+}
+
+// MultiDelete commits a tombstone entry for each of the provided keys.
+// Keys are split into consecutive transactions holding at most maxTxEntries entries each,
+// the id of the last committed transaction is returned
+func (s *ImmuStore) MultiDelete(keys [][]byte) (uint64, error) {
+	if len(keys) == 0 {
+		return 0, ErrIllegalArguments
+	}
+
+	for _, key := range keys {
+		if len(key) == 0 {
+			return 0, ErrNullKey
+		}
+	}
+
+	md := NewKVMetadata()
+	md.AsDeleted(true)
+
+	var lastTxID uint64
+
+	for i := 0; i < len(keys); i += s.maxTxEntries {
+		end := i + s.maxTxEntries
+		if end > len(keys) {
+			end = len(keys)
+		}
+
+		tx, err := s.NewWriteOnlyTx()
+		if err != nil {
+			return lastTxID, err
+		}
+
+		for _, key := range keys[i:end] {
+			err = tx.Set(key, md, nil)
+			if err != nil {
+				tx.Cancel()
+				return lastTxID, err
+			}
+		}
+
+		hdr, err := tx.Commit()
+		if err != nil {
+			return lastTxID, err
+		}
+
+		lastTxID = hdr.ID
+	}
+
+	return lastTxID, nil
+}
+
 func (s *ImmuStore) History(key []byte, offset uint64, descOrder bool, limit int) (txs []uint64, hCount uint64, err error) {
 	return s.indexer.History(key, offset, descOrder, limit)
 }
 
+// GetFirstTxAfterKey returns the id of the first transaction newer than afterTxID where key was written,
+// deletions included. Committed transactions are indexed before the lookup, which binary searches
+// the indexed history of the key. ErrTxNotFound is returned when there is no such transaction
+func (s *ImmuStore) GetFirstTxAfterKey(key []byte, afterTxID uint64) (uint64, error) {
+	if len(key) == 0 {
+		return 0, ErrNullKey
+	}
+
+	err := s.WaitForIndexingUpto(s.TransactionCount(), nil)
+	if err != nil {
+		return 0, err
+	}
+
+	_, hCount, err := s.History(key, 0, false, 1)
+	if errors.Is(err, ErrKeyNotFound) {
+		return 0, ErrTxNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	// first history offset holding a transaction newer than afterTxID
+	left, right := uint64(0), hCount
+
+	var txID uint64
+
+	for left < right {
+		mid := left + (right-left)/2
+
+		txs, _, err := s.History(key, mid, false, 1)
+		if err != nil {
+			return 0, err
+		}
+
+		if txs[0] > afterTxID {
+			txID = txs[0]
+			right = mid
+		} else {
+			left = mid + 1
+		}
+	}
+
+	if txID == 0 {
+		return 0, ErrTxNotFound
+	}
+
+	return txID, nil
+}
+
 func (s *ImmuStore) UseTimeFunc(timeFunc TimeFunc) error {
 	if timeFunc == nil {
 		return ErrIllegalArguments
@@ -723,6 +1402,22 @@ func (s *ImmuStore) SnapshotSince(tx uint64) (*Snapshot, error) {
 	}, nil
 }
 
+// GetSnapshotAtIndex returns a snapshot of the index as it was persisted at the specified index ts.
+// The most recent persisted index state not newer than indexTs is used, thus the Ts() of the
+// returned snapshot may be lower than the requested one.
+func (s *ImmuStore) GetSnapshotAtIndex(indexTs uint64) (*Snapshot, error) {
+	snap, err := s.indexer.SnapshotAtTs(indexTs)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Snapshot{
+		st:   s,
+		snap: snap,
+		ts:   time.Now(),
+	}, nil
+}
+
 func (s *ImmuStore) binaryLinking() {
 	for {
 		select {
@@ -731,7 +1426,7 @@ func (s *ImmuStore) binaryLinking() {
 				_, _, err := s.aht.Append(alh[:])
 				if err != nil {
 					s.SetBlErr(err)
-					s.logger.Errorf("Binary linking at '%s' stopped due to error: %v", s.path, err)
+					s.reportError(fmt.Errorf("binary linking at '%s' stopped due to error: %w", s.path, err))
 					return
 				}
 			}
@@ -757,6 +1452,27 @@ func (s *ImmuStore) Alh() (uint64, [sha256.Size]byte) {
 	return s.committedTxID, s.committedAlh
 }
 
+// State is the latest committed transaction of the store, identified by its id and Alh
+type State struct {
+	TxID uint64
+	Alh  [sha256.Size]byte
+}
+
+// GetCurrentState returns the id and Alh of the latest committed transaction, both read atomically
+func (s *ImmuStore) GetCurrentState() (*State, error) {
+	s.mutex.Lock()
+	closed := s.closed
+	s.mutex.Unlock()
+
+	if closed {
+		return nil, ErrAlreadyClosed
+	}
+
+	txID, alh := s.Alh()
+
+	return &State{TxID: txID, Alh: alh}, nil
+}
+
 func (s *ImmuStore) BlInfo() (uint64, error) {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
@@ -799,7 +1515,7 @@ func (s *ImmuStore) syncBinaryLinking() error {
 		s.aht.Append(alh[:])
 
 		if tx.header.ID%1000 == 0 {
-			s.logger.Infof("Binary linking at '%s' in progress: processing tx: %d", s.path, tx.header.ID)
+			s.logger.Infof("Binary linking at '%s' in progress: processing %s", s.path, tx.Summary())
 		}
 	}
 
@@ -863,6 +1579,33 @@ func (s *ImmuStore) WaitForIndexingUpto(txID uint64, cancellation <-chan struct{
 	return s.indexer.WaitForIndexingUpto(txID, cancellation)
 }
 
+// EnsureIndex blocks until every transaction up to upToTxID is indexed and the index is synced to disk.
+// The error of the indexer is returned if indexing fails before reaching upToTxID
+func (s *ImmuStore) EnsureIndex(upToTxID uint64) error {
+	if upToTxID > s.lastCommittedTxID() {
+		return ErrTxNotFound
+	}
+
+	return s.indexer.EnsureIndexUpto(upToTxID)
+}
+
+// DetachIndexer gracefully stops background indexing, the transaction being indexed is completed before returning.
+// Snapshots remain available while detached but they do not include transactions committed afterwards,
+// thus waiting for those transactions to be indexed blocks until the indexer gets reattached
+func (s *ImmuStore) DetachIndexer() error {
+	return s.indexer.Detach()
+}
+
+// ReattachIndexer restarts background indexing from the last indexed transaction
+func (s *ImmuStore) ReattachIndexer() error {
+	return s.indexer.Reattach()
+}
+
+// WaitForIndexing blocks until every transaction committed at the time of the call has been indexed
+func (s *ImmuStore) WaitForIndexing() error {
+	return s.WaitForIndexingUpto(s.TransactionCount(), nil)
+}
+
 func (s *ImmuStore) CompactIndex() error {
 	if s.compactionDisabled {
 		return ErrCompactionUnsupported
@@ -874,6 +1617,12 @@ func (s *ImmuStore) FlushIndex(cleanupPercentage float32, synced bool) error {
 	return s.indexer.FlushIndex(cleanupPercentage, synced)
 }
 
+// SetIndexSyncInterval changes at runtime how often in-memory index changes are forced to be synced.
+// Setting d to zero triggers an immediate sync. Intervals below MinIndexSyncInterval are raised to it.
+func (s *ImmuStore) SetIndexSyncInterval(d time.Duration) error {
+	return s.indexer.SetSyncInterval(d)
+}
+
 func maxTxSize(maxTxEntries, maxKeyLen, maxTxMetadataLen, maxKVMetadataLen int) int {
 	return txIDSize /*txID*/ +
 		tsSize /*ts*/ +
@@ -931,13 +1680,20 @@ func (s *ImmuStore) MaxLinearProofLen() int {
 	return s.maxLinearProofLen
 }
 
-func (s *ImmuStore) TxCount() uint64 {
+// TransactionCount returns the number of committed transactions
+func (s *ImmuStore) TransactionCount() uint64 {
 	s.commitStateRWMutex.RLock()
 	defer s.commitStateRWMutex.RUnlock()
 
 	return s.committedTxID
 }
 
+// TxCount returns the number of committed transactions
+// Deprecated: TxCount will be removed in future release, use TransactionCount instead
+func (s *ImmuStore) TxCount() uint64 {
+	return s.TransactionCount()
+}
+
 func (s *ImmuStore) fetchAllocTx() (*Tx, error) {
 	tx, err := s.txPool.Alloc()
 	if errors.Is(err, ErrTxPoolExhausted) {
@@ -1017,13 +1773,43 @@ func (s *ImmuStore) appendData(entries []*EntrySpec, donec chan<- appendableResu
 		offsets[i] = encodeOffset(voff, vLogID)
 	}
 
-	err := vLog.Flush()
-	if err != nil {
-		donec <- appendableResult{nil, err}
-		return
+	err := vLog.Flush()
+	if err != nil {
+		donec <- appendableResult{nil, err}
+		return
+	}
+
+	donec <- appendableResult{offsets, nil}
+}
+
+// FaultInjectAppendable replaces the value log at position vlogIndex with fa while the store is running.
+// The replaced appendable is not closed so it can be wrapped by fa, meant to be used for fault injection in tests
+func (s *ImmuStore) FaultInjectAppendable(vlogIndex int, fa appendable.Appendable) error {
+	if fa == nil || vlogIndex < 0 || vlogIndex >= len(s.vLogs) {
+		return ErrIllegalArguments
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.closed {
+		return ErrAlreadyClosed
+	}
+
+	vLogID := byte(vlogIndex + 1)
+
+	s.fetchVLog(vLogID)
+
+	if s.vLogCache != nil {
+		// cached pages may not match the content of the new value log
+		evictVLogPages(s.vLogCache, vLogID, 0, math.MaxInt64)
+		fa = &cachedVLog{Appendable: fa, vLogID: vLogID, cache: s.vLogCache}
 	}
 
-	donec <- appendableResult{offsets, nil}
+	s.vLogs[vLogID-1].vLog = fa
+	s.releaseVLog(vLogID)
+
+	return nil
 }
 
 func (s *ImmuStore) NewWriteOnlyTx() (*OngoingTx, error) {
@@ -1035,6 +1821,8 @@ func (s *ImmuStore) NewTx() (*OngoingTx, error) {
 }
 
 func (s *ImmuStore) commit(otx *OngoingTx, expectedHeader *TxHeader, waitForIndexing bool) (*TxHeader, error) {
+	start := time.Now()
+
 	hdr, err := s.precommit(otx, expectedHeader, waitForIndexing)
 	if err != nil {
 		return nil, err
@@ -1056,6 +1844,8 @@ func (s *ImmuStore) commit(otx *OngoingTx, expectedHeader *TxHeader, waitForInde
 		}
 	}
 
+	s.commitLatency.observe(time.Since(start))
+
 	return hdr, nil
 }
 
@@ -1118,6 +1908,9 @@ func (s *ImmuStore) precommit(otx *OngoingTx, expectedHeader *TxHeader, waitForI
 
 	if expectedHeader == nil {
 		ts = s.timeFunc().Unix()
+		if otx.fixedTs > 0 {
+			ts = otx.fixedTs
+		}
 		blTxID = s.aht.Size()
 		version = s.writeTxHeaderVersion
 	} else {
@@ -1410,6 +2203,7 @@ func (s *ImmuStore) performPreCommit(tx *Tx, ts int64, blTxID uint64) error {
 
 	if s.synced {
 		copy(s.cLogBuf[int(s.preCommittedTxID-s.committedTxID-1)*cLogEntrySize:], cb[:])
+		s.cLogAlhBuf[int(s.preCommittedTxID-s.committedTxID-1)] = alh
 	} else {
 		// will overwrite partially written and uncommitted data
 		err = s.cLog.SetOffset(int64(s.committedTxID * cLogEntrySize))
@@ -1431,7 +2225,10 @@ func (s *ImmuStore) performPreCommit(tx *Tx, ts int64, blTxID uint64) error {
 		s.committedAlh = s.preCommittedAlh
 		s.committedTxLogSize = s.preCommittedTxLogSize
 
+		s.runPostCommitHooks(s.committedTxID, alh)
+
 		s.commitWHub.DoneUpto(s.committedTxID)
+		s.observersWHub.DoneUpto(s.committedTxID)
 	}
 
 	return nil
@@ -1512,6 +2309,12 @@ func (s *ImmuStore) preCommitWith(callback func(txID uint64, index KeyIndex) ([]
 		return nil, err
 	}
 
+	for _, e := range otx.entries {
+		if isReservedKey(e.Key) {
+			return nil, ErrReservedKey
+		}
+	}
+
 	err = s.validatePreconditions(otx.preconditions)
 	if err != nil {
 		return nil, err
@@ -1589,6 +2392,19 @@ type DualProof struct {
 	LinearProof        *LinearProof
 }
 
+// SizeBytes returns the amount of bytes required to hold the hashes and headers included in the proof
+func (p *DualProof) SizeBytes() int {
+	if p == nil {
+		return 0
+	}
+
+	size := p.SourceTxHeader.sizeBytes() + p.TargetTxHeader.sizeBytes()
+
+	hashCount := len(p.InclusionProof) + len(p.ConsistencyProof) + len(p.LastInclusionProof) + 1 // TargetBlTxAlh
+
+	return size + hashCount*sha256.Size + p.LinearProof.SizeBytes()
+}
+
 // DualProof combines linear cryptographic linking i.e. transactions include the linear accumulative hash up to the previous one,
 // with binary cryptographic linking generated by appending the linear accumulative hash values into an incremental hash tree, whose
 // root is also included as part of each transaction and thus considered when calculating the linear accumulative hash.
@@ -1655,12 +2471,67 @@ func (s *ImmuStore) DualProof(sourceTxHdr, targetTxHdr *TxHeader) (proof *DualPr
 	return
 }
 
+// TagTx associates a unique human-readable tag to an already committed transaction
+// ErrTagExists is returned if the tag was already assigned
+func (s *ImmuStore) TagTx(txID uint64, tag string) error {
+	if len(tag) == 0 || len(tag) > MaxTagLen {
+		return ErrIllegalArguments
+	}
+
+	if s.IsClosed() {
+		return ErrAlreadyClosed
+	}
+
+	if txID == 0 || txID > s.lastCommittedTxID() {
+		return ErrTxNotFound
+	}
+
+	return s.wrapAppendableErr(s.tags.put(tag, txID), "tagging tx")
+}
+
+// ResolveTx returns the id of the transaction associated to the given tag
+func (s *ImmuStore) ResolveTx(tag string) (uint64, error) {
+	if s.IsClosed() {
+		return 0, ErrAlreadyClosed
+	}
+
+	return s.tags.get(tag)
+}
+
+// BlInclusionProof returns the proof of inclusion of Alh@txID into the binary linking tree of size targetBlTxID
+func (s *ImmuStore) BlInclusionProof(txID, targetBlTxID uint64) ([][sha256.Size]byte, error) {
+	if txID == 0 || txID > targetBlTxID {
+		return nil, ErrIllegalArguments
+	}
+
+	return s.aht.InclusionProof(txID, targetBlTxID)
+}
+
 type LinearProof struct {
 	SourceTxID uint64
 	TargetTxID uint64
 	Terms      [][sha256.Size]byte
 }
 
+// SizeBytes returns the amount of bytes required to hold the tx ids and terms included in the proof
+func (p *LinearProof) SizeBytes() int {
+	if p == nil {
+		return 0
+	}
+
+	return 2*txIDSize + len(p.Terms)*sha256.Size
+}
+
+// EstimateLinearProofSize returns the number of bytes taken by the terms of the linear proof
+// between fromTxID and toTxID without building it, zero is returned for an invalid range
+func (s *ImmuStore) EstimateLinearProofSize(fromTxID, toTxID uint64) int {
+	if fromTxID == 0 || fromTxID > toTxID {
+		return 0
+	}
+
+	return int(toTxID-fromTxID+1) * sha256.Size
+}
+
 // LinearProof returns a list of hashes to calculate Alh@targetTxID from Alh@sourceTxID
 func (s *ImmuStore) LinearProof(sourceTxID, targetTxID uint64) (*LinearProof, error) {
 	if sourceTxID == 0 || sourceTxID > targetTxID {
@@ -1706,6 +2577,60 @@ func (s *ImmuStore) LinearProof(sourceTxID, targetTxID uint64) (*LinearProof, er
 	}, nil
 }
 
+// LinearProofBetween builds the linear proof between the transactions whose Alh are fromAlh and toAlh.
+// Accumulated hashes are not ordered, so committed transactions are sequentially scanned
+// to resolve their ids. ErrHashNotFound is returned if any of them does not match a committed transaction
+func (s *ImmuStore) LinearProofBetween(fromAlh, toAlh [sha256.Size]byte) (*LinearProof, error) {
+	fromTxID, toTxID, err := s.txIDsByAlh(fromAlh, toAlh)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.LinearProof(fromTxID, toTxID)
+}
+
+func (s *ImmuStore) txIDsByAlh(fromAlh, toAlh [sha256.Size]byte) (fromTxID, toTxID uint64, err error) {
+	txCount := s.TransactionCount()
+
+	if txCount == 0 {
+		return 0, 0, ErrHashNotFound
+	}
+
+	tx, err := s.fetchAllocTx()
+	if err != nil {
+		return 0, 0, err
+	}
+	defer s.releaseAllocTx(tx)
+
+	r, err := s.NewTxReader(1, false, tx)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	for txID := uint64(1); txID <= txCount && (fromTxID == 0 || toTxID == 0); txID++ {
+		tx, err := r.Read()
+		if err != nil {
+			return 0, 0, err
+		}
+
+		alh := tx.header.Alh()
+
+		if fromTxID == 0 && alh == fromAlh {
+			fromTxID = txID
+		}
+
+		if toTxID == 0 && alh == toAlh {
+			toTxID = txID
+		}
+	}
+
+	if fromTxID == 0 || toTxID == 0 {
+		return 0, 0, ErrHashNotFound
+	}
+
+	return fromTxID, toTxID, nil
+}
+
 func (s *ImmuStore) txOffsetAndSize(txID uint64) (int64, int, error) {
 	if txID == 0 {
 		return 0, 0, ErrIllegalArguments
@@ -1715,7 +2640,7 @@ func (s *ImmuStore) txOffsetAndSize(txID uint64) (int64, int, error) {
 
 	var cb [cLogEntrySize]byte
 
-	_, err := s.cLog.ReadAt(cb[:], int64(off))
+	_, err := s.withReadDeadline(s.cLog).ReadAt(cb[:], int64(off))
 	if err == multiapp.ErrAlreadyClosed || err == singleapp.ErrAlreadyClosed {
 		return 0, 0, ErrAlreadyClosed
 	}
@@ -1871,6 +2796,9 @@ func (s *ImmuStore) ReplicateTx(exportedTx []byte, waitForIndexing bool) (*TxHea
 		return nil, err
 	}
 
+	// replicated entries may have been written by leases or sequences of the primary
+	txSpec.reservedKeysAllowed = true
+
 	txSpec.metadata = hdr.Metadata
 
 	for e := 0; e < hdr.NEntries; e++ {
@@ -1926,6 +2854,133 @@ func (s *ImmuStore) ReplicateTx(exportedTx []byte, waitForIndexing bool) (*TxHea
 	return s.commit(txSpec, hdr, waitForIndexing)
 }
 
+// MigrateFrom replays the transactions of src starting from fromTxID into this store.
+// Original timestamps are preserved unless older than the last committed transaction, in which case
+// the timestamp of the latter is used so timestamps never go backwards. New sequential ids are assigned,
+// the mapping between source and target ids is appended to the file txID_mapping.csv inside the store folder
+func (s *ImmuStore) MigrateFrom(src *ImmuStore, fromTxID uint64) (err error) {
+	if src == nil || src == s || fromTxID == 0 {
+		return ErrIllegalArguments
+	}
+
+	if s.IsClosed() {
+		return ErrAlreadyClosed
+	}
+
+	mappingPath := filepath.Join(s.path, migrationMappingFilename)
+
+	_, err = os.Stat(mappingPath)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	newMapping := os.IsNotExist(err)
+
+	mappingFile, err := os.OpenFile(mappingPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, s.fileMode)
+	if err != nil {
+		return err
+	}
+	defer mappingFile.Close()
+
+	mapping := csv.NewWriter(mappingFile)
+
+	// rows of the already committed transactions are kept even if the migration fails
+	defer func() {
+		mapping.Flush()
+
+		flushErr := mapping.Error()
+		if flushErr == nil && s.synced {
+			flushErr = mappingFile.Sync()
+		}
+
+		if err == nil {
+			err = flushErr
+		}
+	}()
+
+	if newMapping {
+		err = mapping.Write([]string{"src_tx_id", "dst_tx_id"})
+		if err != nil {
+			return err
+		}
+	}
+
+	tx, err := src.fetchAllocTx()
+	if err != nil {
+		return err
+	}
+	defer src.releaseAllocTx(tx)
+
+	txr, err := src.NewTxReader(fromTxID, false, tx)
+	if err != nil {
+		return err
+	}
+
+	var lastTs int64
+
+	if lastTxID := s.lastCommittedTxID(); lastTxID > 0 {
+		hdr, err := s.ReadTxHeader(lastTxID)
+		if err != nil {
+			return err
+		}
+
+		lastTs = hdr.Ts
+	}
+
+	for {
+		srcTx, err := txr.Read()
+		if err == ErrNoMoreEntries {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		otx, err := s.NewWriteOnlyTx()
+		if err != nil {
+			return err
+		}
+
+		otx.metadata = srcTx.header.Metadata
+		otx.reservedKeysAllowed = true
+		// lookups by time rely on timestamps not going backwards
+		otx.fixedTs = srcTx.header.Ts
+		if otx.fixedTs < lastTs {
+			otx.fixedTs = lastTs
+		}
+
+		for _, e := range srcTx.Entries() {
+			val := make([]byte, e.vLen)
+
+			_, err = src.readValueAt(val, e.vOff, e.hVal)
+			if err != nil {
+				return err
+			}
+
+			err = otx.Set(e.Key(), e.md, val)
+			if err != nil {
+				return err
+			}
+		}
+
+		hdr, err := otx.AsyncCommit()
+		if err != nil {
+			return err
+		}
+
+		lastTs = hdr.Ts
+
+		err = mapping.Write([]string{
+			strconv.FormatUint(srcTx.header.ID, 10),
+			strconv.FormatUint(hdr.ID, 10),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (s *ImmuStore) FirstTxSince(ts time.Time) (*TxHeader, error) {
 	left := uint64(1)
 	right := s.lastCommittedTxID()
@@ -1988,6 +3043,42 @@ func (s *ImmuStore) LastTxUntil(ts time.Time) (*TxHeader, error) {
 	return header, nil
 }
 
+// TransactionsByTimeRange returns, in ascending order, the ids of the transactions whose timestamp
+// falls within [from, to] at second precision. Bounds are located with binary searches (see FirstTxSince and LastTxUntil)
+func (s *ImmuStore) TransactionsByTimeRange(from, to time.Time) ([]uint64, error) {
+	if from.After(to) {
+		return nil, ErrIllegalArguments
+	}
+
+	first, err := s.FirstTxSince(from)
+	if err == ErrTxNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	last, err := s.LastTxUntil(to)
+	if err == ErrTxNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if first.ID > last.ID {
+		return nil, nil
+	}
+
+	txIDs := make([]uint64, 0, last.ID-first.ID+1)
+
+	for txID := first.ID; txID <= last.ID; txID++ {
+		txIDs = append(txIDs, txID)
+	}
+
+	return txIDs, nil
+}
+
 func (s *ImmuStore) appendableReaderForTx(txID uint64) (*appendable.Reader, error) {
 	cacheMiss := false
 
@@ -2007,7 +3098,7 @@ func (s *ImmuStore) appendableReaderForTx(txID uint64) (*appendable.Reader, erro
 	var txr io.ReaderAt
 
 	if cacheMiss {
-		txr = s.txLog
+		txr = s.withReadDeadline(s.txLog)
 	} else {
 		txr = &slicedReaderAt{bs: txbs.([]byte), off: txOff}
 	}
@@ -2029,6 +3120,102 @@ func (s *ImmuStore) ReadTx(txID uint64, tx *Tx) error {
 	return err
 }
 
+// BatchReadTx reads the transactions txIDs into txs, which must hold a transaction per id.
+// Transactions stored contiguously in the tx log are fetched issuing a single read
+func (s *ImmuStore) BatchReadTx(txIDs []uint64, txs []*Tx) error {
+	if len(txIDs) != len(txs) {
+		return ErrIllegalArguments
+	}
+
+	type txLocation struct {
+		i    int
+		off  int64
+		size int
+	}
+
+	locations := make([]*txLocation, len(txIDs))
+
+	for i, txID := range txIDs {
+		if txs[i] == nil {
+			return ErrIllegalArguments
+		}
+
+		off, size, err := s.txOffsetAndSize(txID)
+		if err != nil {
+			return err
+		}
+
+		locations[i] = &txLocation{i: i, off: off, size: size}
+	}
+
+	sort.Slice(locations, func(i, j int) bool {
+		return locations[i].off < locations[j].off
+	})
+
+	txLog := s.withReadDeadline(s.txLog)
+
+	for start := 0; start < len(locations); {
+		rangeOff := locations[start].off
+		rangeEnd := rangeOff + int64(locations[start].size)
+
+		end := start + 1
+
+		// repeated ids share the same location
+		for ; end < len(locations) && locations[end].off <= rangeEnd; end++ {
+			locEnd := locations[end].off + int64(locations[end].size)
+			if locEnd > rangeEnd {
+				rangeEnd = locEnd
+			}
+		}
+
+		bs := make([]byte, rangeEnd-rangeOff)
+
+		_, err := txLog.ReadAt(bs, rangeOff)
+		if err == multiapp.ErrAlreadyClosed || err == singleapp.ErrAlreadyClosed {
+			return ErrAlreadyClosed
+		}
+		if err != nil {
+			return err
+		}
+
+		for _, loc := range locations[start:end] {
+			o := loc.off - rangeOff
+
+			r := appendable.NewReaderFrom(&slicedReaderAt{bs: bs[o : o+int64(loc.size)], off: loc.off}, loc.off, loc.size)
+
+			err = txs[loc.i].readFrom(r)
+			if err == io.EOF {
+				return fmt.Errorf("%w: unexpected EOF while reading tx %d", ErrorCorruptedTxData, txIDs[loc.i])
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		start = end
+	}
+
+	return nil
+}
+
+// TxEntriesCount returns the number of entries of the transaction txID.
+// Only the header of the transaction is read, entries are neither read nor validated
+func (s *ImmuStore) TxEntriesCount(txID uint64) (int, error) {
+	r, err := s.appendableReaderForTx(txID)
+	if err != nil {
+		return 0, err
+	}
+
+	tdr := &txDataReader{r: r}
+
+	header, err := tdr.readHeader(s.maxTxEntries)
+	if err != nil {
+		return 0, err
+	}
+
+	return header.NEntries, nil
+}
+
 func (s *ImmuStore) ReadTxHeader(txID uint64) (*TxHeader, error) {
 	r, err := s.appendableReaderForTx(txID)
 	if err != nil {
@@ -2147,7 +3334,7 @@ func (s *ImmuStore) readValueAt(b []byte, off int64, hvalue [sha256.Size]byte) (
 		vLog := s.fetchVLog(vLogID)
 		defer s.releaseVLog(vLogID)
 
-		n, err := vLog.ReadAt(b, offset)
+		n, err := s.readVLogAt(vLogID, vLog, b, offset)
 		if err == multiapp.ErrAlreadyClosed || err == singleapp.ErrAlreadyClosed {
 			return n, ErrAlreadyClosed
 		}
@@ -2173,25 +3360,29 @@ func (s *ImmuStore) validateEntries(entries []*EntrySpec) error {
 
 	m := make(map[string]struct{}, len(entries))
 
-	for _, kv := range entries {
-		if kv.Key == nil {
-			return ErrNullKey
-		}
+	// all the invalid entries are reported at once
+	merr := multierr.NewMultiErr()
 
-		if len(kv.Key) > s.maxKeyLen {
-			return ErrorMaxKeyLenExceeded
-		}
-		if len(kv.Value) > s.maxValueLen {
-			return ErrorMaxValueLenExceeded
+	for _, kv := range entries {
+		err := kv.validate(s.maxKeyLen, s.maxValueLen)
+		if err != nil {
+			merr.Append(err)
+			continue
 		}
 
 		b64k := base64.StdEncoding.EncodeToString(kv.Key)
 		if _, ok := m[b64k]; ok {
-			return ErrDuplicatedKey
+			merr.Append(ErrDuplicatedKey)
+			continue
 		}
 		m[b64k] = struct{}{}
 	}
-	return nil
+
+	if len(merr.Errors()) == 1 {
+		return merr.Errors()[0]
+	}
+
+	return merr.Reduce()
 }
 
 func (s *ImmuStore) validatePreconditions(preconditions []Precondition) error {
@@ -2269,11 +3460,18 @@ func (s *ImmuStore) sync() error {
 		return err
 	}
 
+	fromTxID := s.committedTxID + 1
+
 	s.committedTxID = s.preCommittedTxID
 	s.committedAlh = s.preCommittedAlh
 	s.committedTxLogSize = s.preCommittedTxLogSize
 
+	for txID := fromTxID; txID <= s.committedTxID; txID++ {
+		s.runPostCommitHooks(txID, s.cLogAlhBuf[txID-fromTxID])
+	}
+
 	s.commitWHub.DoneUpto(s.committedTxID)
+	s.observersWHub.DoneUpto(s.committedTxID)
 
 	return nil
 }
@@ -2319,6 +3517,9 @@ func (s *ImmuStore) Close() error {
 	err = s.commitWHub.Close()
 	merr.Append(err)
 
+	err = s.observersWHub.Close()
+	merr.Append(err)
+
 	if s.indexer != nil {
 		err = s.indexer.Close()
 		merr.Append(err)
@@ -2333,11 +3534,19 @@ func (s *ImmuStore) Close() error {
 	err = s.aht.Close()
 	merr.Append(err)
 
+	err = s.tags.Close()
+	merr.Append(err)
+
 	used, _, _ := s.txPool.Stats()
 	if used > 0 {
 		merr.Append(errors.New("not all tx holders were released"))
 	}
 
+	if s.flock != nil {
+		err = releaseFlock(s.flock)
+		merr.Append(err)
+	}
+
 	return merr.Reduce()
 }
 
@@ -27,6 +27,7 @@ import (
 	"path/filepath"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -687,6 +688,8 @@ func TestImmudbStoreEdgeCases(t *testing.T) {
 
 	_, err = immuStore.LinearProof(2, 1)
 	require.Equal(t, ErrSourceTxNewerThanTargetTx, err)
+	require.Zero(t, immuStore.EstimateLinearProofSize(2, 1))
+	require.Zero(t, immuStore.EstimateLinearProofSize(0, 1))
 
 	_, err = immuStore.LinearProof(1, uint64(1+immuStore.maxLinearProofLen))
 	require.Equal(t, ErrLinearProofMaxLenExceeded, err)
@@ -794,6 +797,76 @@ func TestImmudbSetBlErr(t *testing.T) {
 	require.Error(t, err)
 }
 
+type failingAppendable struct {
+	appendable.Appendable
+	failing *int32
+}
+
+func (a *failingAppendable) Append(bs []byte) (off int64, n int, err error) {
+	if atomic.LoadInt32(a.failing) == 1 {
+		return 0, 0, errors.New("injected error")
+	}
+
+	return a.Appendable.Append(bs)
+}
+
+func TestImmudbStoreOnErrorHook(t *testing.T) {
+	dir, err := ioutil.TempDir("", "data_on_error_hook")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	var failing int32
+
+	opts := DefaultOptions().WithAppFactory(func(rootPath, subPath string, opts *multiapp.Options) (appendable.Appendable, error) {
+		app, err := multiapp.Open(filepath.Join(rootPath, subPath), opts)
+		if err != nil {
+			return nil, err
+		}
+
+		if strings.HasPrefix(subPath, ahtDirname) {
+			return &failingAppendable{Appendable: app, failing: &failing}, nil
+		}
+
+		return app, nil
+	})
+
+	immuStore, err := Open(dir, opts)
+	require.NoError(t, err)
+
+	errs := make(chan error, 1)
+
+	immuStore.SetOnErrorHook(func(err error) {
+		errs <- err
+	})
+
+	atomic.StoreInt32(&failing, 1)
+
+	tx, err := immuStore.NewWriteOnlyTx()
+	require.NoError(t, err)
+
+	err = tx.Set([]byte("key"), nil, []byte("value"))
+	require.NoError(t, err)
+
+	_, err = tx.AsyncCommit()
+	require.NoError(t, err)
+
+	select {
+	case err := <-errs:
+		require.ErrorContains(t, err, "binary linking")
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "error hook not called")
+	}
+
+	immuStore.SetOnErrorHook(nil)
+
+	immuStore.reportError(errors.New("logged error"))
+
+	require.Empty(t, errs)
+
+	err = immuStore.Close()
+	require.NoError(t, err)
+}
+
 func TestImmudbTxOffsetAndSize(t *testing.T) {
 	opts := DefaultOptions().WithMaxConcurrency(1)
 	immuStore, err := Open("data_tx_off_sz", opts)
@@ -1257,7 +1330,7 @@ func TestImmudbStoreRWTransactions(t *testing.T) {
 		require.NoError(t, err)
 		require.NotNil(t, r)
 
-		_, _, _, err = r.ReadBetween(1, immuStore.TxCount())
+		_, _, _, err = r.ReadBetween(1, immuStore.TransactionCount())
 		require.ErrorIs(t, err, ErrNoMoreEntries)
 
 		err = r.Close()
@@ -2006,7 +2079,22 @@ func TestImmudbStoreConsistencyProofAgainstLatest(t *testing.T) {
 
 		verifies := VerifyDualProof(dproof, sourceTxID, targetTxID, sourceTx.header.Alh(), targetTx.header.Alh())
 		require.True(t, verifies)
+
+		sourceHdrBs, err := dproof.SourceTxHeader.Bytes()
+		require.NoError(t, err)
+
+		targetHdrBs, err := dproof.TargetTxHeader.Bytes()
+		require.NoError(t, err)
+
+		hashCount := len(dproof.InclusionProof) + len(dproof.ConsistencyProof) + len(dproof.LastInclusionProof) + 1
+		linearProofSize := 2*txIDSize + len(dproof.LinearProof.Terms)*sha256.Size
+
+		require.Equal(t, linearProofSize, dproof.LinearProof.SizeBytes())
+		require.Equal(t, len(sourceHdrBs)+len(targetHdrBs)+hashCount*sha256.Size+linearProofSize, dproof.SizeBytes())
 	}
+
+	require.Zero(t, (*DualProof)(nil).SizeBytes())
+	require.Zero(t, (*LinearProof)(nil).SizeBytes())
 }
 
 func TestImmudbStoreConsistencyProofReopened(t *testing.T) {
@@ -2093,6 +2181,7 @@ func TestImmudbStoreConsistencyProofReopened(t *testing.T) {
 
 			lproof, err := immuStore.LinearProof(sourceTxID, targetTxID)
 			require.NoError(t, err)
+			require.Equal(t, len(lproof.Terms)*sha256.Size, immuStore.EstimateLinearProofSize(sourceTxID, targetTxID))
 
 			verifies := VerifyLinearProof(lproof, sourceTxID, targetTxID, sourceTx.header.Alh(), targetTx.header.Alh())
 			require.True(t, verifies)
@@ -2308,7 +2397,8 @@ func TestUncommittedTxOverwriting(t *testing.T) {
 	_, err = r.Read()
 	require.Equal(t, ErrNoMoreEntries, err)
 
-	require.Equal(t, uint64(txCount-emulatedFailures), immuStore.TxCount())
+	require.Equal(t, uint64(txCount-emulatedFailures), immuStore.TransactionCount())
+	require.Equal(t, immuStore.TransactionCount(), immuStore.TxCount())
 
 	err = immuStore.Close()
 	require.NoError(t, err)
@@ -2949,3 +3039,1251 @@ func TestTimeBasedTxLookup(t *testing.T) {
 		}
 	}
 }
+
+func TestImmudbStoreFlock(t *testing.T) {
+	opts := DefaultOptions().WithUseFlock(true)
+
+	immuStore, err := Open("data_flock", opts)
+	require.NoError(t, err)
+	defer os.RemoveAll("data_flock")
+
+	_, err = Open("data_flock", opts)
+	require.ErrorIs(t, err, ErrStoreLocked)
+
+	_, err = TryOpen("data_flock", opts, 10*time.Millisecond)
+	require.ErrorIs(t, err, ErrStoreLocked)
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		immustoreClose(t, immuStore)
+	}()
+
+	immuStore, err = TryOpen("data_flock", opts, 5*time.Second)
+	require.NoError(t, err)
+
+	immustoreClose(t, immuStore)
+
+	immuStore, err = Open("data_flock", opts)
+	require.NoError(t, err)
+
+	immustoreClose(t, immuStore)
+}
+
+func TestImmudbStoreWarmUpIndex(t *testing.T) {
+	immuStore, err := Open("data_warmup", DefaultOptions())
+	require.NoError(t, err)
+	defer os.RemoveAll("data_warmup")
+
+	for i := 0; i < 10; i++ {
+		tx, err := immuStore.NewWriteOnlyTx()
+		require.NoError(t, err)
+
+		err = tx.Set([]byte(fmt.Sprintf("key%d", i)), nil, []byte(fmt.Sprintf("value%d", i)))
+		require.NoError(t, err)
+
+		_, err = tx.Commit()
+		require.NoError(t, err)
+	}
+
+	immustoreClose(t, immuStore)
+
+	immuStore, err = Open("data_warmup", DefaultOptions())
+	require.NoError(t, err)
+
+	require.NoError(t, immuStore.WarmUpIndex(0))
+	require.NoError(t, immuStore.WarmUpIndex(1))
+	require.ErrorIs(t, immuStore.WarmUpIndex(2), ErrIllegalArguments)
+
+	immustoreClose(t, immuStore)
+
+	require.ErrorIs(t, immuStore.WarmUpIndex(1), ErrAlreadyClosed)
+}
+
+func TestImmudbStoreCloneKey(t *testing.T) {
+	immuStore, err := Open("data_clone_key", DefaultOptions())
+	require.NoError(t, err)
+	defer os.RemoveAll("data_clone_key")
+
+	defer immustoreClose(t, immuStore)
+
+	_, err = immuStore.CloneKey([]byte("src"), []byte("dst"))
+	require.ErrorIs(t, err, ErrKeyNotFound)
+
+	tx, err := immuStore.NewWriteOnlyTx()
+	require.NoError(t, err)
+
+	err = tx.Set([]byte("src"), nil, []byte("value"))
+	require.NoError(t, err)
+
+	hdr, err := tx.Commit()
+	require.NoError(t, err)
+
+	txID, err := immuStore.CloneKey([]byte("src"), []byte("dst"))
+	require.NoError(t, err)
+	require.Equal(t, hdr.ID+1, txID)
+
+	valRef, err := immuStore.Get([]byte("dst"))
+	require.NoError(t, err)
+	require.Equal(t, txID, valRef.Tx())
+
+	val, err := valRef.Resolve()
+	require.NoError(t, err)
+	require.Equal(t, []byte("value"), val)
+
+	_, err = immuStore.CloneKey([]byte("src"), nil)
+	require.ErrorIs(t, err, ErrNullKey)
+}
+
+func TestImmudbStoreAtomicIncrement(t *testing.T) {
+	immuStore, err := Open("data_atomic_increment", DefaultOptions().WithMaxIncrementRetries(100))
+	require.NoError(t, err)
+	defer os.RemoveAll("data_atomic_increment")
+
+	defer immustoreClose(t, immuStore)
+
+	v, err := immuStore.AtomicIncrement([]byte("counter"), 5)
+	require.NoError(t, err)
+	require.EqualValues(t, 5, v)
+
+	v, err = immuStore.AtomicIncrement([]byte("counter"), -7)
+	require.NoError(t, err)
+	require.EqualValues(t, -2, v)
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 5; j++ {
+				_, err := immuStore.AtomicIncrement([]byte("counter"), 1)
+				require.NoError(t, err)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	valRef, err := immuStore.Get([]byte("counter"))
+	require.NoError(t, err)
+
+	val, err := valRef.Resolve()
+	require.NoError(t, err)
+	require.EqualValues(t, 18, int64(binary.BigEndian.Uint64(val)))
+
+	tx, err := immuStore.NewWriteOnlyTx()
+	require.NoError(t, err)
+
+	err = tx.Set([]byte("not-a-counter"), nil, []byte("value"))
+	require.NoError(t, err)
+
+	_, err = tx.Commit()
+	require.NoError(t, err)
+
+	_, err = immuStore.AtomicIncrement([]byte("not-a-counter"), 1)
+	require.ErrorIs(t, err, ErrInvalidCounter)
+
+	_, err = immuStore.AtomicIncrement(nil, 1)
+	require.ErrorIs(t, err, ErrNullKey)
+}
+
+func TestImmudbStoreTagTx(t *testing.T) {
+	dir, err := ioutil.TempDir("", "data_tags")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	immuStore, err := Open(dir, DefaultOptions())
+	require.NoError(t, err)
+
+	err = immuStore.TagTx(1, "checkpoint")
+	require.ErrorIs(t, err, ErrTxNotFound)
+
+	for i := 0; i < 3; i++ {
+		tx, err := immuStore.NewWriteOnlyTx()
+		require.NoError(t, err)
+
+		err = tx.Set([]byte(fmt.Sprintf("key%d", i)), nil, []byte("value"))
+		require.NoError(t, err)
+
+		_, err = tx.Commit()
+		require.NoError(t, err)
+	}
+
+	err = immuStore.TagTx(0, "checkpoint")
+	require.ErrorIs(t, err, ErrTxNotFound)
+
+	err = immuStore.TagTx(1, "")
+	require.ErrorIs(t, err, ErrIllegalArguments)
+
+	err = immuStore.TagTx(1, strings.Repeat("a", MaxTagLen+1))
+	require.ErrorIs(t, err, ErrIllegalArguments)
+
+	err = immuStore.TagTx(2, "migration-v2-complete")
+	require.NoError(t, err)
+
+	err = immuStore.TagTx(3, "migration-v2-complete")
+	require.ErrorIs(t, err, ErrTagExists)
+
+	err = immuStore.TagTx(3, "end-of-day")
+	require.NoError(t, err)
+
+	txID, err := immuStore.ResolveTx("migration-v2-complete")
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), txID)
+
+	_, err = immuStore.ResolveTx("unknown")
+	require.ErrorIs(t, err, ErrTagNotFound)
+
+	err = immuStore.Close()
+	require.NoError(t, err)
+
+	err = immuStore.TagTx(1, "closed")
+	require.ErrorIs(t, err, ErrAlreadyClosed)
+
+	_, err = immuStore.ResolveTx("end-of-day")
+	require.ErrorIs(t, err, ErrAlreadyClosed)
+
+	immuStore, err = Open(dir, DefaultOptions())
+	require.NoError(t, err)
+
+	txID, err = immuStore.ResolveTx("migration-v2-complete")
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), txID)
+
+	txID, err = immuStore.ResolveTx("end-of-day")
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), txID)
+
+	err = immuStore.TagTx(1, "end-of-day")
+	require.ErrorIs(t, err, ErrTagExists)
+
+	err = immuStore.Close()
+	require.NoError(t, err)
+
+	// stores created before tags were introduced can be opened read-only
+	err = os.RemoveAll(filepath.Join(dir, tagsDirname))
+	require.NoError(t, err)
+
+	immuStore, err = Open(dir, DefaultOptions().WithReadOnly(true))
+	require.NoError(t, err)
+
+	_, err = immuStore.ResolveTx("end-of-day")
+	require.ErrorIs(t, err, ErrTagNotFound)
+
+	err = immuStore.TagTx(1, "read-only")
+	require.ErrorIs(t, err, ErrReadOnly)
+
+	err = immuStore.Close()
+	require.NoError(t, err)
+}
+
+func TestImmudbStoreMigrateFrom(t *testing.T) {
+	srcDir, err := ioutil.TempDir("", "data_migrate_src")
+	require.NoError(t, err)
+	defer os.RemoveAll(srcDir)
+
+	dstDir, err := ioutil.TempDir("", "data_migrate_dst")
+	require.NoError(t, err)
+	defer os.RemoveAll(dstDir)
+
+	srcTs := int64(1000)
+
+	src, err := Open(srcDir, DefaultOptions().WithTimeFunc(func() time.Time {
+		srcTs++
+		return time.Unix(srcTs, 0)
+	}))
+	require.NoError(t, err)
+	defer immustoreClose(t, src)
+
+	// the pre-existing transaction is newer than the first migrated one
+	dst, err := Open(dstDir, DefaultOptions().WithTimeFunc(func() time.Time {
+		return time.Unix(1004, 0)
+	}))
+	require.NoError(t, err)
+	defer immustoreClose(t, dst)
+
+	err = dst.MigrateFrom(nil, 1)
+	require.ErrorIs(t, err, ErrIllegalArguments)
+
+	err = dst.MigrateFrom(dst, 1)
+	require.ErrorIs(t, err, ErrIllegalArguments)
+
+	err = dst.MigrateFrom(src, 0)
+	require.ErrorIs(t, err, ErrIllegalArguments)
+
+	txCount := 5
+
+	for i := 0; i < txCount; i++ {
+		tx, err := src.NewWriteOnlyTx()
+		require.NoError(t, err)
+
+		err = tx.Set([]byte(fmt.Sprintf("key%d", i)), nil, []byte(fmt.Sprintf("value%d", i)))
+		require.NoError(t, err)
+
+		_, err = tx.Commit()
+		require.NoError(t, err)
+	}
+
+	// pre-existing transaction in the target store
+	tx, err := dst.NewWriteOnlyTx()
+	require.NoError(t, err)
+
+	err = tx.Set([]byte("existing"), nil, []byte("value"))
+	require.NoError(t, err)
+
+	_, err = tx.Commit()
+	require.NoError(t, err)
+
+	err = dst.MigrateFrom(src, 3)
+	require.NoError(t, err)
+
+	require.Equal(t, uint64(4), dst.TransactionCount())
+
+	for srcTxID := uint64(3); srcTxID <= uint64(txCount); srcTxID++ {
+		srcHdr, err := src.ReadTxHeader(srcTxID)
+		require.NoError(t, err)
+
+		dstHdr, err := dst.ReadTxHeader(srcTxID - 1)
+		require.NoError(t, err)
+
+		if srcHdr.Ts < 1004 {
+			require.EqualValues(t, 1004, dstHdr.Ts)
+		} else {
+			require.Equal(t, srcHdr.Ts, dstHdr.Ts)
+		}
+	}
+
+	err = dst.WaitForIndexingUpto(4, nil)
+	require.NoError(t, err)
+
+	valRef, err := dst.Get([]byte("key4"))
+	require.NoError(t, err)
+	require.Equal(t, uint64(4), valRef.Tx())
+
+	val, err := valRef.Resolve()
+	require.NoError(t, err)
+	require.Equal(t, []byte("value4"), val)
+
+	_, err = dst.Get([]byte("key1"))
+	require.ErrorIs(t, err, ErrKeyNotFound)
+
+	mapping, err := ioutil.ReadFile(filepath.Join(dstDir, migrationMappingFilename))
+	require.NoError(t, err)
+	require.Equal(t, "src_tx_id,dst_tx_id\n3,2\n4,3\n5,4\n", string(mapping))
+
+	err = dst.MigrateFrom(src, 5)
+	require.NoError(t, err)
+
+	mapping, err = ioutil.ReadFile(filepath.Join(dstDir, migrationMappingFilename))
+	require.NoError(t, err)
+	require.Equal(t, "src_tx_id,dst_tx_id\n3,2\n4,3\n5,4\n5,5\n", string(mapping))
+}
+
+func TestImmudbStoreCAS(t *testing.T) {
+	dir, err := ioutil.TempDir("", "data_cas")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	immuStore, err := Open(dir, DefaultOptions())
+	require.NoError(t, err)
+	defer immustoreClose(t, immuStore)
+
+	_, err = immuStore.CAS(nil, 0, []byte("value"))
+	require.ErrorIs(t, err, ErrNullKey)
+
+	_, err = immuStore.CAS([]byte("key"), 1, []byte("value1"))
+	require.ErrorIs(t, err, ErrVersionConflict)
+
+	txID1, err := immuStore.CAS([]byte("key"), 0, []byte("value1"))
+	require.NoError(t, err)
+
+	_, err = immuStore.CAS([]byte("key"), 0, []byte("value2"))
+	require.ErrorIs(t, err, ErrVersionConflict)
+
+	txID2, err := immuStore.CAS([]byte("key"), txID1, []byte("value2"))
+	require.NoError(t, err)
+	require.Greater(t, txID2, txID1)
+
+	_, err = immuStore.CAS([]byte("key"), txID1, []byte("value3"))
+	require.ErrorIs(t, err, ErrVersionConflict)
+
+	valRef, err := immuStore.Get([]byte("key"))
+	require.NoError(t, err)
+	require.Equal(t, txID2, valRef.Tx())
+
+	val, err := valRef.Resolve()
+	require.NoError(t, err)
+	require.Equal(t, []byte("value2"), val)
+
+	t.Run("only one concurrent update should succeed", func(t *testing.T) {
+		workers := 10
+
+		var wg sync.WaitGroup
+		var succeeded int32
+
+		for i := 0; i < workers; i++ {
+			wg.Add(1)
+
+			go func(i int) {
+				defer wg.Done()
+
+				_, err := immuStore.CAS([]byte("key"), txID2, []byte(fmt.Sprintf("value_%d", i)))
+				if err == nil {
+					atomic.AddInt32(&succeeded, 1)
+					return
+				}
+
+				require.ErrorIs(t, err, ErrVersionConflict)
+			}(i)
+		}
+
+		wg.Wait()
+
+		require.Equal(t, int32(1), succeeded)
+	})
+}
+
+func TestImmudbStoreMultiDelete(t *testing.T) {
+	dir, err := ioutil.TempDir("", "data_multi_delete")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	immuStore, err := Open(dir, DefaultOptions().WithMaxTxEntries(2))
+	require.NoError(t, err)
+	defer immustoreClose(t, immuStore)
+
+	_, err = immuStore.MultiDelete(nil)
+	require.ErrorIs(t, err, ErrIllegalArguments)
+
+	_, err = immuStore.MultiDelete([][]byte{[]byte("key0"), nil})
+	require.ErrorIs(t, err, ErrNullKey)
+
+	var keys [][]byte
+
+	for i := 0; i < 5; i++ {
+		key := []byte(fmt.Sprintf("key%d", i))
+		keys = append(keys, key)
+
+		tx, err := immuStore.NewWriteOnlyTx()
+		require.NoError(t, err)
+
+		err = tx.Set(key, nil, []byte("value"))
+		require.NoError(t, err)
+
+		_, err = tx.Commit()
+		require.NoError(t, err)
+	}
+
+	txID, err := immuStore.MultiDelete(keys)
+	require.NoError(t, err)
+	require.Equal(t, uint64(5+3), txID)
+
+	for _, key := range keys {
+		_, err = immuStore.Get(key)
+		require.ErrorIs(t, err, ErrKeyNotFound)
+
+		valRef, err := immuStore.GetWith(key)
+		require.NoError(t, err)
+		require.True(t, valRef.KVMetadata().Deleted())
+	}
+}
+
+func TestImmudbStoreFaultInjectAppendable(t *testing.T) {
+	dir, err := ioutil.TempDir("", "data_fault_inject")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	immuStore, err := Open(dir, DefaultOptions().WithMaxIOConcurrency(1))
+	require.NoError(t, err)
+
+	commit := func() error {
+		tx, err := immuStore.NewWriteOnlyTx()
+		require.NoError(t, err)
+
+		err = tx.Set([]byte("key"), nil, []byte("value"))
+		require.NoError(t, err)
+
+		_, err = tx.Commit()
+		return err
+	}
+
+	require.NoError(t, commit())
+
+	err = immuStore.FaultInjectAppendable(1, &FailingAppendable{})
+	require.ErrorIs(t, err, ErrIllegalArguments)
+
+	err = immuStore.FaultInjectAppendable(0, nil)
+	require.ErrorIs(t, err, ErrIllegalArguments)
+
+	vLog := immuStore.vLogs[0].vLog
+
+	err = immuStore.FaultInjectAppendable(0, &FailingAppendable{vLog, 100})
+	require.NoError(t, err)
+
+	require.ErrorIs(t, commit(), errEmulatedAppendableError)
+
+	err = immuStore.FaultInjectAppendable(0, vLog)
+	require.NoError(t, err)
+
+	require.NoError(t, commit())
+
+	valRef, err := immuStore.Get([]byte("key"))
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), valRef.Tx())
+
+	err = immuStore.Close()
+	require.NoError(t, err)
+
+	err = immuStore.FaultInjectAppendable(0, vLog)
+	require.ErrorIs(t, err, ErrAlreadyClosed)
+}
+
+func TestImmudbStoreGetManyWithMVCC(t *testing.T) {
+	dir, err := ioutil.TempDir("", "data_get_many_mvcc")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	immuStore, err := Open(dir, DefaultOptions())
+	require.NoError(t, err)
+	defer immustoreClose(t, immuStore)
+
+	set := func(key, value string) uint64 {
+		tx, err := immuStore.NewWriteOnlyTx()
+		require.NoError(t, err)
+
+		err = tx.Set([]byte(key), nil, []byte(value))
+		require.NoError(t, err)
+
+		hdr, err := tx.Commit()
+		require.NoError(t, err)
+
+		return hdr.ID
+	}
+
+	_, err = immuStore.GetManyWithMVCC(nil, 1)
+	require.ErrorIs(t, err, ErrIllegalArguments)
+
+	_, err = immuStore.GetManyWithMVCC([][]byte{[]byte("key1")}, 0)
+	require.ErrorIs(t, err, ErrIllegalArguments)
+
+	_, err = immuStore.GetManyWithMVCC([][]byte{nil}, 1)
+	require.ErrorIs(t, err, ErrNullKey)
+
+	_, err = immuStore.GetManyWithMVCC([][]byte{[]byte("key1")}, 1)
+	require.ErrorIs(t, err, ErrTxNotFound)
+
+	tx1 := set("key1", "value1_1")
+	set("key2", "value2_1")
+
+	for i := 0; i < 100; i++ {
+		set("key1", fmt.Sprintf("value1_%d", i+2))
+	}
+
+	_, err = immuStore.MultiDelete([][]byte{[]byte("key2")})
+	require.NoError(t, err)
+
+	keys := [][]byte{[]byte("key1"), []byte("key2"), []byte("key3")}
+
+	results, err := immuStore.GetManyWithMVCC(keys, tx1)
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	require.Equal(t, []byte("value1_1"), results[0].Value)
+	require.Equal(t, tx1, results[0].TxID)
+	require.Nil(t, results[1])
+	require.Nil(t, results[2])
+
+	results, err = immuStore.GetManyWithMVCC(keys, tx1+1)
+	require.NoError(t, err)
+	require.Equal(t, []byte("value1_1"), results[0].Value)
+	require.Equal(t, []byte("value2_1"), results[1].Value)
+	require.Equal(t, tx1+1, results[1].TxID)
+	require.Nil(t, results[2])
+
+	results, err = immuStore.GetManyWithMVCC(keys, tx1+101)
+	require.NoError(t, err)
+	require.Equal(t, []byte("value1_101"), results[0].Value)
+	require.Equal(t, []byte("value2_1"), results[1].Value)
+
+	lastTxID := immuStore.TransactionCount()
+
+	results, err = immuStore.GetManyWithMVCC(keys, lastTxID)
+	require.NoError(t, err)
+	require.Equal(t, []byte("value1_101"), results[0].Value)
+	require.Nil(t, results[1])
+
+	t.Run("expiration should be evaluated at the commit time of the transaction", func(t *testing.T) {
+		immuStore, cleanup, err := NewTempStore(DefaultOptions())
+		require.NoError(t, err)
+		defer cleanup()
+
+		now := time.Now()
+
+		err = immuStore.UseTimeFunc(func() time.Time { return now.Add(-time.Hour) })
+		require.NoError(t, err)
+
+		md := NewKVMetadata()
+		err = md.ExpiresAt(now.Add(-time.Minute))
+		require.NoError(t, err)
+
+		tx, err := immuStore.NewWriteOnlyTx()
+		require.NoError(t, err)
+
+		err = tx.Set([]byte("key1"), md, []byte("value1"))
+		require.NoError(t, err)
+
+		hdr, err := tx.Commit()
+		require.NoError(t, err)
+
+		results, err := immuStore.GetManyWithMVCC([][]byte{[]byte("key1")}, hdr.ID)
+		require.NoError(t, err)
+		require.Equal(t, []byte("value1"), results[0].Value)
+
+		err = immuStore.UseTimeFunc(time.Now)
+		require.NoError(t, err)
+
+		tx, err = immuStore.NewWriteOnlyTx()
+		require.NoError(t, err)
+
+		err = tx.Set([]byte("key2"), nil, []byte("value2"))
+		require.NoError(t, err)
+
+		hdr, err = tx.Commit()
+		require.NoError(t, err)
+
+		results, err = immuStore.GetManyWithMVCC([][]byte{[]byte("key1")}, hdr.ID)
+		require.NoError(t, err)
+		require.Nil(t, results[0])
+	})
+}
+
+func TestImmudbStoreTxEntriesCount(t *testing.T) {
+	dir, err := ioutil.TempDir("", "data_tx_entries_count")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	immuStore, err := Open(dir, DefaultOptions())
+	require.NoError(t, err)
+	defer immustoreClose(t, immuStore)
+
+	_, err = immuStore.TxEntriesCount(1)
+	require.ErrorIs(t, err, ErrTxNotFound)
+
+	for i := 1; i <= 3; i++ {
+		tx, err := immuStore.NewWriteOnlyTx()
+		require.NoError(t, err)
+
+		for j := 0; j < i; j++ {
+			err = tx.Set([]byte(fmt.Sprintf("key%d", j)), nil, []byte("value"))
+			require.NoError(t, err)
+		}
+
+		hdr, err := tx.Commit()
+		require.NoError(t, err)
+
+		n, err := immuStore.TxEntriesCount(hdr.ID)
+		require.NoError(t, err)
+		require.Equal(t, i, n)
+	}
+}
+
+func TestImmudbStoreGetSnapshotAtIndex(t *testing.T) {
+	dir, err := ioutil.TempDir("", "data_snapshot_at_index")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	immuStore, err := Open(dir, DefaultOptions())
+	require.NoError(t, err)
+	defer immustoreClose(t, immuStore)
+
+	_, err = immuStore.GetSnapshotAtIndex(0)
+	require.ErrorIs(t, err, ErrNoSnapshotAvailable)
+
+	for i := 1; i <= 3; i++ {
+		tx, err := immuStore.NewWriteOnlyTx()
+		require.NoError(t, err)
+
+		err = tx.Set([]byte("key1"), nil, []byte(fmt.Sprintf("value%d", i)))
+		require.NoError(t, err)
+
+		hdr, err := tx.Commit()
+		require.NoError(t, err)
+
+		err = immuStore.WaitForIndexingUpto(hdr.ID, nil)
+		require.NoError(t, err)
+
+		err = immuStore.FlushIndex(0, true)
+		require.NoError(t, err)
+	}
+
+	for i := 1; i <= 3; i++ {
+		snap, err := immuStore.GetSnapshotAtIndex(uint64(i))
+		require.NoError(t, err)
+		require.Equal(t, uint64(i), snap.Ts())
+
+		valRef, err := snap.Get([]byte("key1"))
+		require.NoError(t, err)
+		require.Equal(t, uint64(i), valRef.Tx())
+
+		v, err := valRef.Resolve()
+		require.NoError(t, err)
+		require.Equal(t, []byte(fmt.Sprintf("value%d", i)), v)
+
+		err = snap.Close()
+		require.NoError(t, err)
+	}
+}
+
+func TestNewTempStore(t *testing.T) {
+	_, _, err := NewTempStore(nil)
+	require.ErrorIs(t, err, ErrIllegalArguments)
+
+	immuStore, cleanup, err := NewTempStore(DefaultOptions())
+	require.NoError(t, err)
+
+	path := immuStore.path
+	require.DirExists(t, path)
+
+	tx, err := immuStore.NewWriteOnlyTx()
+	require.NoError(t, err)
+
+	err = tx.Set([]byte("key1"), nil, []byte("value1"))
+	require.NoError(t, err)
+
+	_, err = tx.Commit()
+	require.NoError(t, err)
+
+	cleanup()
+
+	require.NoDirExists(t, path)
+
+	_, err = immuStore.Get([]byte("key1"))
+	require.ErrorIs(t, err, ErrAlreadyClosed)
+}
+
+func TestImmudbStoreSetIndexSyncInterval(t *testing.T) {
+	immuStore, cleanup, err := NewTempStore(DefaultOptions())
+	require.NoError(t, err)
+	defer cleanup()
+
+	set := func(key string) uint64 {
+		tx, err := immuStore.NewWriteOnlyTx()
+		require.NoError(t, err)
+
+		err = tx.Set([]byte(key), nil, []byte("value"))
+		require.NoError(t, err)
+
+		hdr, err := tx.Commit()
+		require.NoError(t, err)
+
+		return hdr.ID
+	}
+
+	err = immuStore.SetIndexSyncInterval(-1)
+	require.ErrorIs(t, err, ErrIllegalArguments)
+
+	txID := set("key1")
+
+	_, err = immuStore.GetSnapshotAtIndex(txID)
+	require.ErrorIs(t, err, ErrNoSnapshotAvailable)
+
+	t.Run("a zero interval should trigger an immediate sync", func(t *testing.T) {
+		err = immuStore.SetIndexSyncInterval(0)
+		require.NoError(t, err)
+
+		snap, err := immuStore.GetSnapshotAtIndex(txID)
+		require.NoError(t, err)
+		require.Equal(t, txID, snap.Ts())
+
+		err = snap.Close()
+		require.NoError(t, err)
+	})
+
+	t.Run("the index should be periodically synced", func(t *testing.T) {
+		err = immuStore.SetIndexSyncInterval(time.Millisecond)
+		require.NoError(t, err)
+
+		err = immuStore.SetIndexSyncInterval(MinIndexSyncInterval)
+		require.NoError(t, err)
+
+		txID := set("key2")
+
+		require.Eventually(t, func() bool {
+			snap, err := immuStore.GetSnapshotAtIndex(txID)
+			if err != nil {
+				return false
+			}
+			defer snap.Close()
+
+			return snap.Ts() == txID
+		}, 5*time.Second, 10*time.Millisecond)
+	})
+
+	err = immuStore.Close()
+	require.NoError(t, err)
+
+	err = immuStore.SetIndexSyncInterval(time.Second)
+	require.ErrorIs(t, err, ErrAlreadyClosed)
+}
+
+func TestImmudbStoreWaitForIndexing(t *testing.T) {
+	immuStore, cleanup, err := NewTempStore(DefaultOptions())
+	require.NoError(t, err)
+	defer cleanup()
+
+	err = immuStore.WaitForIndexing()
+	require.NoError(t, err)
+
+	for i := 0; i < 100; i++ {
+		tx, err := immuStore.NewWriteOnlyTx()
+		require.NoError(t, err)
+
+		err = tx.Set([]byte(fmt.Sprintf("key%d", i)), nil, []byte("value"))
+		require.NoError(t, err)
+
+		_, err = tx.AsyncCommit()
+		require.NoError(t, err)
+	}
+
+	err = immuStore.WaitForIndexing()
+	require.NoError(t, err)
+
+	require.Equal(t, immuStore.TxCount(), immuStore.IndexInfo())
+
+	err = immuStore.Close()
+	require.NoError(t, err)
+
+	err = immuStore.WaitForIndexing()
+	require.ErrorIs(t, err, ErrAlreadyClosed)
+}
+
+func TestImmudbStoreGetCurrentState(t *testing.T) {
+	immuStore, cleanup, err := NewTempStore(DefaultOptions())
+	require.NoError(t, err)
+	defer cleanup()
+
+	state, err := immuStore.GetCurrentState()
+	require.NoError(t, err)
+	require.Zero(t, state.TxID)
+	require.Equal(t, sha256.Sum256(nil), state.Alh)
+
+	for i := 0; i < 3; i++ {
+		tx, err := immuStore.NewWriteOnlyTx()
+		require.NoError(t, err)
+
+		err = tx.Set([]byte(fmt.Sprintf("key%d", i)), nil, []byte("value"))
+		require.NoError(t, err)
+
+		hdr, err := tx.AsyncCommit()
+		require.NoError(t, err)
+
+		state, err := immuStore.GetCurrentState()
+		require.NoError(t, err)
+		require.Equal(t, hdr.ID, state.TxID)
+		require.Equal(t, hdr.Alh(), state.Alh)
+	}
+
+	err = immuStore.Close()
+	require.NoError(t, err)
+
+	_, err = immuStore.GetCurrentState()
+	require.ErrorIs(t, err, ErrAlreadyClosed)
+}
+
+func TestImmudbStoreDetachIndexer(t *testing.T) {
+	immuStore, cleanup, err := NewTempStore(DefaultOptions())
+	require.NoError(t, err)
+	defer cleanup()
+
+	set := func(key string) uint64 {
+		tx, err := immuStore.NewWriteOnlyTx()
+		require.NoError(t, err)
+
+		err = tx.Set([]byte(key), nil, []byte("value"))
+		require.NoError(t, err)
+
+		hdr, err := tx.AsyncCommit()
+		require.NoError(t, err)
+
+		return hdr.ID
+	}
+
+	err = immuStore.ReattachIndexer()
+	require.ErrorIs(t, err, ErrIndexerNotDetached)
+
+	txID := set("key1")
+
+	err = immuStore.WaitForIndexing()
+	require.NoError(t, err)
+
+	err = immuStore.DetachIndexer()
+	require.NoError(t, err)
+
+	err = immuStore.DetachIndexer()
+	require.ErrorIs(t, err, ErrIndexerAlreadyDetached)
+
+	set("key2")
+
+	snap, err := immuStore.Snapshot()
+	require.NoError(t, err)
+	require.Equal(t, txID, snap.Ts())
+
+	_, err = snap.Get([]byte("key1"))
+	require.NoError(t, err)
+
+	_, err = snap.Get([]byte("key2"))
+	require.ErrorIs(t, err, ErrKeyNotFound)
+
+	err = snap.Close()
+	require.NoError(t, err)
+
+	err = immuStore.ReattachIndexer()
+	require.NoError(t, err)
+
+	err = immuStore.ReattachIndexer()
+	require.ErrorIs(t, err, ErrIndexerNotDetached)
+
+	err = immuStore.WaitForIndexing()
+	require.NoError(t, err)
+
+	_, err = immuStore.Get([]byte("key2"))
+	require.NoError(t, err)
+
+	t.Run("a store with a detached indexer should be closed", func(t *testing.T) {
+		err = immuStore.DetachIndexer()
+		require.NoError(t, err)
+
+		err = immuStore.Close()
+		require.NoError(t, err)
+
+		err = immuStore.DetachIndexer()
+		require.ErrorIs(t, err, ErrAlreadyClosed)
+
+		err = immuStore.ReattachIndexer()
+		require.ErrorIs(t, err, ErrAlreadyClosed)
+	})
+}
+
+func TestImmudbStoreVLogMmap(t *testing.T) {
+	immuStore, cleanup, err := NewTempStore(DefaultOptions().WithVLogMmapSize(1 << 20))
+	require.NoError(t, err)
+	defer cleanup()
+
+	for i := 0; i < 10; i++ {
+		tx, err := immuStore.NewWriteOnlyTx()
+		require.NoError(t, err)
+
+		err = tx.Set([]byte(fmt.Sprintf("key%d", i)), nil, []byte(fmt.Sprintf("value%d", i)))
+		require.NoError(t, err)
+
+		_, err = tx.Commit()
+		require.NoError(t, err)
+	}
+
+	err = immuStore.Sync()
+	require.NoError(t, err)
+
+	for i := 0; i < 10; i++ {
+		valRef, err := immuStore.Get([]byte(fmt.Sprintf("key%d", i)))
+		require.NoError(t, err)
+
+		val, err := valRef.Resolve()
+		require.NoError(t, err)
+		require.Equal(t, []byte(fmt.Sprintf("value%d", i)), val)
+	}
+}
+
+func TestImmudbStoreLinearProofBetween(t *testing.T) {
+	immuStore, cleanup, err := NewTempStore(DefaultOptions())
+	require.NoError(t, err)
+	defer cleanup()
+
+	_, err = immuStore.LinearProofBetween(sha256.Sum256(nil), sha256.Sum256(nil))
+	require.ErrorIs(t, err, ErrHashNotFound)
+
+	var alhs [][sha256.Size]byte
+
+	for i := 0; i < 5; i++ {
+		tx, err := immuStore.NewWriteOnlyTx()
+		require.NoError(t, err)
+
+		err = tx.Set([]byte(fmt.Sprintf("key%d", i)), nil, []byte("value"))
+		require.NoError(t, err)
+
+		hdr, err := tx.Commit()
+		require.NoError(t, err)
+
+		alhs = append(alhs, hdr.Alh())
+	}
+
+	proof, err := immuStore.LinearProofBetween(alhs[1], alhs[3])
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), proof.SourceTxID)
+	require.Equal(t, uint64(4), proof.TargetTxID)
+	require.True(t, VerifyLinearProof(proof, 2, 4, alhs[1], alhs[3]))
+
+	_, err = immuStore.LinearProofBetween(alhs[0], sha256.Sum256([]byte("unknown")))
+	require.ErrorIs(t, err, ErrHashNotFound)
+
+	_, err = immuStore.LinearProofBetween(sha256.Sum256([]byte("unknown")), alhs[0])
+	require.ErrorIs(t, err, ErrHashNotFound)
+
+	_, err = immuStore.LinearProofBetween(alhs[3], alhs[1])
+	require.ErrorIs(t, err, ErrSourceTxNewerThanTargetTx)
+}
+
+func TestImmudbStoreRenameKey(t *testing.T) {
+	immuStore, cleanup, err := NewTempStore(DefaultOptions())
+	require.NoError(t, err)
+	defer cleanup()
+
+	set := func(key, value string) {
+		tx, err := immuStore.NewWriteOnlyTx()
+		require.NoError(t, err)
+
+		err = tx.Set([]byte(key), nil, []byte(value))
+		require.NoError(t, err)
+
+		_, err = tx.Commit()
+		require.NoError(t, err)
+	}
+
+	_, err = immuStore.RenameKey(nil, []byte("key"))
+	require.ErrorIs(t, err, ErrNullKey)
+
+	_, err = immuStore.RenameKey([]byte("key"), nil)
+	require.ErrorIs(t, err, ErrNullKey)
+
+	_, err = immuStore.RenameKey([]byte("key"), []byte("key"))
+	require.ErrorIs(t, err, ErrIllegalArguments)
+
+	_, err = immuStore.RenameKey([]byte("dir1/file"), []byte("dir2/file"))
+	require.ErrorIs(t, err, ErrKeyNotFound)
+
+	set("dir1/file", "content")
+	set("dir3/file", "other content")
+
+	txID, err := immuStore.RenameKey([]byte("dir1/file"), []byte("dir2/file"))
+	require.NoError(t, err)
+
+	valRef, err := immuStore.Get([]byte("dir2/file"))
+	require.NoError(t, err)
+	require.Equal(t, txID, valRef.Tx())
+
+	val, err := valRef.Resolve()
+	require.NoError(t, err)
+	require.Equal(t, []byte("content"), val)
+
+	_, err = immuStore.Get([]byte("dir1/file"))
+	require.ErrorIs(t, err, ErrKeyNotFound)
+
+	_, err = immuStore.RenameKey([]byte("dir2/file"), []byte("dir3/file"))
+	require.ErrorIs(t, err, ErrKeyAlreadyExists)
+
+	immuStore.overwriteOnRename = true
+
+	_, err = immuStore.RenameKey([]byte("dir2/file"), []byte("dir3/file"))
+	require.NoError(t, err)
+
+	valRef, err = immuStore.Get([]byte("dir3/file"))
+	require.NoError(t, err)
+
+	val, err = valRef.Resolve()
+	require.NoError(t, err)
+	require.Equal(t, []byte("content"), val)
+}
+
+func TestImmudbStoreGetFirstTxAfterKey(t *testing.T) {
+	immuStore, cleanup, err := NewTempStore(DefaultOptions())
+	require.NoError(t, err)
+	defer cleanup()
+
+	_, err = immuStore.GetFirstTxAfterKey(nil, 0)
+	require.ErrorIs(t, err, ErrNullKey)
+
+	_, err = immuStore.GetFirstTxAfterKey([]byte("key"), 0)
+	require.ErrorIs(t, err, ErrTxNotFound)
+
+	var keyTxs []uint64
+
+	for i := 0; i < 20; i++ {
+		tx, err := immuStore.NewWriteOnlyTx()
+		require.NoError(t, err)
+
+		if i%3 == 0 {
+			err = tx.Set([]byte("key"), nil, []byte{byte(i)})
+			require.NoError(t, err)
+		}
+
+		err = tx.Set([]byte("other"), nil, []byte{byte(i)})
+		require.NoError(t, err)
+
+		hdr, err := tx.AsyncCommit()
+		require.NoError(t, err)
+
+		if i%3 == 0 {
+			keyTxs = append(keyTxs, hdr.ID)
+		}
+	}
+
+	txID, err := immuStore.GetFirstTxAfterKey([]byte("key"), 0)
+	require.NoError(t, err)
+	require.Equal(t, keyTxs[0], txID)
+
+	for i, keyTx := range keyTxs[:len(keyTxs)-1] {
+		txID, err = immuStore.GetFirstTxAfterKey([]byte("key"), keyTx)
+		require.NoError(t, err)
+		require.Equal(t, keyTxs[i+1], txID)
+
+		txID, err = immuStore.GetFirstTxAfterKey([]byte("key"), keyTx+1)
+		require.NoError(t, err)
+		require.Equal(t, keyTxs[i+1], txID)
+	}
+
+	_, err = immuStore.GetFirstTxAfterKey([]byte("key"), keyTxs[len(keyTxs)-1])
+	require.ErrorIs(t, err, ErrTxNotFound)
+}
+
+func TestImmudbStoreGetWithVersion(t *testing.T) {
+	immuStore, cleanup, err := NewTempStore(DefaultOptions())
+	require.NoError(t, err)
+	defer cleanup()
+
+	_, _, err = immuStore.GetWithVersion([]byte("key1"))
+	require.ErrorIs(t, err, ErrKeyNotFound)
+
+	for i := 1; i <= 2; i++ {
+		tx, err := immuStore.NewWriteOnlyTx()
+		require.NoError(t, err)
+
+		err = tx.Set([]byte("key1"), nil, []byte(fmt.Sprintf("value%d", i)))
+		require.NoError(t, err)
+
+		hdr, err := tx.Commit()
+		require.NoError(t, err)
+
+		value, txID, err := immuStore.GetWithVersion([]byte("key1"))
+		require.NoError(t, err)
+		require.Equal(t, []byte(fmt.Sprintf("value%d", i)), value)
+		require.Equal(t, hdr.ID, txID)
+	}
+
+	tx, err := immuStore.NewTx()
+	require.NoError(t, err)
+
+	err = tx.Delete([]byte("key1"))
+	require.NoError(t, err)
+
+	_, err = tx.Commit()
+	require.NoError(t, err)
+
+	_, _, err = immuStore.GetWithVersion([]byte("key1"))
+	require.ErrorIs(t, err, ErrKeyNotFound)
+}
+
+func TestImmudbStoreTransactionsByTimeRange(t *testing.T) {
+	immuStore, cleanup, err := NewTempStore(DefaultOptions())
+	require.NoError(t, err)
+	defer cleanup()
+
+	start := time.Unix(1700000000, 0)
+
+	txIDs, err := immuStore.TransactionsByTimeRange(start, start.Add(time.Hour))
+	require.NoError(t, err)
+	require.Empty(t, txIDs)
+
+	_, err = immuStore.TransactionsByTimeRange(start.Add(time.Second), start)
+	require.ErrorIs(t, err, ErrIllegalArguments)
+
+	// two transactions per second
+	for i := 0; i < 10; i++ {
+		ts := start.Add(time.Duration(i/2) * time.Second)
+
+		err = immuStore.UseTimeFunc(func() time.Time { return ts })
+		require.NoError(t, err)
+
+		tx, err := immuStore.NewWriteOnlyTx()
+		require.NoError(t, err)
+
+		err = tx.Set([]byte("key"), nil, []byte("value"))
+		require.NoError(t, err)
+
+		_, err = tx.Commit()
+		require.NoError(t, err)
+	}
+
+	txIDs, err = immuStore.TransactionsByTimeRange(start.Add(time.Second), start.Add(2*time.Second))
+	require.NoError(t, err)
+	require.Equal(t, []uint64{3, 4, 5, 6}, txIDs)
+
+	txIDs, err = immuStore.TransactionsByTimeRange(start, start)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{1, 2}, txIDs)
+
+	txIDs, err = immuStore.TransactionsByTimeRange(start.Add(-time.Hour), start.Add(time.Hour))
+	require.NoError(t, err)
+	require.Len(t, txIDs, 10)
+
+	txIDs, err = immuStore.TransactionsByTimeRange(start.Add(time.Hour), start.Add(2*time.Hour))
+	require.NoError(t, err)
+	require.Empty(t, txIDs)
+
+	txIDs, err = immuStore.TransactionsByTimeRange(start.Add(-2*time.Hour), start.Add(-time.Hour))
+	require.NoError(t, err)
+	require.Empty(t, txIDs)
+}
+
+func TestImmudbStoreBatchReadTx(t *testing.T) {
+	immuStore, cleanup, err := NewTempStore(DefaultOptions())
+	require.NoError(t, err)
+	defer cleanup()
+
+	for i := 0; i < 10; i++ {
+		tx, err := immuStore.NewWriteOnlyTx()
+		require.NoError(t, err)
+
+		for j := 0; j <= i; j++ {
+			err = tx.Set([]byte(fmt.Sprintf("key%d", j)), nil, []byte(fmt.Sprintf("value%d_%d", i, j)))
+			require.NoError(t, err)
+		}
+
+		_, err = tx.Commit()
+		require.NoError(t, err)
+	}
+
+	err = immuStore.BatchReadTx([]uint64{1}, nil)
+	require.ErrorIs(t, err, ErrIllegalArguments)
+
+	err = immuStore.BatchReadTx([]uint64{1}, []*Tx{nil})
+	require.ErrorIs(t, err, ErrIllegalArguments)
+
+	err = immuStore.BatchReadTx([]uint64{11}, []*Tx{tempTxHolder(t, immuStore)})
+	require.ErrorIs(t, err, ErrTxNotFound)
+
+	txIDs := []uint64{7, 2, 3, 10, 1, 3, 9}
+
+	txs := make([]*Tx, len(txIDs))
+	for i := range txs {
+		txs[i] = tempTxHolder(t, immuStore)
+	}
+
+	err = immuStore.BatchReadTx(txIDs, txs)
+	require.NoError(t, err)
+
+	tx := tempTxHolder(t, immuStore)
+
+	for i, txID := range txIDs {
+		err = immuStore.ReadTx(txID, tx)
+		require.NoError(t, err)
+
+		require.Equal(t, txID, txs[i].header.ID)
+		require.Equal(t, tx.header.Alh(), txs[i].header.Alh())
+		require.Equal(t, tx.header.NEntries, txs[i].header.NEntries)
+
+		for j, e := range tx.Entries() {
+			require.Equal(t, e.Key(), txs[i].Entries()[j].Key())
+			require.Equal(t, e.HVal(), txs[i].Entries()[j].HVal())
+		}
+	}
+}
@@ -18,8 +18,10 @@ package store
 import (
 	"crypto/sha256"
 	"encoding/binary"
+	"fmt"
 	"path/filepath"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/codenotary/immudb/embedded/tbtree"
@@ -29,6 +31,10 @@ import (
 )
 
 type indexer struct {
+	// indexedUpToTxID is the last transaction fully indexed, it's accessed atomically
+	// and kept as the first field so it is 64-bit aligned on 32-bit platforms
+	indexedUpToTxID uint64
+
 	path string
 
 	store *ImmuStore
@@ -42,8 +48,32 @@ type indexer struct {
 	state     int
 	stateCond *sync.Cond
 
+	indexingWG sync.WaitGroup
+	detached   bool
+
+	syncTicker *time.Ticker
+	syncDone   chan struct{}
+
 	closed bool
 
+	// indexingErr holds the error of the last failed attempt to index a transaction, nil once indexing succeeds.
+	// indexingErrCh is closed and replaced whenever indexing fails, waking up EnsureIndexUpto callers
+	indexingErr   error
+	indexingErrCh chan struct{}
+	errMutex      sync.Mutex
+
+	// kvCount is the number of live keys, only maintained once kvCountTracked is set by KVCount.
+	// kvCountMutex is held while indexing a transaction
+	kvCount        uint64
+	kvCountTracked bool
+	kvCountMutex   sync.Mutex
+
+	// the index is flushed every flushTxThld transactions (when positive), lastFlushedTxID is the
+	// last transaction indexed when it was flushed because of it or when the index was opened.
+	// Such flushes wait for an ongoing compaction to complete, thus indexing is paused meanwhile
+	flushTxThld     int
+	lastFlushedTxID uint64
+
 	compactionMutex sync.Mutex
 	mutex           sync.Mutex
 
@@ -51,6 +81,8 @@ type indexer struct {
 	metricsLastIndexedTrx   prometheus.Gauge
 }
 
+const MinIndexSyncInterval = 100 * time.Millisecond
+
 type runningState = int
 
 const (
@@ -74,7 +106,7 @@ var (
 	})
 )
 
-func newIndexer(path string, store *ImmuStore, indexOpts *tbtree.Options, maxWaitees int) (*indexer, error) {
+func newIndexer(path string, store *ImmuStore, indexOpts *tbtree.Options, maxWaitees int, flushTxThld int) (*indexer, error) {
 	index, err := tbtree.Open(path, indexOpts)
 	if err != nil {
 		return nil, err
@@ -91,15 +123,20 @@ func newIndexer(path string, store *ImmuStore, indexOpts *tbtree.Options, maxWai
 	}
 
 	indexer := &indexer{
-		store:     store,
-		tx:        tx,
-		path:      path,
-		index:     index,
-		wHub:      wHub,
-		state:     stopped,
-		stateCond: sync.NewCond(&sync.Mutex{}),
+		store:         store,
+		tx:            tx,
+		path:          path,
+		index:         index,
+		wHub:          wHub,
+		state:         stopped,
+		stateCond:     sync.NewCond(&sync.Mutex{}),
+		indexingErrCh: make(chan struct{}),
+		flushTxThld:   flushTxThld,
 	}
 
+	indexer.lastFlushedTxID = index.Ts()
+	indexer.indexedUpToTxID = index.Ts()
+
 	dbName := filepath.Base(store.path)
 	indexer.metricsLastIndexedTrx = metricsLastIndexedTrxId.WithLabelValues(dbName)
 	indexer.metricsLastCommittedTrx = metricsLastCommittedTrx.WithLabelValues(dbName)
@@ -116,6 +153,11 @@ func (idx *indexer) Ts() uint64 {
 	return idx.index.Ts()
 }
 
+// IndexedUpToTxID returns the last transaction fully indexed, it does not wait for the index lock
+func (idx *indexer) IndexedUpToTxID() uint64 {
+	return atomic.LoadUint64(&idx.indexedUpToTxID)
+}
+
 func (idx *indexer) Get(key []byte) (value []byte, tx uint64, hc uint64, err error) {
 	idx.mutex.Lock()
 	defer idx.mutex.Unlock()
@@ -160,6 +202,17 @@ func (idx *indexer) SnapshotSince(tx uint64) (*tbtree.Snapshot, error) {
 	return idx.index.SnapshotSince(tx)
 }
 
+func (idx *indexer) SnapshotAtTs(ts uint64) (*tbtree.Snapshot, error) {
+	idx.mutex.Lock()
+	defer idx.mutex.Unlock()
+
+	if idx.closed {
+		return nil, ErrAlreadyClosed
+	}
+
+	return idx.index.SnapshotAtTs(ts)
+}
+
 func (idx *indexer) ExistKeyWith(prefix []byte, neq []byte) (bool, error) {
 	idx.mutex.Lock()
 	defer idx.mutex.Unlock()
@@ -171,6 +224,22 @@ func (idx *indexer) ExistKeyWith(prefix []byte, neq []byte) (bool, error) {
 	return idx.index.ExistKeyWith(prefix, neq)
 }
 
+func (idx *indexer) WarmUp(fraction float64) (int, error) {
+	idx.mutex.Lock()
+	defer idx.mutex.Unlock()
+
+	if idx.closed {
+		return 0, ErrAlreadyClosed
+	}
+
+	loaded, err := idx.index.WarmUp(fraction)
+	if err == tbtree.ErrIllegalArguments {
+		return loaded, ErrIllegalArguments
+	}
+
+	return loaded, err
+}
+
 func (idx *indexer) Sync() error {
 	idx.mutex.Lock()
 	defer idx.mutex.Unlock()
@@ -182,6 +251,59 @@ func (idx *indexer) Sync() error {
 	return idx.index.Sync()
 }
 
+// SetSyncInterval makes the index to be periodically synced every d.
+// A zero interval triggers an immediate sync while keeping the current period
+func (idx *indexer) SetSyncInterval(d time.Duration) error {
+	if d < 0 {
+		return ErrIllegalArguments
+	}
+
+	if d == 0 {
+		return idx.Sync()
+	}
+
+	idx.mutex.Lock()
+	defer idx.mutex.Unlock()
+
+	if idx.closed {
+		return ErrAlreadyClosed
+	}
+
+	if d < MinIndexSyncInterval {
+		idx.store.logger.Warningf("Index sync interval of %v at '%s' is too small, using %v instead", d, idx.store.path, MinIndexSyncInterval)
+		d = MinIndexSyncInterval
+	}
+
+	if idx.syncTicker != nil {
+		idx.syncTicker.Reset(d)
+		return nil
+	}
+
+	idx.syncTicker = time.NewTicker(d)
+	idx.syncDone = make(chan struct{})
+
+	go idx.doPeriodicSync(idx.syncTicker, idx.syncDone)
+
+	return nil
+}
+
+func (idx *indexer) doPeriodicSync(ticker *time.Ticker, done <-chan struct{}) {
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			err := idx.Sync()
+			if err == ErrAlreadyClosed {
+				return
+			}
+			if err != nil {
+				idx.store.reportError(fmt.Errorf("periodic sync of index at '%s' failed due to error: %w", idx.store.path, err))
+			}
+		}
+	}
+}
+
 func (idx *indexer) Close() error {
 	idx.compactionMutex.Lock()
 	defer idx.compactionMutex.Unlock()
@@ -193,8 +315,17 @@ func (idx *indexer) Close() error {
 		return ErrAlreadyClosed
 	}
 
-	idx.stop()
+	if !idx.detached {
+		idx.stop()
+	}
+
 	idx.wHub.Close()
+
+	if idx.syncTicker != nil {
+		idx.syncTicker.Stop()
+		close(idx.syncDone)
+	}
+
 	idx.store.releaseAllocTx(idx.tx)
 
 	idx.closed = true
@@ -270,7 +401,11 @@ func (idx *indexer) resume() {
 	idx.stateCond.L.Lock()
 	idx.state = running
 	idx.cancellation = make(chan struct{})
-	go idx.doIndexing(idx.cancellation)
+	idx.indexingWG.Add(1)
+	go func(cancellation <-chan struct{}) {
+		defer idx.indexingWG.Done()
+		idx.doIndexing(cancellation)
+	}(idx.cancellation)
 	idx.stateCond.L.Unlock()
 
 	idx.store.notify(Info, true, "Indexing in progress at '%s'", idx.store.path)
@@ -284,8 +419,10 @@ func (idx *indexer) restartIndex() error {
 		return ErrAlreadyClosed
 	}
 
-	idx.stop()
-	defer idx.resume()
+	if !idx.detached {
+		idx.stop()
+		defer idx.resume()
+	}
 
 	opts := idx.index.GetOptions()
 
@@ -301,9 +438,52 @@ func (idx *indexer) restartIndex() error {
 
 	idx.index = index
 
+	atomic.StoreUint64(&idx.indexedUpToTxID, index.Ts())
+
 	return err
 }
 
+// Detach stops the indexing goroutine, waiting for the transaction being indexed to be completed
+func (idx *indexer) Detach() error {
+	idx.mutex.Lock()
+	defer idx.mutex.Unlock()
+
+	if idx.closed {
+		return ErrAlreadyClosed
+	}
+
+	if idx.detached {
+		return ErrIndexerAlreadyDetached
+	}
+
+	idx.stop()
+	idx.indexingWG.Wait()
+
+	idx.detached = true
+
+	return nil
+}
+
+// Reattach starts a new indexing goroutine, resuming from the last indexed transaction
+func (idx *indexer) Reattach() error {
+	idx.mutex.Lock()
+	defer idx.mutex.Unlock()
+
+	if idx.closed {
+		return ErrAlreadyClosed
+	}
+
+	if !idx.detached {
+		return ErrIndexerNotDetached
+	}
+
+	idx.resume()
+
+	idx.detached = false
+
+	return nil
+}
+
 func (idx *indexer) Resume() {
 	idx.stateCond.L.Lock()
 	idx.state = running
@@ -334,7 +514,7 @@ func (idx *indexer) doIndexing(cancellation <-chan struct{}) {
 			return
 		}
 		if err != nil {
-			idx.store.logger.Errorf("Indexing failed at '%s' due to error: %v", idx.store.path, err)
+			idx.store.reportError(fmt.Errorf("indexing failed at '%s' due to error: %w", idx.store.path, err))
 			time.Sleep(60 * time.Second)
 		}
 
@@ -361,19 +541,61 @@ func (idx *indexer) doIndexing(cancellation <-chan struct{}) {
 		if err == ErrAlreadyClosed || err == tbtree.ErrAlreadyClosed {
 			return
 		}
+
+		idx.setIndexingErr(err)
+
 		if err != nil {
-			idx.store.logger.Errorf("Indexing failed at '%s' due to error: %v", idx.store.path, err)
+			idx.store.reportError(fmt.Errorf("indexing failed at '%s' due to error: %w", idx.store.path, err))
 			time.Sleep(60 * time.Second)
 		}
 	}
 }
 
+func (idx *indexer) setIndexingErr(err error) {
+	idx.errMutex.Lock()
+	defer idx.errMutex.Unlock()
+
+	idx.indexingErr = err
+
+	if err != nil {
+		close(idx.indexingErrCh)
+		idx.indexingErrCh = make(chan struct{})
+	}
+}
+
+// EnsureIndexUpto waits until txID is indexed and syncs the index.
+// Unlike WaitForIndexingUpto, it returns the indexing error if indexing fails before reaching txID
+func (idx *indexer) EnsureIndexUpto(txID uint64) error {
+	for idx.index.Ts() < txID {
+		idx.errMutex.Lock()
+		err := idx.indexingErr
+		errCh := idx.indexingErrCh
+		idx.errMutex.Unlock()
+
+		if err != nil {
+			return err
+		}
+
+		err = idx.WaitForIndexingUpto(txID, errCh)
+		if err != nil && err != watchers.ErrCancellationRequested {
+			return err
+		}
+	}
+
+	return idx.Sync()
+}
+
 func (idx *indexer) indexTx(txID uint64) error {
+	idx.kvCountMutex.Lock()
+	defer idx.kvCountMutex.Unlock()
+
 	err := idx.store.ReadTx(txID, idx.tx)
 	if err != nil {
 		return err
 	}
 
+	var kvCountDelta int64
+
 	txEntries := idx.tx.Entries()
 
 	var txmd []byte
@@ -424,6 +646,21 @@ func (idx *indexer) indexTx(txID uint64) error {
 		copy(b[o:], kvmd)
 		o += kvmdLen
 
+		if idx.kvCountTracked {
+			wasLive, err := idx.isLive(e.key())
+			if err != nil {
+				return err
+			}
+
+			isLive := e.md == nil || !e.md.Deleted()
+
+			if !wasLive && isLive {
+				kvCountDelta++
+			} else if wasLive && !isLive {
+				kvCountDelta--
+			}
+		}
+
 		idx.store._kvs[indexableEntries].K = e.key()
 		idx.store._kvs[indexableEntries].V = b[:o]
 
@@ -439,7 +676,21 @@ func (idx *indexer) indexTx(txID uint64) error {
 		return err
 	}
 
+	idx.kvCount = uint64(int64(idx.kvCount) + kvCountDelta)
+
+	atomic.StoreUint64(&idx.indexedUpToTxID, txID)
+
 	idx.metricsLastIndexedTrx.Set(float64(txID))
 
+	if idx.flushTxThld > 0 && txID-idx.lastFlushedTxID >= uint64(idx.flushTxThld) {
+		// no cleanup is done so flushing stays proportional to the changes since the last flush
+		err = idx.FlushIndex(0, false)
+		if err != nil {
+			return err
+		}
+
+		idx.lastFlushedTxID = txID
+	}
+
 	return nil
 }
@@ -16,6 +16,8 @@ limitations under the License.
 package store
 
 import (
+	"errors"
+	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -29,7 +31,7 @@ import (
 )
 
 func TestNewIndexerFailure(t *testing.T) {
-	indexer, err := newIndexer("data", nil, nil, 0)
+	indexer, err := newIndexer("data", nil, nil, 0, 0)
 	require.Nil(t, indexer)
 	require.ErrorIs(t, err, tbtree.ErrIllegalArguments)
 }
@@ -212,3 +214,145 @@ func TestClosedIndexer(t *testing.T) {
 	assert.Error(t, err)
 	assert.Equal(t, err, ErrAlreadyClosed)
 }
+
+func TestEnsureIndex(t *testing.T) {
+	immuStore, cleanup, err := NewTempStore(DefaultOptions())
+	require.NoError(t, err)
+	defer cleanup()
+
+	err = immuStore.EnsureIndex(1)
+	require.ErrorIs(t, err, ErrTxNotFound)
+
+	commit := func() uint64 {
+		tx, err := immuStore.NewWriteOnlyTx()
+		require.NoError(t, err)
+
+		err = tx.Set([]byte("key"), nil, []byte("value"))
+		require.NoError(t, err)
+
+		hdr, err := tx.AsyncCommit()
+		require.NoError(t, err)
+
+		return hdr.ID
+	}
+
+	txID := commit()
+
+	err = immuStore.EnsureIndex(txID)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, immuStore.IndexInfo(), txID)
+
+	t.Run("indexing errors should be returned", func(t *testing.T) {
+		err := immuStore.DetachIndexer()
+		require.NoError(t, err)
+
+		txID := commit()
+
+		errIndexing := errors.New("indexing error")
+
+		errCh := make(chan error)
+		go func() {
+			errCh <- immuStore.EnsureIndex(txID)
+		}()
+
+		time.Sleep(10 * time.Millisecond)
+
+		immuStore.indexer.setIndexingErr(errIndexing)
+		require.ErrorIs(t, <-errCh, errIndexing)
+
+		err = immuStore.EnsureIndex(txID)
+		require.ErrorIs(t, err, errIndexing)
+
+		immuStore.indexer.setIndexingErr(nil)
+
+		err = immuStore.ReattachIndexer()
+		require.NoError(t, err)
+
+		err = immuStore.EnsureIndex(txID)
+		require.NoError(t, err)
+	})
+}
+
+func TestIndexFlushTxThld(t *testing.T) {
+	dir, err := ioutil.TempDir("", "data_index_flush_tx_thld")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	opts := DefaultOptions()
+	opts.IndexOpts.WithFlushThld(1000).WithFlushTxThld(2)
+
+	immuStore, err := Open(dir, opts)
+	require.NoError(t, err)
+	defer immuStore.Close()
+
+	// the flushed index is what would be loaded after a crash
+	flushedTs := func() uint64 {
+		index, err := tbtree.Open(filepath.Join(dir, indexDirname), tbtree.DefaultOptions().WithReadOnly(true))
+		require.NoError(t, err)
+		defer index.Close()
+
+		return index.Ts()
+	}
+
+	for i := 1; i <= 5; i++ {
+		tx, err := immuStore.NewWriteOnlyTx()
+		require.NoError(t, err)
+
+		err = tx.Set([]byte(fmt.Sprintf("key%d", i)), nil, []byte("value"))
+		require.NoError(t, err)
+
+		hdr, err := tx.Commit()
+		require.NoError(t, err)
+
+		err = immuStore.WaitForIndexingUpto(hdr.ID, nil)
+		require.NoError(t, err)
+
+		if i >= 2 {
+			require.EqualValues(t, i-i%2, flushedTs())
+		}
+	}
+}
+
+func TestIndexedUpToTxID(t *testing.T) {
+	immuStore, cleanup, err := NewTempStore(DefaultOptions())
+	require.NoError(t, err)
+	defer cleanup()
+
+	require.Zero(t, immuStore.IndexedUpToTxID())
+
+	commit := func() uint64 {
+		tx, err := immuStore.NewWriteOnlyTx()
+		require.NoError(t, err)
+
+		err = tx.Set([]byte("key"), nil, []byte("value"))
+		require.NoError(t, err)
+
+		hdr, err := tx.AsyncCommit()
+		require.NoError(t, err)
+
+		return hdr.ID
+	}
+
+	for i := 0; i < 3; i++ {
+		commit()
+	}
+
+	err = immuStore.WaitForIndexingUpto(3, nil)
+	require.NoError(t, err)
+	require.EqualValues(t, 3, immuStore.IndexedUpToTxID())
+	require.Equal(t, immuStore.IndexInfo(), immuStore.IndexedUpToTxID())
+
+	err = immuStore.DetachIndexer()
+	require.NoError(t, err)
+
+	txID := commit()
+	require.EqualValues(t, 3, immuStore.IndexedUpToTxID())
+	require.Equal(t, txID, immuStore.TransactionCount())
+
+	err = immuStore.ReattachIndexer()
+	require.NoError(t, err)
+
+	err = immuStore.WaitForIndexingUpto(txID, nil)
+	require.NoError(t, err)
+	require.Equal(t, immuStore.TransactionCount(), immuStore.IndexedUpToTxID())
+}
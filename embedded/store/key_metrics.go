@@ -0,0 +1,122 @@
+/*
+Copyright 2022 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package store
+
+import (
+	"time"
+)
+
+const keyMetricsCacheSize = 1000
+
+const keyMetricsHistoryPageSize = 256
+
+// KeyMetrics holds statistics about the values written for a key, deletions included
+type KeyMetrics struct {
+	Versions    uint64
+	FirstTxID   uint64
+	LastTxID    uint64
+	LastWriteTs time.Time
+
+	MinValueLen int
+	MaxValueLen int
+	AvgValueLen float64
+}
+
+type cachedKeyMetrics struct {
+	indexTs uint64
+	metrics KeyMetrics
+}
+
+// KeyMetrics computes statistics about the values written for key based on its indexed history.
+// Results are cached until the index advances, so repeated calls over the same index state are O(1)
+func (s *ImmuStore) KeyMetrics(key []byte) (*KeyMetrics, error) {
+	if len(key) == 0 {
+		return nil, ErrNullKey
+	}
+
+	indexTs := s.indexer.Ts()
+
+	cached, err := s.keyMetricsCache.Get(string(key))
+	if err == nil && cached.(*cachedKeyMetrics).indexTs == indexTs {
+		metrics := cached.(*cachedKeyMetrics).metrics
+		return &metrics, nil
+	}
+
+	metrics := KeyMetrics{}
+
+	var totalValueLen uint64
+
+	for offset := uint64(0); ; {
+		txIDs, hCount, err := s.indexer.History(key, offset, false, keyMetricsHistoryPageSize)
+		if err == ErrOffsetOutOfRange && offset > 0 {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		for _, txID := range txIDs {
+			e, _, err := s.ReadTxEntry(txID, key)
+			if err != nil {
+				return nil, err
+			}
+
+			if metrics.Versions == 0 {
+				metrics.FirstTxID = txID
+				metrics.MinValueLen = e.vLen
+			}
+
+			metrics.Versions++
+			metrics.LastTxID = txID
+
+			if e.vLen < metrics.MinValueLen {
+				metrics.MinValueLen = e.vLen
+			}
+
+			if e.vLen > metrics.MaxValueLen {
+				metrics.MaxValueLen = e.vLen
+			}
+
+			totalValueLen += uint64(e.vLen)
+		}
+
+		offset += uint64(len(txIDs))
+
+		if offset >= hCount {
+			break
+		}
+	}
+
+	if metrics.Versions == 0 {
+		return nil, ErrKeyNotFound
+	}
+
+	metrics.AvgValueLen = float64(totalValueLen) / float64(metrics.Versions)
+
+	hdr, err := s.ReadTxHeader(metrics.LastTxID)
+	if err != nil {
+		return nil, err
+	}
+
+	metrics.LastWriteTs = time.Unix(hdr.Ts, 0)
+
+	_, _, err = s.keyMetricsCache.Put(string(key), &cachedKeyMetrics{indexTs: indexTs, metrics: metrics})
+	if err != nil {
+		return nil, err
+	}
+
+	return &metrics, nil
+}
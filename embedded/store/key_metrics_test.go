@@ -0,0 +1,94 @@
+/*
+Copyright 2022 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyMetrics(t *testing.T) {
+	immuStore, cleanup, err := NewTempStore(DefaultOptions())
+	require.NoError(t, err)
+	defer cleanup()
+
+	set := func(key string, value []byte) uint64 {
+		tx, err := immuStore.NewWriteOnlyTx()
+		require.NoError(t, err)
+
+		err = tx.Set([]byte(key), nil, value)
+		require.NoError(t, err)
+
+		hdr, err := tx.Commit()
+		require.NoError(t, err)
+
+		return hdr.ID
+	}
+
+	_, err = immuStore.KeyMetrics(nil)
+	require.ErrorIs(t, err, ErrNullKey)
+
+	_, err = immuStore.KeyMetrics([]byte("key1"))
+	require.ErrorIs(t, err, ErrKeyNotFound)
+
+	firstTxID := set("key1", make([]byte, 10))
+	set("key2", make([]byte, 100))
+
+	for i := 0; i < keyMetricsHistoryPageSize; i++ {
+		set("key1", make([]byte, 20))
+	}
+
+	lastTxID := set("key1", make([]byte, 30))
+
+	metrics, err := immuStore.KeyMetrics([]byte("key1"))
+	require.NoError(t, err)
+	require.Equal(t, uint64(keyMetricsHistoryPageSize+2), metrics.Versions)
+	require.Equal(t, firstTxID, metrics.FirstTxID)
+	require.Equal(t, lastTxID, metrics.LastTxID)
+	require.WithinDuration(t, time.Now(), metrics.LastWriteTs, 5*time.Second)
+	require.Equal(t, 10, metrics.MinValueLen)
+	require.Equal(t, 30, metrics.MaxValueLen)
+	require.Equal(t, float64(10+20*keyMetricsHistoryPageSize+30)/float64(keyMetricsHistoryPageSize+2), metrics.AvgValueLen)
+
+	t.Run("metrics should be cached while the index does not change", func(t *testing.T) {
+		cached, err := immuStore.keyMetricsCache.Get("key1")
+		require.NoError(t, err)
+		require.Equal(t, immuStore.indexer.Ts(), cached.(*cachedKeyMetrics).indexTs)
+
+		metrics1, err := immuStore.KeyMetrics([]byte("key1"))
+		require.NoError(t, err)
+		require.Equal(t, metrics, metrics1)
+
+		// cached metrics must not be modified by the caller
+		metrics1.Versions = 0
+
+		metrics2, err := immuStore.KeyMetrics([]byte("key1"))
+		require.NoError(t, err)
+		require.Equal(t, metrics, metrics2)
+	})
+
+	t.Run("metrics should be updated when the index advances", func(t *testing.T) {
+		lastTxID := set("key1", make([]byte, 5))
+
+		metrics, err := immuStore.KeyMetrics([]byte("key1"))
+		require.NoError(t, err)
+		require.Equal(t, uint64(keyMetricsHistoryPageSize+3), metrics.Versions)
+		require.Equal(t, lastTxID, metrics.LastTxID)
+		require.Equal(t, 5, metrics.MinValueLen)
+	})
+}
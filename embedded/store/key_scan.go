@@ -0,0 +1,78 @@
+/*
+Copyright 2022 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package store
+
+// ScanForKeys walks the whole index in key order and returns the current entry of every key for which
+// fn returns true, up to limit matching entries. Deleted and expired entries are skipped.
+// Committed transactions are indexed before the scan begins.
+// Note: this is a sequential scan, its cost is O(N) in the number of indexed keys regardless
+// of the number of matches. Prefer a key reader with a prefix whenever possible
+func (s *ImmuStore) ScanForKeys(fn func(key []byte) bool, limit int) ([]*KVWithTxID, error) {
+	if fn == nil || limit <= 0 {
+		return nil, ErrIllegalArguments
+	}
+
+	txID := s.TransactionCount()
+
+	err := s.WaitForIndexingUpto(txID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	snap, err := s.SnapshotSince(txID)
+	if err != nil {
+		return nil, err
+	}
+	defer snap.Close()
+
+	r, err := snap.NewKeyReader(&KeyReaderSpec{
+		Filters: []FilterFn{IgnoreExpired, IgnoreDeleted},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	var kvs []*KVWithTxID
+
+	for len(kvs) < limit {
+		key, valRef, err := r.Read()
+		if err == ErrNoMoreEntries {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if !fn(key) {
+			continue
+		}
+
+		val, err := valRef.Resolve()
+		if err != nil {
+			return nil, err
+		}
+
+		kvs = append(kvs, &KVWithTxID{
+			Key:      key,
+			Value:    val,
+			Metadata: valRef.KVMetadata(),
+			TxID:     valRef.Tx(),
+		})
+	}
+
+	return kvs, nil
+}
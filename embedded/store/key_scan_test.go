@@ -0,0 +1,77 @@
+/*
+Copyright 2022 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package store
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestImmudbStoreScanForKeys(t *testing.T) {
+	immuStore, cleanup, err := NewTempStore(DefaultOptions())
+	require.NoError(t, err)
+	defer cleanup()
+
+	_, err = immuStore.ScanForKeys(nil, 1)
+	require.ErrorIs(t, err, ErrIllegalArguments)
+
+	thirdByte := func(key []byte) bool {
+		return len(key) > 2 && key[2] == 0x42
+	}
+
+	_, err = immuStore.ScanForKeys(thirdByte, 0)
+	require.ErrorIs(t, err, ErrIllegalArguments)
+
+	kvs, err := immuStore.ScanForKeys(thirdByte, 10)
+	require.NoError(t, err)
+	require.Empty(t, kvs)
+
+	tx, err := immuStore.NewWriteOnlyTx()
+	require.NoError(t, err)
+
+	for _, k := range [][]byte{{1, 1, 0x42}, {0, 0, 0x42, 1}, {0x42}, {2, 2, 0x41}, {3, 3, 0x42}} {
+		err = tx.Set(k, nil, append([]byte("value"), k...))
+		require.NoError(t, err)
+	}
+
+	hdr, err := tx.Commit()
+	require.NoError(t, err)
+
+	tx, err = immuStore.NewTx()
+	require.NoError(t, err)
+
+	err = tx.Delete([]byte{3, 3, 0x42})
+	require.NoError(t, err)
+
+	_, err = tx.Commit()
+	require.NoError(t, err)
+
+	kvs, err = immuStore.ScanForKeys(thirdByte, 10)
+	require.NoError(t, err)
+	require.Len(t, kvs, 2)
+
+	require.Equal(t, []byte{0, 0, 0x42, 1}, kvs[0].Key)
+	require.Equal(t, append([]byte("value"), 0, 0, 0x42, 1), kvs[0].Value)
+	require.Equal(t, hdr.ID, kvs[0].TxID)
+
+	require.Equal(t, []byte{1, 1, 0x42}, kvs[1].Key)
+
+	kvs, err = immuStore.ScanForKeys(thirdByte, 1)
+	require.NoError(t, err)
+	require.Len(t, kvs, 1)
+	require.Equal(t, []byte{0, 0, 0x42, 1}, kvs[0].Key)
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2022 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package store
+
+import (
+	"github.com/codenotary/immudb/embedded/tbtree"
+)
+
+// KVCount returns the number of indexed keys whose current value is not deleted. Expired keys are counted
+// until they are deleted. The counter is initialized by walking the index on the first call, then it's
+// maintained while indexing so further calls are O(1).
+// Transactions not yet indexed are not waited for
+func (s *ImmuStore) KVCount() (uint64, error) {
+	return s.indexer.KVCount()
+}
+
+func (idx *indexer) KVCount() (uint64, error) {
+	idx.kvCountMutex.Lock()
+	defer idx.kvCountMutex.Unlock()
+
+	idx.mutex.Lock()
+	closed := idx.closed
+	idx.mutex.Unlock()
+
+	if closed {
+		return 0, ErrAlreadyClosed
+	}
+
+	if idx.kvCountTracked {
+		return idx.kvCount, nil
+	}
+
+	// no transaction is being indexed while kvCountMutex is held
+	snap, err := idx.SnapshotSince(idx.Ts())
+	if err != nil {
+		return 0, err
+	}
+	defer snap.Close()
+
+	r, err := snap.NewReader(&tbtree.ReaderSpec{})
+	if err != nil {
+		return 0, err
+	}
+	defer r.Close()
+
+	var kvCount uint64
+
+	for {
+		_, indexedVal, tx, hc, err := r.Read()
+		if err == tbtree.ErrNoMoreEntries {
+			break
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		live, err := idx.isLiveValue(tx, hc, indexedVal)
+		if err != nil {
+			return 0, err
+		}
+
+		if live {
+			kvCount++
+		}
+	}
+
+	idx.kvCount = kvCount
+	idx.kvCountTracked = true
+
+	return kvCount, nil
+}
+
+// isLive returns whether key is currently indexed with a value not deleted
+func (idx *indexer) isLive(key []byte) (bool, error) {
+	indexedVal, tx, hc, err := idx.index.Get(key)
+	if err == tbtree.ErrKeyNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return idx.isLiveValue(tx, hc, indexedVal)
+}
+
+func (idx *indexer) isLiveValue(tx, hc uint64, indexedVal []byte) (bool, error) {
+	valRef, err := idx.store.valueRefFrom(tx, hc, indexedVal)
+	if err != nil {
+		return false, err
+	}
+
+	md := valRef.KVMetadata()
+
+	return md == nil || !md.Deleted(), nil
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2022 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package store
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestImmudbStoreKVCount(t *testing.T) {
+	dir := t.TempDir()
+
+	immuStore, err := Open(dir, DefaultOptions())
+	require.NoError(t, err)
+
+	commit := func(fn func(tx *OngoingTx)) {
+		tx, err := immuStore.NewTx()
+		require.NoError(t, err)
+
+		fn(tx)
+
+		hdr, err := tx.Commit()
+		require.NoError(t, err)
+
+		err = immuStore.WaitForIndexingUpto(hdr.ID, nil)
+		require.NoError(t, err)
+	}
+
+	commit(func(tx *OngoingTx) {
+		require.NoError(t, tx.Set([]byte("key1"), nil, []byte("value1")))
+		require.NoError(t, tx.Set([]byte("key2"), nil, []byte("value2")))
+	})
+
+	kvCount, err := immuStore.KVCount()
+	require.NoError(t, err)
+	require.EqualValues(t, 2, kvCount)
+
+	commit(func(tx *OngoingTx) {
+		require.NoError(t, tx.Set([]byte("key1"), nil, []byte("value1_1")))
+		require.NoError(t, tx.Set([]byte("key3"), nil, []byte("value3")))
+		require.NoError(t, tx.Delete([]byte("key2")))
+	})
+
+	kvCount, err = immuStore.KVCount()
+	require.NoError(t, err)
+	require.EqualValues(t, 2, kvCount)
+
+	// a deleted key is counted again once it's set
+	commit(func(tx *OngoingTx) {
+		require.NoError(t, tx.Set([]byte("key2"), nil, []byte("value2_1")))
+	})
+
+	kvCount, err = immuStore.KVCount()
+	require.NoError(t, err)
+	require.EqualValues(t, 3, kvCount)
+
+	err = immuStore.Close()
+	require.NoError(t, err)
+
+	_, err = immuStore.KVCount()
+	require.ErrorIs(t, err, ErrAlreadyClosed)
+
+	immuStore, err = Open(dir, DefaultOptions())
+	require.NoError(t, err)
+	defer immuStore.Close()
+
+	err = immuStore.WaitForIndexingUpto(immuStore.TxCount(), nil)
+	require.NoError(t, err)
+
+	kvCount, err = immuStore.KVCount()
+	require.NoError(t, err)
+	require.EqualValues(t, 3, kvCount)
+}
@@ -0,0 +1,217 @@
+/*
+Copyright 2022 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package store
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"time"
+)
+
+var ErrKeyLeased = errors.New("key already leased")
+var ErrLeaseNotFound = errors.New("lease not found or expired")
+
+// leaseKeyPrefix is prepended to user keys to hold lease entries
+var leaseKeyPrefix = []byte("_lease.")
+
+func leaseKey(key []byte) []byte {
+	lk := make([]byte, len(leaseKeyPrefix)+len(key))
+	copy(lk, leaseKeyPrefix)
+	copy(lk[len(leaseKeyPrefix):], key)
+	return lk
+}
+
+// Lease acquires exclusive temporary ownership of key by committing an expirable entry.
+// The returned lease id is the id of the transaction where the lease was acquired.
+// ErrKeyLeased is returned if there is already a valid lease on the same key.
+// Note: expiration time has a granularity of one second
+func (s *ImmuStore) Lease(key []byte, duration time.Duration) (leaseID uint64, err error) {
+	if len(key) == 0 {
+		return 0, ErrNullKey
+	}
+
+	if duration <= 0 {
+		return 0, ErrIllegalArguments
+	}
+
+	lk := leaseKey(key)
+
+	err = s.WaitForIndexingUpto(s.lastPreCommittedTxID(), nil)
+	if err != nil {
+		return 0, err
+	}
+
+	var precondition Precondition
+
+	// latest entry, it could be deleted or expired
+	valRef, err := s.GetWith(lk)
+	if errors.Is(err, ErrKeyNotFound) {
+		precondition = &PreconditionKeyMustNotExist{Key: lk}
+	} else if err != nil {
+		return 0, err
+	} else {
+		md := valRef.KVMetadata()
+		if md == nil || (!md.Deleted() && !md.ExpiredAt(s.timeFunc())) {
+			return 0, ErrKeyLeased
+		}
+
+		precondition = &PreconditionKeyNotModifiedAfterTx{Key: lk, TxID: valRef.Tx()}
+	}
+
+	hdr, err := s.commitLeaseEntry(lk, duration, false, nil, precondition)
+	if errors.Is(err, ErrPreconditionFailed) {
+		return 0, ErrKeyLeased
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return hdr.ID, nil
+}
+
+// RenewLease extends the expiration of a valid lease to duration from now
+func (s *ImmuStore) RenewLease(leaseID uint64, duration time.Duration) error {
+	if duration <= 0 {
+		return ErrIllegalArguments
+	}
+
+	lk, currTxID, err := s.activeLease(leaseID)
+	if err != nil {
+		return err
+	}
+
+	var b [txIDSize]byte
+	binary.BigEndian.PutUint64(b[:], leaseID)
+
+	_, err = s.commitLeaseEntry(lk, duration, false, b[:], &PreconditionKeyNotModifiedAfterTx{Key: lk, TxID: currTxID})
+	if errors.Is(err, ErrPreconditionFailed) {
+		return ErrLeaseNotFound
+	}
+
+	return err
+}
+
+// ReleaseLease releases a valid lease before its expiration
+func (s *ImmuStore) ReleaseLease(leaseID uint64) error {
+	lk, currTxID, err := s.activeLease(leaseID)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.commitLeaseEntry(lk, 0, true, nil, &PreconditionKeyNotModifiedAfterTx{Key: lk, TxID: currTxID})
+	if errors.Is(err, ErrPreconditionFailed) {
+		return ErrLeaseNotFound
+	}
+
+	return err
+}
+
+// activeLease returns the lease key acquired at leaseID and the id of the
+// transaction where the lease was lastly updated, as long as it's still valid
+func (s *ImmuStore) activeLease(leaseID uint64) (lk []byte, currTxID uint64, err error) {
+	if leaseID == 0 {
+		return nil, 0, ErrLeaseNotFound
+	}
+
+	tx, err := s.fetchAllocTx()
+	if err != nil {
+		return nil, 0, err
+	}
+	defer s.releaseAllocTx(tx)
+
+	err = s.ReadTx(leaseID, tx)
+	if errors.Is(err, ErrTxNotFound) {
+		return nil, 0, ErrLeaseNotFound
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+
+	entries := tx.Entries()
+
+	// lease acquisition is done in a single-entry transaction with an empty value
+	if len(entries) != 1 || entries[0].vLen != 0 || !bytes.HasPrefix(entries[0].key(), leaseKeyPrefix) {
+		return nil, 0, ErrLeaseNotFound
+	}
+
+	lk = entries[0].Key()
+
+	err = s.WaitForIndexingUpto(s.lastPreCommittedTxID(), nil)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	valRef, err := s.Get(lk)
+	if errors.Is(err, ErrKeyNotFound) {
+		return nil, 0, ErrLeaseNotFound
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+
+	currLeaseID := valRef.Tx()
+
+	if valRef.Len() > 0 {
+		val, err := valRef.Resolve()
+		if err != nil {
+			return nil, 0, err
+		}
+
+		if len(val) != txIDSize {
+			return nil, 0, ErrCorruptedData
+		}
+
+		currLeaseID = binary.BigEndian.Uint64(val)
+	}
+
+	if currLeaseID != leaseID {
+		return nil, 0, ErrLeaseNotFound
+	}
+
+	return lk, valRef.Tx(), nil
+}
+
+func (s *ImmuStore) commitLeaseEntry(lk []byte, duration time.Duration, deleted bool, value []byte, precondition Precondition) (*TxHeader, error) {
+	md := NewKVMetadata()
+
+	if deleted {
+		md.AsDeleted(true)
+	} else {
+		md.ExpiresAt(s.timeFunc().Add(duration))
+	}
+
+	tx, err := s.NewWriteOnlyTx()
+	if err != nil {
+		return nil, err
+	}
+
+	tx.reservedKeysAllowed = true
+
+	err = tx.Set(lk, md, value)
+	if err != nil {
+		tx.Cancel()
+		return nil, err
+	}
+
+	err = tx.AddPrecondition(precondition)
+	if err != nil {
+		tx.Cancel()
+		return nil, err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2022 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package store
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestImmudbStoreLease(t *testing.T) {
+	dir, err := ioutil.TempDir("", "data_lease")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	immuStore, err := Open(dir, DefaultOptions())
+	require.NoError(t, err)
+	defer immustoreClose(t, immuStore)
+
+	_, err = immuStore.Lease(nil, time.Minute)
+	require.ErrorIs(t, err, ErrNullKey)
+
+	_, err = immuStore.Lease([]byte("resource1"), 0)
+	require.ErrorIs(t, err, ErrIllegalArguments)
+
+	leaseID, err := immuStore.Lease([]byte("resource1"), time.Minute)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), leaseID)
+
+	_, err = immuStore.Lease([]byte("resource1"), time.Minute)
+	require.ErrorIs(t, err, ErrKeyLeased)
+
+	leaseID2, err := immuStore.Lease([]byte("resource2"), time.Minute)
+	require.NoError(t, err)
+
+	err = immuStore.RenewLease(leaseID, 0)
+	require.ErrorIs(t, err, ErrIllegalArguments)
+
+	err = immuStore.RenewLease(0, time.Minute)
+	require.ErrorIs(t, err, ErrLeaseNotFound)
+
+	err = immuStore.RenewLease(100, time.Minute)
+	require.ErrorIs(t, err, ErrLeaseNotFound)
+
+	err = immuStore.RenewLease(leaseID, 2*time.Minute)
+	require.NoError(t, err)
+
+	// renewal transactions are not lease ids
+	err = immuStore.RenewLease(immuStore.TransactionCount(), time.Minute)
+	require.ErrorIs(t, err, ErrLeaseNotFound)
+
+	_, err = immuStore.Lease([]byte("resource1"), time.Minute)
+	require.ErrorIs(t, err, ErrKeyLeased)
+
+	err = immuStore.ReleaseLease(leaseID)
+	require.NoError(t, err)
+
+	err = immuStore.ReleaseLease(leaseID)
+	require.ErrorIs(t, err, ErrLeaseNotFound)
+
+	err = immuStore.RenewLease(leaseID, time.Minute)
+	require.ErrorIs(t, err, ErrLeaseNotFound)
+
+	leaseID3, err := immuStore.Lease([]byte("resource1"), time.Minute)
+	require.NoError(t, err)
+	require.NotEqual(t, leaseID, leaseID3)
+
+	err = immuStore.ReleaseLease(leaseID2)
+	require.NoError(t, err)
+
+	t.Run("lease entries should not be written by users", func(t *testing.T) {
+		tx, err := immuStore.NewWriteOnlyTx()
+		require.NoError(t, err)
+		defer tx.Cancel()
+
+		err = tx.Set(leaseKey([]byte("resource1")), nil, nil)
+		require.ErrorIs(t, err, ErrReservedKey)
+
+		_, err = immuStore.CommitWith(func(txID uint64, index KeyIndex) ([]*EntrySpec, []Precondition, error) {
+			return []*EntrySpec{{Key: leaseKey([]byte("resource1"))}}, nil, nil
+		}, false)
+		require.ErrorIs(t, err, ErrReservedKey)
+	})
+
+	t.Run("lease expiration should follow the store clock", func(t *testing.T) {
+		now := time.Now()
+
+		err := immuStore.UseTimeFunc(func() time.Time { return now })
+		require.NoError(t, err)
+		defer immuStore.UseTimeFunc(time.Now)
+
+		_, err = immuStore.Lease([]byte("resource4"), time.Minute)
+		require.NoError(t, err)
+
+		_, err = immuStore.Lease([]byte("resource4"), time.Minute)
+		require.ErrorIs(t, err, ErrKeyLeased)
+
+		now = now.Add(2 * time.Minute)
+
+		_, err = immuStore.Lease([]byte("resource4"), time.Minute)
+		require.NoError(t, err)
+	})
+
+	t.Run("expired leases should not be renewed", func(t *testing.T) {
+		leaseID, err := immuStore.Lease([]byte("resource3"), time.Second)
+		require.NoError(t, err)
+
+		time.Sleep(2 * time.Second)
+
+		err = immuStore.RenewLease(leaseID, time.Minute)
+		require.ErrorIs(t, err, ErrLeaseNotFound)
+
+		_, err = immuStore.Lease([]byte("resource3"), time.Minute)
+		require.NoError(t, err)
+	})
+}
@@ -0,0 +1,272 @@
+/*
+Copyright 2022 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package store
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+
+	"github.com/codenotary/immudb/embedded/watchers"
+)
+
+// KVEvent describes a change made to a key by a committed transaction
+type KVEvent struct {
+	Key      []byte
+	Value    []byte
+	Metadata *KVMetadata
+	TxID     uint64
+}
+
+// ObserveKey blocks until a transaction with an id greater than afterTxID modifying key is committed.
+// The entry written by the first of such transactions is returned, deletions included.
+// The context error is returned if it gets cancelled before such transaction is committed
+func (s *ImmuStore) ObserveKey(ctx context.Context, key []byte, afterTxID uint64) (*KVEvent, error) {
+	if ctx == nil {
+		return nil, ErrIllegalArguments
+	}
+
+	if len(key) == 0 {
+		return nil, ErrNullKey
+	}
+
+	txID := afterTxID + 1
+
+	for {
+		err := s.observersWHub.WaitFor(txID, ctx.Done())
+		if err == watchers.ErrCancellationRequested {
+			return nil, ctx.Err()
+		}
+		if err == watchers.ErrAlreadyClosed {
+			return nil, ErrAlreadyClosed
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		for lastTxID := s.lastCommittedTxID(); txID <= lastTxID; txID++ {
+			e, _, err := s.ReadTxEntry(txID, key)
+			if err == ErrKeyNotFound {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+
+			val := make([]byte, e.vLen)
+
+			_, err = s.readValueAt(val, e.vOff, e.hVal)
+			if err != nil {
+				return nil, err
+			}
+
+			k := make([]byte, len(key))
+			copy(k, key)
+
+			return &KVEvent{
+				Key:      k,
+				Value:    val,
+				Metadata: e.md,
+				TxID:     txID,
+			}, nil
+		}
+	}
+}
+
+// CommitLogEvent describes a committed transaction
+type CommitLogEvent struct {
+	TxID     uint64
+	Alh      [sha256.Size]byte
+	Ts       int64
+	NEntries int
+}
+
+// WatchCommitLog delivers an event for each transaction committed after the call, in commit order.
+// Only transaction headers are read, so events are delivered as soon as transactions are committed.
+// The channel is closed when the context gets cancelled, the store is closed or headers can not be read
+func (s *ImmuStore) WatchCommitLog(ctx context.Context) (<-chan CommitLogEvent, error) {
+	if ctx == nil {
+		return nil, ErrIllegalArguments
+	}
+
+	s.mutex.Lock()
+	closed := s.closed
+	s.mutex.Unlock()
+
+	if closed {
+		return nil, ErrAlreadyClosed
+	}
+
+	ch := make(chan CommitLogEvent)
+
+	txID := s.lastCommittedTxID() + 1
+
+	go func() {
+		defer close(ch)
+
+		for {
+			err := s.observersWHub.WaitFor(txID, ctx.Done())
+			if err != nil {
+				return
+			}
+
+			for lastTxID := s.lastCommittedTxID(); txID <= lastTxID; txID++ {
+				hdr, err := s.ReadTxHeader(txID)
+				if err != nil {
+					if ctx.Err() == nil {
+						s.logger.Warningf("watching commit log stopped at tx %d due to error: %v", txID, err)
+					}
+					return
+				}
+
+				select {
+				case ch <- CommitLogEvent{
+					TxID:     hdr.ID,
+					Alh:      hdr.Alh(),
+					Ts:       hdr.Ts,
+					NEntries: hdr.NEntries,
+				}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return ch, nil
+}
+
+// watchPrefixMaxPendingBatches bounds the number of batches read ahead of the consumer by WatchPrefix
+const watchPrefixMaxPendingBatches = 64
+
+// errPendingBatchesLimitReached stops reading transactions until pending batches are delivered
+var errPendingBatchesLimitReached = errors.New("pending batches limit reached")
+
+// PrefixBatch holds the changes made by a transaction to the keys watched by WatchPrefix.
+// When Err is set no changes are included and no further batches are delivered
+type PrefixBatch struct {
+	Events []*KVEvent
+	Err    error
+}
+
+// WatchPrefix delivers the changes made to keys starting with prefix by each transaction with an
+// id greater than afterTxID. Changes are delivered in batches, one per transaction, so changes made
+// by the same transaction become visible at once. Transactions not modifying any of such keys are skipped.
+// At most a bounded number of batches is read ahead of the consumer.
+// The channel is closed when the context gets cancelled or the store is closed. If changes can not be read,
+// e.g. the max concurrency limit is reached, a last batch with the error is delivered before closing the channel
+func (s *ImmuStore) WatchPrefix(ctx context.Context, prefix []byte, afterTxID uint64) (<-chan PrefixBatch, error) {
+	if ctx == nil {
+		return nil, ErrIllegalArguments
+	}
+
+	s.mutex.Lock()
+	closed := s.closed
+	s.mutex.Unlock()
+
+	if closed {
+		return nil, ErrAlreadyClosed
+	}
+
+	p := make([]byte, len(prefix))
+	copy(p, prefix)
+
+	ch := make(chan PrefixBatch)
+
+	go func() {
+		defer close(ch)
+
+		txID := afterTxID + 1
+
+		for {
+			err := s.observersWHub.WaitFor(txID, ctx.Done())
+			if err != nil {
+				return
+			}
+
+			var batches [][]*KVEvent
+
+			err = s.ForEachTx(ctx, txID, func(tx *Tx) error {
+				batch, err := s.prefixEvents(tx, p)
+				if err != nil {
+					return err
+				}
+
+				txID = tx.header.ID + 1
+
+				if len(batch) > 0 {
+					batches = append(batches, batch)
+				}
+
+				if len(batches) == watchPrefixMaxPendingBatches {
+					return errPendingBatchesLimitReached
+				}
+
+				return nil
+			})
+			if err == errPendingBatchesLimitReached {
+				err = nil
+			}
+
+			for _, batch := range batches {
+				select {
+				case ch <- PrefixBatch{Events: batch}:
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			if err != nil {
+				if ctx.Err() == nil {
+					select {
+					case ch <- PrefixBatch{Err: err}:
+					case <-ctx.Done():
+					}
+				}
+				return
+			}
+		}
+	}()
+
+	return ch, nil
+}
+
+func (s *ImmuStore) prefixEvents(tx *Tx, prefix []byte) ([]*KVEvent, error) {
+	var events []*KVEvent
+
+	for _, e := range tx.Entries() {
+		if !bytes.HasPrefix(e.key(), prefix) {
+			continue
+		}
+
+		val := make([]byte, e.vLen)
+
+		_, err := s.readValueAt(val, e.vOff, e.hVal)
+		if err != nil {
+			return nil, err
+		}
+
+		events = append(events, &KVEvent{
+			Key:      e.Key(),
+			Value:    val,
+			Metadata: e.md,
+			TxID:     tx.header.ID,
+		})
+	}
+
+	return events, nil
+}
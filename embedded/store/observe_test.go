@@ -0,0 +1,305 @@
+/*
+Copyright 2022 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package store
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestObserveKey(t *testing.T) {
+	dir, err := ioutil.TempDir("", "data_observe_key")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	immuStore, err := Open(dir, DefaultOptions())
+	require.NoError(t, err)
+
+	set := func(key, value []byte) uint64 {
+		tx, err := immuStore.NewWriteOnlyTx()
+		require.NoError(t, err)
+
+		err = tx.Set(key, nil, value)
+		require.NoError(t, err)
+
+		hdr, err := tx.Commit()
+		require.NoError(t, err)
+
+		return hdr.ID
+	}
+
+	_, err = immuStore.ObserveKey(nil, []byte("key"), 0)
+	require.ErrorIs(t, err, ErrIllegalArguments)
+
+	_, err = immuStore.ObserveKey(context.Background(), nil, 0)
+	require.ErrorIs(t, err, ErrNullKey)
+
+	txID := set([]byte("key"), []byte("value1"))
+
+	t.Run("already committed changes should be returned right away", func(t *testing.T) {
+		ev, err := immuStore.ObserveKey(context.Background(), []byte("key"), 0)
+		require.NoError(t, err)
+		require.Equal(t, txID, ev.TxID)
+		require.Equal(t, []byte("key"), ev.Key)
+		require.Equal(t, []byte("value1"), ev.Value)
+	})
+
+	t.Run("observation should be cancelled with the context", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		_, err := immuStore.ObserveKey(ctx, []byte("key"), txID)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("concurrent observers should be notified", func(t *testing.T) {
+		observers := 2000
+
+		var wg sync.WaitGroup
+		wg.Add(observers)
+
+		events := make(chan *KVEvent, observers)
+
+		for i := 0; i < observers; i++ {
+			go func() {
+				defer wg.Done()
+
+				ev, err := immuStore.ObserveKey(context.Background(), []byte("key"), txID)
+				require.NoError(t, err)
+
+				events <- ev
+			}()
+		}
+
+		set([]byte("other-key"), []byte("value"))
+		expectedTxID := set([]byte("key"), []byte("value2"))
+
+		wg.Wait()
+		close(events)
+
+		for ev := range events {
+			require.Equal(t, expectedTxID, ev.TxID)
+			require.Equal(t, []byte("value2"), ev.Value)
+		}
+	})
+
+	t.Run("observers should be released when the store is closed", func(t *testing.T) {
+		errCh := make(chan error)
+
+		go func() {
+			_, err := immuStore.ObserveKey(context.Background(), []byte("key"), txID+10)
+			errCh <- err
+		}()
+
+		time.Sleep(10 * time.Millisecond)
+
+		err := immuStore.Close()
+		require.NoError(t, err)
+
+		require.ErrorIs(t, <-errCh, ErrAlreadyClosed)
+	})
+}
+
+func TestWatchPrefix(t *testing.T) {
+	dir, err := ioutil.TempDir("", "data_watch_prefix")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	immuStore, err := Open(dir, DefaultOptions())
+	require.NoError(t, err)
+
+	set := func(kvs ...string) uint64 {
+		tx, err := immuStore.NewWriteOnlyTx()
+		require.NoError(t, err)
+
+		for i := 0; i < len(kvs); i += 2 {
+			err = tx.Set([]byte(kvs[i]), nil, []byte(kvs[i+1]))
+			require.NoError(t, err)
+		}
+
+		hdr, err := tx.Commit()
+		require.NoError(t, err)
+
+		return hdr.ID
+	}
+
+	_, err = immuStore.WatchPrefix(nil, []byte("dir/"), 0)
+	require.ErrorIs(t, err, ErrIllegalArguments)
+
+	tx1 := set("dir/key1", "value1", "other/key1", "value1")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch, err := immuStore.WatchPrefix(ctx, []byte("dir/"), 0)
+	require.NoError(t, err)
+
+	batch := <-ch
+	require.NoError(t, batch.Err)
+	require.Len(t, batch.Events, 1)
+	require.Equal(t, tx1, batch.Events[0].TxID)
+	require.Equal(t, []byte("dir/key1"), batch.Events[0].Key)
+	require.Equal(t, []byte("value1"), batch.Events[0].Value)
+
+	set("other/key2", "value2")
+	tx3 := set("dir/key2", "value2", "dir/key3", "value3", "other/key3", "value3")
+
+	batch = <-ch
+	require.NoError(t, batch.Err)
+	require.Len(t, batch.Events, 2)
+	require.Equal(t, tx3, batch.Events[0].TxID)
+	require.Equal(t, []byte("dir/key2"), batch.Events[0].Key)
+	require.Equal(t, tx3, batch.Events[1].TxID)
+	require.Equal(t, []byte("dir/key3"), batch.Events[1].Key)
+
+	t.Run("batches exceeding the pending limit should be delivered in order", func(t *testing.T) {
+		ch, err := immuStore.WatchPrefix(ctx, []byte("many/"), tx3)
+		require.NoError(t, err)
+
+		var txs []uint64
+		for i := 0; i < 2*watchPrefixMaxPendingBatches+1; i++ {
+			txs = append(txs, set("many/key", "value"))
+		}
+
+		for _, txID := range txs {
+			batch := <-ch
+			require.NoError(t, batch.Err)
+			require.Len(t, batch.Events, 1)
+			require.Equal(t, txID, batch.Events[0].TxID)
+		}
+	})
+
+	t.Run("read errors should be delivered before closing the channel", func(t *testing.T) {
+		var txHolders []*Tx
+
+		for {
+			tx, err := immuStore.fetchAllocTx()
+			if err != nil {
+				require.ErrorIs(t, err, ErrMaxConcurrencyLimitExceeded)
+				break
+			}
+			txHolders = append(txHolders, tx)
+		}
+
+		ch, err := immuStore.WatchPrefix(context.Background(), []byte("dir/"), 0)
+		require.NoError(t, err)
+
+		batch := <-ch
+		require.ErrorIs(t, batch.Err, ErrMaxConcurrencyLimitExceeded)
+		require.Empty(t, batch.Events)
+
+		_, ok := <-ch
+		require.False(t, ok)
+
+		for _, tx := range txHolders {
+			immuStore.releaseAllocTx(tx)
+		}
+	})
+
+	t.Run("channel should be closed when the context is cancelled", func(t *testing.T) {
+		cancel()
+
+		_, ok := <-ch
+		require.False(t, ok)
+	})
+
+	t.Run("channel should be closed when the store is closed", func(t *testing.T) {
+		ch, err := immuStore.WatchPrefix(context.Background(), []byte("dir/"), tx3)
+		require.NoError(t, err)
+
+		err = immuStore.Close()
+		require.NoError(t, err)
+
+		_, ok := <-ch
+		require.False(t, ok)
+
+		_, err = immuStore.WatchPrefix(context.Background(), []byte("dir/"), 0)
+		require.ErrorIs(t, err, ErrAlreadyClosed)
+	})
+}
+
+func TestWatchCommitLog(t *testing.T) {
+	dir, err := ioutil.TempDir("", "data_watch_commit_log")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	immuStore, err := Open(dir, DefaultOptions())
+	require.NoError(t, err)
+
+	commit := func(entries int) *TxHeader {
+		tx, err := immuStore.NewWriteOnlyTx()
+		require.NoError(t, err)
+
+		for i := 0; i < entries; i++ {
+			err = tx.Set([]byte{byte(i)}, nil, []byte{byte(i)})
+			require.NoError(t, err)
+		}
+
+		hdr, err := tx.Commit()
+		require.NoError(t, err)
+
+		return hdr
+	}
+
+	_, err = immuStore.WatchCommitLog(nil)
+	require.ErrorIs(t, err, ErrIllegalArguments)
+
+	// transactions committed before watching are not delivered
+	commit(1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch, err := immuStore.WatchCommitLog(ctx)
+	require.NoError(t, err)
+
+	for i := 1; i <= 3; i++ {
+		hdr := commit(i)
+
+		event := <-ch
+		require.Equal(t, hdr.ID, event.TxID)
+		require.Equal(t, hdr.Alh(), event.Alh)
+		require.Equal(t, hdr.Ts, event.Ts)
+		require.Equal(t, i, event.NEntries)
+	}
+
+	t.Run("channel should be closed when the context is cancelled", func(t *testing.T) {
+		cancel()
+
+		_, ok := <-ch
+		require.False(t, ok)
+	})
+
+	t.Run("channel should be closed when the store is closed", func(t *testing.T) {
+		ch, err := immuStore.WatchCommitLog(context.Background())
+		require.NoError(t, err)
+
+		err = immuStore.Close()
+		require.NoError(t, err)
+
+		_, ok := <-ch
+		require.False(t, ok)
+
+		_, err = immuStore.WatchCommitLog(context.Background())
+		require.ErrorIs(t, err, ErrAlreadyClosed)
+	})
+}
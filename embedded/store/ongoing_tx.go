@@ -16,6 +16,7 @@ limitations under the License.
 package store
 
 import (
+	"bytes"
 	"crypto/sha256"
 	"fmt"
 	"time"
@@ -36,6 +37,12 @@ type OngoingTx struct {
 
 	ts time.Time
 
+	// when set, it's used as the timestamp of the committed transaction
+	fixedTs int64
+
+	// when set, keys under prefixes reserved by the store (leases, sequences) can be written
+	reservedKeysAllowed bool
+
 	closed bool
 }
 
@@ -45,6 +52,32 @@ type EntrySpec struct {
 	Value    []byte
 }
 
+// Validate checks the entry can be committed into a store opened with opts.
+// Nil values are accepted as they are used by deleted entries and by entries with no payload
+func (e *EntrySpec) Validate(opts *Options) error {
+	if opts == nil {
+		return ErrIllegalArguments
+	}
+
+	return e.validate(opts.MaxKeyLen, opts.MaxValueLen)
+}
+
+func (e *EntrySpec) validate(maxKeyLen, maxValueLen int) error {
+	if len(e.Key) == 0 {
+		return ErrNullKey
+	}
+
+	if len(e.Key) > maxKeyLen {
+		return ErrorMaxKeyLenExceeded
+	}
+
+	if len(e.Value) > maxValueLen {
+		return ErrorMaxValueLenExceeded
+	}
+
+	return nil
+}
+
 func newWriteOnlyTx(s *ImmuStore) (*OngoingTx, error) {
 	return &OngoingTx{
 		st:           s,
@@ -162,6 +195,10 @@ func (tx *OngoingTx) Set(key []byte, md *KVMetadata, value []byte) error {
 		return ErrorMaxValueLenExceeded
 	}
 
+	if !tx.reservedKeysAllowed && isReservedKey(key) {
+		return ErrReservedKey
+	}
+
 	kid := sha256.Sum256(key)
 	keyRef, isKeyUpdate := tx.entriesByKey[kid]
 
@@ -196,6 +233,12 @@ func (tx *OngoingTx) Set(key []byte, md *KVMetadata, value []byte) error {
 	return nil
 }
 
+// isReservedKey returns true if key belongs to a namespace managed by the store itself,
+// such keys can not be written by users as that would allow to forge leases or sequence values
+func isReservedKey(key []byte) bool {
+	return bytes.HasPrefix(key, leaseKeyPrefix) || bytes.HasPrefix(key, sequenceKeyPrefix)
+}
+
 func (tx *OngoingTx) AddPrecondition(c Precondition) error {
 	if tx.closed {
 		return ErrAlreadyClosed
@@ -88,3 +88,46 @@ func (i *dummyKeyIndex) GetWith(key []byte, filters ...FilterFn) (valRef ValueRe
 
 	return nil, ErrKeyNotFound
 }
+
+func TestEntrySpecValidate(t *testing.T) {
+	opts := DefaultOptions().WithMaxKeyLen(4).WithMaxValueLen(8)
+
+	e := &EntrySpec{Key: []byte("key"), Value: []byte("value")}
+	require.NoError(t, e.Validate(opts))
+
+	require.ErrorIs(t, e.Validate(nil), ErrIllegalArguments)
+
+	require.ErrorIs(t, (&EntrySpec{}).Validate(opts), ErrNullKey)
+	require.ErrorIs(t, (&EntrySpec{Key: []byte{}}).Validate(opts), ErrNullKey)
+	require.ErrorIs(t, (&EntrySpec{Key: []byte("key12")}).Validate(opts), ErrorMaxKeyLenExceeded)
+	require.ErrorIs(t, (&EntrySpec{Key: []byte("key"), Value: []byte("value1234")}).Validate(opts), ErrorMaxValueLenExceeded)
+
+	// deleted entries have no value
+	require.NoError(t, (&EntrySpec{Key: []byte("key")}).Validate(opts))
+}
+
+func TestValidateEntriesReportsAllErrors(t *testing.T) {
+	st := &ImmuStore{
+		maxTxEntries: 10,
+		maxKeyLen:    4,
+		maxValueLen:  8,
+	}
+
+	err := st.validateEntries([]*EntrySpec{
+		{Key: []byte("key")},
+		{Key: nil},
+	})
+	require.Equal(t, ErrNullKey, err)
+
+	err = st.validateEntries([]*EntrySpec{
+		{Key: []byte("key")},
+		{Key: nil},
+		{Key: []byte("key12")},
+		{Key: []byte("key"), Value: []byte("value1234")},
+		{Key: []byte("key")},
+	})
+	require.ErrorIs(t, err, ErrNullKey)
+	require.ErrorIs(t, err, ErrorMaxKeyLenExceeded)
+	require.ErrorIs(t, err, ErrorMaxValueLenExceeded)
+	require.ErrorIs(t, err, ErrDuplicatedKey)
+}
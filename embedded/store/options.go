@@ -45,6 +45,7 @@ const DefaultVLogMaxOpenedFiles = 10
 const DefaultTxLogMaxOpenedFiles = 10
 const DefaultCommitLogMaxOpenedFiles = 10
 const DefaultWriteTxHeaderVersion = MaxTxHeaderVersion
+const DefaultMaxIncrementRetries = 10
 
 const MaxFileSize = (1 << 31) - 1 // 2Gb
 
@@ -61,6 +62,8 @@ type Options struct {
 	Synced        bool
 	SyncFrequency time.Duration
 
+	UseFlock bool
+
 	FileMode os.FileMode
 	logger   logger.Logger
 
@@ -75,13 +78,22 @@ type Options struct {
 
 	TxLogCacheSize int
 
+	// VLogCacheSize is the number of value log pages kept in memory, the cache is disabled when zero
+	VLogCacheSize int
+
 	VLogMaxOpenedFiles      int
+	VLogMmapSize            int64
 	TxLogMaxOpenedFiles     int
 	CommitLogMaxOpenedFiles int
 	WriteTxHeaderVersion    int
 
 	MaxWaitees int
 
+	MaxIncrementRetries int
+
+	// OverwriteOnRename allows RenameKey to replace the value of an existing destination key
+	OverwriteOnRename bool
+
 	TimeFunc TimeFunc
 
 	// options below are only set during initialization and stored as metadata
@@ -113,6 +125,11 @@ type IndexOptions struct {
 	NodesLogMaxOpenedFiles   int
 	HistoryLogMaxOpenedFiles int
 	CommitLogMaxOpenedFiles  int
+
+	// FlushTxThld forces the index to be flushed every FlushTxThld indexed transactions, so at most
+	// FlushTxThld transactions are indexed again when reopening after a crash. Zero if disabled.
+	// Note: such flushes wait for an ongoing index compaction, pausing indexing until it completes
+	FlushTxThld int
 }
 
 type AHTOptions struct {
@@ -141,6 +158,8 @@ func DefaultOptions() *Options {
 
 		MaxWaitees: DefaultMaxWaitees,
 
+		MaxIncrementRetries: DefaultMaxIncrementRetries,
+
 		TimeFunc: func() time.Time {
 			return time.Now()
 		},
@@ -212,6 +231,9 @@ func (opts *Options) Validate() error {
 	if opts.VLogMaxOpenedFiles <= 0 {
 		return fmt.Errorf("%w: invalid VLogMaxOpenedFiles", ErrInvalidOptions)
 	}
+	if opts.VLogMmapSize < 0 {
+		return fmt.Errorf("%w: invalid VLogMmapSize", ErrInvalidOptions)
+	}
 	if opts.TxLogMaxOpenedFiles <= 0 {
 		return fmt.Errorf("%w: invalid TxLogMaxOpenedFiles", ErrInvalidOptions)
 	}
@@ -223,10 +245,18 @@ func (opts *Options) Validate() error {
 		return fmt.Errorf("%w: invalid TxLogCacheSize", ErrInvalidOptions)
 	}
 
+	if opts.VLogCacheSize < 0 {
+		return fmt.Errorf("%w: invalid VLogCacheSize", ErrInvalidOptions)
+	}
+
 	if opts.MaxWaitees < 0 {
 		return fmt.Errorf("%w: invalid MaxWaitees", ErrInvalidOptions)
 	}
 
+	if opts.MaxIncrementRetries < 0 {
+		return fmt.Errorf("%w: invalid MaxIncrementRetries", ErrInvalidOptions)
+	}
+
 	if opts.TimeFunc == nil {
 		return fmt.Errorf("%w: invalid TimeFunc", ErrInvalidOptions)
 	}
@@ -306,6 +336,9 @@ func (opts *IndexOptions) Validate() error {
 	if opts.CommitLogMaxOpenedFiles <= 0 {
 		return fmt.Errorf("%w: invalid index option CommitLogMaxOpenedFiles", ErrInvalidOptions)
 	}
+	if opts.FlushTxThld < 0 {
+		return fmt.Errorf("%w: invalid index option FlushTxThld", ErrInvalidOptions)
+	}
 
 	return nil
 }
@@ -331,6 +364,11 @@ func (opts *Options) WithSynced(synced bool) *Options {
 	return opts
 }
 
+func (opts *Options) WithUseFlock(useFlock bool) *Options {
+	opts.UseFlock = useFlock
+	return opts
+}
+
 func (opts *Options) WithSyncFrequency(frequency time.Duration) *Options {
 	opts.SyncFrequency = frequency
 	return opts
@@ -396,6 +434,12 @@ func (opts *Options) WithTxLogCacheSize(txLogCacheSize int) *Options {
 	return opts
 }
 
+// WithVLogCacheSize sets the number of value log pages kept in memory, see VLogCachePageSize
+func (opts *Options) WithVLogCacheSize(vLogCacheSize int) *Options {
+	opts.VLogCacheSize = vLogCacheSize
+	return opts
+}
+
 func (opts *Options) WithFileSize(fileSize int) *Options {
 	opts.FileSize = fileSize
 	return opts
@@ -406,6 +450,13 @@ func (opts *Options) WithVLogMaxOpenedFiles(vLogMaxOpenedFiles int) *Options {
 	return opts
 }
 
+// WithVLogMmapSize makes reads within the first vLogMmapSize bytes of each uncompressed value log file
+// to be served from memory mapped regions, zero disables memory mapping
+func (opts *Options) WithVLogMmapSize(vLogMmapSize int64) *Options {
+	opts.VLogMmapSize = vLogMmapSize
+	return opts
+}
+
 func (opts *Options) WithTxLogMaxOpenedFiles(txLogMaxOpenedFiles int) *Options {
 	opts.TxLogMaxOpenedFiles = txLogMaxOpenedFiles
 	return opts
@@ -421,6 +472,16 @@ func (opts *Options) WithMaxWaitees(maxWaitees int) *Options {
 	return opts
 }
 
+func (opts *Options) WithMaxIncrementRetries(maxIncrementRetries int) *Options {
+	opts.MaxIncrementRetries = maxIncrementRetries
+	return opts
+}
+
+func (opts *Options) WithOverwriteOnRename(overwriteOnRename bool) *Options {
+	opts.OverwriteOnRename = overwriteOnRename
+	return opts
+}
+
 func (opts *Options) WithTimeFunc(timeFunc TimeFunc) *Options {
 	opts.TimeFunc = timeFunc
 	return opts
@@ -518,6 +579,11 @@ func (opts *IndexOptions) WithCommitLogMaxOpenedFiles(commitLogMaxOpenedFiles in
 	return opts
 }
 
+func (opts *IndexOptions) WithFlushTxThld(flushTxThld int) *IndexOptions {
+	opts.FlushTxThld = flushTxThld
+	return opts
+}
+
 // AHTOptions
 
 func (opts *AHTOptions) WithSyncThld(syncThld int) *AHTOptions {
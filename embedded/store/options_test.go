@@ -38,12 +38,15 @@ func TestInvalidOptions(t *testing.T) {
 		{"MaxIOConcurrency-max", DefaultOptions().WithMaxIOConcurrency(MaxParallelIO + 1)},
 		{"MaxLinearProofLen", DefaultOptions().WithMaxLinearProofLen(-1)},
 		{"TxLogCacheSize", DefaultOptions().WithTxLogCacheSize(-1)},
+		{"VLogCacheSize", DefaultOptions().WithVLogCacheSize(-1)},
 		{"VLogMaxOpenedFiles", DefaultOptions().WithVLogMaxOpenedFiles(0)},
+		{"VLogMmapSize", DefaultOptions().WithVLogMmapSize(-1)},
 		{"TxLogMaxOpenedFiles", DefaultOptions().WithTxLogMaxOpenedFiles(0)},
 		{"CommitLogMaxOpenedFiles", DefaultOptions().WithCommitLogMaxOpenedFiles(0)},
 		{"WriteTxHeaderVersion", DefaultOptions().WithWriteTxHeaderVersion(-1)},
 		{"WriteTxHeaderVersion-max", DefaultOptions().WithWriteTxHeaderVersion(MaxTxHeaderVersion + 1)},
 		{"MaxWaitees", DefaultOptions().WithMaxWaitees(-1)},
+		{"MaxIncrementRetries", DefaultOptions().WithMaxIncrementRetries(-1)},
 		{"TimeFunc", DefaultOptions().WithTimeFunc(nil)},
 		{"MaxTxEntries", DefaultOptions().WithMaxTxEntries(0)},
 		{"MaxKeyLen", DefaultOptions().WithMaxKeyLen(0)},
@@ -79,6 +82,7 @@ func TestInvalidIndexOptions(t *testing.T) {
 		{"NodesLogMaxOpenedFiles", DefaultIndexOptions().WithNodesLogMaxOpenedFiles(0)},
 		{"HistoryLogMaxOpenedFiles", DefaultIndexOptions().WithHistoryLogMaxOpenedFiles(0)},
 		{"CommitLogMaxOpenedFiles", DefaultIndexOptions().WithCommitLogMaxOpenedFiles(0)},
+		{"FlushTxThld", DefaultIndexOptions().WithFlushTxThld(-1)},
 	} {
 		t.Run(d.n, func(t *testing.T) {
 			require.ErrorIs(t, d.opts.Validate(), ErrInvalidOptions)
@@ -115,6 +119,7 @@ func TestValidOptions(t *testing.T) {
 	require.Equal(t, DefaultFileMode, opts.WithFileMode(DefaultFileMode).FileMode)
 	require.Equal(t, DefaultFileSize, opts.WithFileSize(DefaultFileSize).FileSize)
 	require.Equal(t, DefaultSyncFrequency, opts.WithSyncFrequency(DefaultSyncFrequency).SyncFrequency)
+	require.True(t, opts.WithUseFlock(true).UseFlock)
 	require.Equal(t, DefaultMaxActiveTransactions, opts.WithMaxActiveTransactions(DefaultMaxActiveTransactions).MaxActiveTransactions)
 	require.Equal(t, DefaultMaxIOConcurrency, opts.WithMaxIOConcurrency(DefaultMaxIOConcurrency).MaxIOConcurrency)
 	require.Equal(t, DefaultMaxKeyLen, opts.WithMaxKeyLen(DefaultMaxKeyLen).MaxKeyLen)
@@ -123,8 +128,12 @@ func TestValidOptions(t *testing.T) {
 	require.Equal(t, DefaultMaxValueLen, opts.WithMaxValueLen(DefaultMaxValueLen).MaxValueLen)
 	require.Equal(t, DefaultTxLogCacheSize, opts.WithTxLogCacheSize(DefaultOptions().TxLogCacheSize).TxLogCacheSize)
 	require.Equal(t, 2, opts.WithTxLogMaxOpenedFiles(2).TxLogMaxOpenedFiles)
+	require.Equal(t, 10, opts.WithVLogCacheSize(10).VLogCacheSize)
 	require.Equal(t, 3, opts.WithVLogMaxOpenedFiles(3).VLogMaxOpenedFiles)
+	require.Equal(t, int64(1<<20), opts.WithVLogMmapSize(1<<20).VLogMmapSize)
 	require.Equal(t, DefaultMaxWaitees, opts.WithMaxWaitees(DefaultMaxWaitees).MaxWaitees)
+	require.Equal(t, DefaultMaxIncrementRetries, opts.WithMaxIncrementRetries(DefaultMaxIncrementRetries).MaxIncrementRetries)
+	require.True(t, opts.WithOverwriteOnRename(true).OverwriteOnRename)
 
 	timeFun := func() time.Time {
 		return time.Now()
@@ -178,6 +187,7 @@ func TestValidOptions(t *testing.T) {
 	require.Equal(t, 10, indexOpts.WithNodesLogMaxOpenedFiles(10).NodesLogMaxOpenedFiles)
 	require.Equal(t, 11, indexOpts.WithHistoryLogMaxOpenedFiles(11).HistoryLogMaxOpenedFiles)
 	require.Equal(t, 12, indexOpts.WithCommitLogMaxOpenedFiles(12).CommitLogMaxOpenedFiles)
+	require.Equal(t, 13, indexOpts.WithFlushTxThld(13).FlushTxThld)
 	require.Equal(t, 3, indexOpts.WithCompactionThld(3).CompactionThld)
 	require.Equal(t, 1*time.Millisecond, indexOpts.WithDelayDuringCompaction(1*time.Millisecond).DelayDuringCompaction)
 	require.Equal(t, 4096*2, indexOpts.WithFlushBufferSize(4096*2).FlushBufferSize)
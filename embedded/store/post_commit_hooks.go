@@ -0,0 +1,85 @@
+/*
+Copyright 2022 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package store
+
+import (
+	"crypto/sha256"
+	"errors"
+)
+
+var ErrHookNotFound = errors.New("hook not found")
+
+type HookID uint64
+
+type postCommitHook struct {
+	id HookID
+	fn func(txID uint64, alh [sha256.Size]byte)
+}
+
+// RegisterOnPostCommitHook registers h to be called once each transaction is committed, hooks are called
+// in registration order and transactions in commit order.
+// Hooks are called from the commit goroutine before waiting commits are released and while the commit state
+// is locked, thus they must not block nor call back into the store, WatchCommitLog should be used instead for further processing
+func (s *ImmuStore) RegisterOnPostCommitHook(h func(txID uint64, alh [sha256.Size]byte)) (HookID, error) {
+	if h == nil {
+		return 0, ErrIllegalArguments
+	}
+
+	s.postCommitHooksMutex.Lock()
+	defer s.postCommitHooksMutex.Unlock()
+
+	s.lastPostCommitHookID++
+
+	// the slice is replaced so hooks being called are not affected
+	hooks := make([]postCommitHook, len(s.postCommitHooks), len(s.postCommitHooks)+1)
+	copy(hooks, s.postCommitHooks)
+
+	s.postCommitHooks = append(hooks, postCommitHook{id: s.lastPostCommitHookID, fn: h})
+
+	return s.lastPostCommitHookID, nil
+}
+
+// UnregisterOnPostCommitHook removes the hook identified by id, it's not called for transactions committed afterwards
+func (s *ImmuStore) UnregisterOnPostCommitHook(id HookID) error {
+	s.postCommitHooksMutex.Lock()
+	defer s.postCommitHooksMutex.Unlock()
+
+	for i, h := range s.postCommitHooks {
+		if h.id != id {
+			continue
+		}
+
+		hooks := make([]postCommitHook, 0, len(s.postCommitHooks)-1)
+		hooks = append(hooks, s.postCommitHooks[:i]...)
+		hooks = append(hooks, s.postCommitHooks[i+1:]...)
+
+		s.postCommitHooks = hooks
+
+		return nil
+	}
+
+	return ErrHookNotFound
+}
+
+func (s *ImmuStore) runPostCommitHooks(txID uint64, alh [sha256.Size]byte) {
+	s.postCommitHooksMutex.RLock()
+	hooks := s.postCommitHooks
+	s.postCommitHooksMutex.RUnlock()
+
+	for _, h := range hooks {
+		h.fn(txID, alh)
+	}
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2022 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package store
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestImmudbStorePostCommitHooks(t *testing.T) {
+	for _, synced := range []bool{true, false} {
+		t.Run(fmt.Sprintf("synced=%v", synced), func(t *testing.T) {
+			immuStore, cleanup, err := NewTempStore(DefaultOptions().WithSynced(synced))
+			require.NoError(t, err)
+			defer cleanup()
+
+			_, err = immuStore.RegisterOnPostCommitHook(nil)
+			require.ErrorIs(t, err, ErrIllegalArguments)
+
+			err = immuStore.UnregisterOnPostCommitHook(1)
+			require.ErrorIs(t, err, ErrHookNotFound)
+
+			type call struct {
+				hook int
+				txID uint64
+				alh  [sha256.Size]byte
+			}
+
+			var calls []call
+			var mutex sync.Mutex
+
+			hook := func(n int) func(uint64, [sha256.Size]byte) {
+				return func(txID uint64, alh [sha256.Size]byte) {
+					mutex.Lock()
+					defer mutex.Unlock()
+
+					calls = append(calls, call{hook: n, txID: txID, alh: alh})
+				}
+			}
+
+			id1, err := immuStore.RegisterOnPostCommitHook(hook(1))
+			require.NoError(t, err)
+
+			id2, err := immuStore.RegisterOnPostCommitHook(hook(2))
+			require.NoError(t, err)
+			require.NotEqual(t, id1, id2)
+
+			commit := func(i int) *TxHeader {
+				tx, err := immuStore.NewWriteOnlyTx()
+				require.NoError(t, err)
+
+				err = tx.Set([]byte(fmt.Sprintf("key%d", i)), nil, []byte(fmt.Sprintf("value%d", i)))
+				require.NoError(t, err)
+
+				hdr, err := tx.Commit()
+				require.NoError(t, err)
+
+				return hdr
+			}
+
+			var hdrs []*TxHeader
+
+			for i := 0; i < 3; i++ {
+				hdrs = append(hdrs, commit(i))
+			}
+
+			mutex.Lock()
+			require.Len(t, calls, 6)
+
+			for i, hdr := range hdrs {
+				require.Equal(t, call{hook: 1, txID: hdr.ID, alh: hdr.Alh()}, calls[2*i])
+				require.Equal(t, call{hook: 2, txID: hdr.ID, alh: hdr.Alh()}, calls[2*i+1])
+			}
+
+			calls = nil
+			mutex.Unlock()
+
+			err = immuStore.UnregisterOnPostCommitHook(id1)
+			require.NoError(t, err)
+
+			err = immuStore.UnregisterOnPostCommitHook(id1)
+			require.ErrorIs(t, err, ErrHookNotFound)
+
+			hdr := commit(3)
+
+			mutex.Lock()
+			require.Equal(t, []call{{hook: 2, txID: hdr.ID, alh: hdr.Alh()}}, calls)
+			mutex.Unlock()
+		})
+	}
+}
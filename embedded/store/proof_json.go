@@ -0,0 +1,216 @@
+/*
+Copyright 2022 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package store
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+var ErrMalformedProof = errors.New("malformed proof")
+
+// dualProofJSON is the canonical JSON form of a DualProof, headers are encoded with
+// their binary representation (as used to calculate their Alh) and hashes as hex strings
+type dualProofJSON struct {
+	SourceTxHeader     string           `json:"sourceTxHeader"`
+	TargetTxHeader     string           `json:"targetTxHeader"`
+	InclusionProof     []string         `json:"inclusionProof"`
+	ConsistencyProof   []string         `json:"consistencyProof"`
+	TargetBlTxAlh      string           `json:"targetBlTxAlh"`
+	LastInclusionProof []string         `json:"lastInclusionProof"`
+	LinearProof        *linearProofJSON `json:"linearProof"`
+}
+
+type linearProofJSON struct {
+	SourceTxID uint64   `json:"sourceTxId"`
+	TargetTxID uint64   `json:"targetTxId"`
+	Terms      []string `json:"terms"`
+}
+
+// JSON returns the canonical JSON representation of the proof
+func (p *DualProof) JSON() ([]byte, error) {
+	if p == nil || p.SourceTxHeader == nil || p.TargetTxHeader == nil || p.LinearProof == nil {
+		return nil, ErrIllegalArguments
+	}
+
+	sourceHdrBs, err := p.SourceTxHeader.Bytes()
+	if err != nil {
+		return nil, err
+	}
+
+	targetHdrBs, err := p.TargetTxHeader.Bytes()
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(&dualProofJSON{
+		SourceTxHeader:     hex.EncodeToString(sourceHdrBs),
+		TargetTxHeader:     hex.EncodeToString(targetHdrBs),
+		InclusionProof:     hashesToHex(p.InclusionProof),
+		ConsistencyProof:   hashesToHex(p.ConsistencyProof),
+		TargetBlTxAlh:      hex.EncodeToString(p.TargetBlTxAlh[:]),
+		LastInclusionProof: hashesToHex(p.LastInclusionProof),
+		LinearProof: &linearProofJSON{
+			SourceTxID: p.LinearProof.SourceTxID,
+			TargetTxID: p.LinearProof.TargetTxID,
+			Terms:      hashesToHex(p.LinearProof.Terms),
+		},
+	})
+}
+
+// FromJSON sets the proof from its canonical JSON representation (see JSON).
+// ErrMalformedProof is returned when any of the fields is missing or invalid, the proof is left unchanged
+func (p *DualProof) FromJSON(b []byte) error {
+	if p == nil {
+		return ErrIllegalArguments
+	}
+
+	var pj dualProofJSON
+
+	err := json.Unmarshal(b, &pj)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrMalformedProof, err)
+	}
+
+	sourceTxHeader, err := txHeaderFromHex(pj.SourceTxHeader)
+	if err != nil {
+		return fmt.Errorf("%w: invalid source tx header: %v", ErrMalformedProof, err)
+	}
+
+	targetTxHeader, err := txHeaderFromHex(pj.TargetTxHeader)
+	if err != nil {
+		return fmt.Errorf("%w: invalid target tx header: %v", ErrMalformedProof, err)
+	}
+
+	inclusionProof, err := hashesFromHex(pj.InclusionProof)
+	if err != nil {
+		return fmt.Errorf("%w: invalid inclusion proof: %v", ErrMalformedProof, err)
+	}
+
+	consistencyProof, err := hashesFromHex(pj.ConsistencyProof)
+	if err != nil {
+		return fmt.Errorf("%w: invalid consistency proof: %v", ErrMalformedProof, err)
+	}
+
+	targetBlTxAlh, err := hashFromHex(pj.TargetBlTxAlh)
+	if err != nil {
+		return fmt.Errorf("%w: invalid target bl tx alh: %v", ErrMalformedProof, err)
+	}
+
+	lastInclusionProof, err := hashesFromHex(pj.LastInclusionProof)
+	if err != nil {
+		return fmt.Errorf("%w: invalid last inclusion proof: %v", ErrMalformedProof, err)
+	}
+
+	if pj.LinearProof == nil {
+		return fmt.Errorf("%w: missing linear proof", ErrMalformedProof)
+	}
+
+	linearProofTerms, err := hashesFromHex(pj.LinearProof.Terms)
+	if err != nil {
+		return fmt.Errorf("%w: invalid linear proof: %v", ErrMalformedProof, err)
+	}
+
+	if pj.LinearProof.SourceTxID == 0 ||
+		pj.LinearProof.SourceTxID > pj.LinearProof.TargetTxID ||
+		uint64(len(linearProofTerms)) != pj.LinearProof.TargetTxID-pj.LinearProof.SourceTxID+1 {
+		return fmt.Errorf("%w: linear proof from tx %d to tx %d has %d terms",
+			ErrMalformedProof, pj.LinearProof.SourceTxID, pj.LinearProof.TargetTxID, len(linearProofTerms))
+	}
+
+	p.SourceTxHeader = sourceTxHeader
+	p.TargetTxHeader = targetTxHeader
+	p.InclusionProof = inclusionProof
+	p.ConsistencyProof = consistencyProof
+	p.TargetBlTxAlh = targetBlTxAlh
+	p.LastInclusionProof = lastInclusionProof
+	p.LinearProof = &LinearProof{
+		SourceTxID: pj.LinearProof.SourceTxID,
+		TargetTxID: pj.LinearProof.TargetTxID,
+		Terms:      linearProofTerms,
+	}
+
+	return nil
+}
+
+func txHeaderFromHex(s string) (*TxHeader, error) {
+	if len(s) == 0 {
+		return nil, errors.New("missing header")
+	}
+
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+
+	hdr := &TxHeader{}
+
+	err = hdr.ReadFrom(b)
+	if err != nil {
+		return nil, err
+	}
+
+	return hdr, nil
+}
+
+func hashesToHex(hashes [][sha256.Size]byte) []string {
+	hs := make([]string, len(hashes))
+
+	for i, h := range hashes {
+		hs[i] = hex.EncodeToString(h[:])
+	}
+
+	return hs
+}
+
+func hashesFromHex(hs []string) ([][sha256.Size]byte, error) {
+	if hs == nil {
+		return nil, errors.New("missing hashes")
+	}
+
+	hashes := make([][sha256.Size]byte, len(hs))
+
+	for i, s := range hs {
+		h, err := hashFromHex(s)
+		if err != nil {
+			return nil, fmt.Errorf("hash %d: %v", i, err)
+		}
+
+		hashes[i] = h
+	}
+
+	return hashes, nil
+}
+
+func hashFromHex(s string) ([sha256.Size]byte, error) {
+	var h [sha256.Size]byte
+
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return h, err
+	}
+
+	if len(b) != sha256.Size {
+		return h, fmt.Errorf("%d bytes found but %d are required", len(b), sha256.Size)
+	}
+
+	copy(h[:], b)
+
+	return h, nil
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2022 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDualProofJSON(t *testing.T) {
+	immuStore, cleanup, err := NewTempStore(DefaultOptions())
+	require.NoError(t, err)
+	defer cleanup()
+
+	for i := 0; i < 10; i++ {
+		tx, err := immuStore.NewWriteOnlyTx()
+		require.NoError(t, err)
+
+		err = tx.Set([]byte(fmt.Sprintf("key%d", i)), nil, []byte("value"))
+		require.NoError(t, err)
+
+		_, err = tx.Commit()
+		require.NoError(t, err)
+	}
+
+	sourceHdr, err := immuStore.ReadTxHeader(3)
+	require.NoError(t, err)
+
+	targetHdr, err := immuStore.ReadTxHeader(9)
+	require.NoError(t, err)
+
+	proof, err := immuStore.DualProof(sourceHdr, targetHdr)
+	require.NoError(t, err)
+
+	_, err = (&DualProof{}).JSON()
+	require.ErrorIs(t, err, ErrIllegalArguments)
+
+	b, err := proof.JSON()
+	require.NoError(t, err)
+
+	var decoded DualProof
+
+	err = decoded.FromJSON(b)
+	require.NoError(t, err)
+
+	require.Equal(t, proof.InclusionProof, decoded.InclusionProof)
+	require.Equal(t, proof.ConsistencyProof, decoded.ConsistencyProof)
+	require.Equal(t, proof.TargetBlTxAlh, decoded.TargetBlTxAlh)
+	require.Equal(t, proof.LinearProof, decoded.LinearProof)
+	require.Equal(t, sourceHdr.Alh(), decoded.SourceTxHeader.Alh())
+	require.Equal(t, targetHdr.Alh(), decoded.TargetTxHeader.Alh())
+
+	require.True(t, VerifyDualProof(&decoded, 3, 9, sourceHdr.Alh(), targetHdr.Alh()))
+
+	// the encoding is canonical
+	b2, err := decoded.JSON()
+	require.NoError(t, err)
+	require.Equal(t, b, b2)
+
+	malformed := func(mutate func(m map[string]interface{})) []byte {
+		var m map[string]interface{}
+
+		err := json.Unmarshal(b, &m)
+		require.NoError(t, err)
+
+		mutate(m)
+
+		mb, err := json.Marshal(m)
+		require.NoError(t, err)
+
+		return mb
+	}
+
+	for _, mb := range [][]byte{
+		[]byte("{"),
+		malformed(func(m map[string]interface{}) { delete(m, "sourceTxHeader") }),
+		malformed(func(m map[string]interface{}) { m["targetTxHeader"] = "zz" }),
+		malformed(func(m map[string]interface{}) { m["inclusionProof"] = nil }),
+		malformed(func(m map[string]interface{}) { m["consistencyProof"] = []string{"0011"} }),
+		malformed(func(m map[string]interface{}) { m["targetBlTxAlh"] = "" }),
+		malformed(func(m map[string]interface{}) { delete(m, "lastInclusionProof") }),
+		malformed(func(m map[string]interface{}) { delete(m, "linearProof") }),
+		malformed(func(m map[string]interface{}) {
+			m["linearProof"].(map[string]interface{})["targetTxId"] = 10
+		}),
+	} {
+		var p DualProof
+
+		err = p.FromJSON(mb)
+		require.ErrorIs(t, err, ErrMalformedProof)
+		require.Nil(t, p.LinearProof)
+	}
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2022 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package store
+
+import (
+	"errors"
+	"io"
+	"sync/atomic"
+	"time"
+)
+
+var ErrReadTimeout = errors.New("read timeout")
+
+// SetReadDeadline bounds the duration of each read done to the transaction, commit and value logs,
+// reads not completed within d fail with ErrReadTimeout. Zero disables the deadline
+func (s *ImmuStore) SetReadDeadline(d time.Duration) error {
+	if d < 0 {
+		return ErrIllegalArguments
+	}
+
+	atomic.StoreInt64(&s.readDeadline, int64(d))
+
+	return nil
+}
+
+// withReadDeadline returns r bounded by the current read deadline, if any
+func (s *ImmuStore) withReadDeadline(r io.ReaderAt) io.ReaderAt {
+	d := time.Duration(atomic.LoadInt64(&s.readDeadline))
+	if d == 0 {
+		return r
+	}
+
+	return &deadlineReaderAt{r: r, timeout: d}
+}
+
+type deadlineReaderAt struct {
+	r       io.ReaderAt
+	timeout time.Duration
+}
+
+type readResult struct {
+	n   int
+	err error
+}
+
+// ReadAt runs the read in its own goroutine, a read timing out keeps running on a private buffer
+// so b is never written after ReadAt returns
+func (r *deadlineReaderAt) ReadAt(b []byte, off int64) (int, error) {
+	buf := make([]byte, len(b))
+
+	resultCh := make(chan readResult, 1)
+
+	go func() {
+		n, err := r.r.ReadAt(buf, off)
+		resultCh <- readResult{n: n, err: err}
+	}()
+
+	timer := time.NewTimer(r.timeout)
+	defer timer.Stop()
+
+	select {
+	case res := <-resultCh:
+		copy(b, buf[:res.n])
+		return res.n, res.err
+	case <-timer.C:
+		return 0, ErrReadTimeout
+	}
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2022 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/codenotary/immudb/embedded/appendable"
+	"github.com/stretchr/testify/require"
+)
+
+type slowAppendable struct {
+	appendable.Appendable
+	delay time.Duration
+}
+
+func (a *slowAppendable) ReadAt(bs []byte, off int64) (int, error) {
+	time.Sleep(a.delay)
+	return a.Appendable.ReadAt(bs, off)
+}
+
+func TestImmudbStoreSetReadDeadline(t *testing.T) {
+	immuStore, cleanup, err := NewTempStore(DefaultOptions().WithMaxIOConcurrency(1))
+	require.NoError(t, err)
+	defer cleanup()
+
+	err = immuStore.SetReadDeadline(-1)
+	require.ErrorIs(t, err, ErrIllegalArguments)
+
+	tx, err := immuStore.NewWriteOnlyTx()
+	require.NoError(t, err)
+
+	err = tx.Set([]byte("key"), nil, []byte("value"))
+	require.NoError(t, err)
+
+	hdr, err := tx.Commit()
+	require.NoError(t, err)
+
+	vLog := immuStore.vLogs[0].vLog
+
+	err = immuStore.FaultInjectAppendable(0, &slowAppendable{Appendable: vLog, delay: 100 * time.Millisecond})
+	require.NoError(t, err)
+
+	valRef, err := immuStore.Get([]byte("key"))
+	require.NoError(t, err)
+
+	err = immuStore.SetReadDeadline(10 * time.Millisecond)
+	require.NoError(t, err)
+
+	_, err = valRef.Resolve()
+	require.ErrorIs(t, err, ErrReadTimeout)
+
+	// reads completed within the deadline are not affected
+	txHolder := tempTxHolder(t, immuStore)
+
+	err = immuStore.ReadTx(hdr.ID, txHolder)
+	require.NoError(t, err)
+
+	err = immuStore.SetReadDeadline(0)
+	require.NoError(t, err)
+
+	val, err := valRef.Resolve()
+	require.NoError(t, err)
+	require.Equal(t, []byte("value"), val)
+
+	err = immuStore.FaultInjectAppendable(0, vLog)
+	require.NoError(t, err)
+}
@@ -0,0 +1,515 @@
+/*
+Copyright 2022 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package store
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/codenotary/immudb/embedded/watchers"
+)
+
+var ErrReplicaDiverged = errors.New("replica diverged from source")
+var ErrReplicationStopped = errors.New("replication stopped")
+var ErrNoReplication = errors.New("no replication session is running")
+
+const DefaultReplicationDialTimeout = 10 * time.Second
+
+// replicationHandshakeSize is the size of the state sent by the receiver: TxCount + Alh
+const replicationHandshakeSize = 8 + sha256.Size
+
+type ReplicationOptions struct {
+	// VerifyProofs makes the source check the state of the receiver is a prefix of its own history
+	VerifyProofs bool
+
+	// DialTimeout is the timeout used to connect to the receiver, DefaultReplicationDialTimeout when zero
+	DialTimeout time.Duration
+}
+
+type ReplicationState int
+
+const (
+	ReplicationRunning ReplicationState = iota
+	ReplicationPaused
+	ReplicationStopped
+)
+
+type ReplicationStatus struct {
+	State ReplicationState
+	// LastTxID is the id of the last transaction sent to the receiver
+	LastTxID uint64
+	// Err holds the error which made replication stop, if any
+	Err error
+}
+
+type ReplicationSession struct {
+	st   *ImmuStore
+	conn net.Conn
+
+	paused   bool
+	resumeCh chan struct{}
+
+	stopCh chan struct{}
+	doneCh chan struct{}
+
+	lastTxID uint64
+	stopped  bool
+	err      error
+
+	mutex sync.Mutex
+}
+
+// StartReplication connects to a receiver listening on target (see AcceptReplication) and streams
+// every transaction committed after the current TxCount of the receiver, including those committed
+// while the session is running. Transactions are exported one by one, so the receiver replays them
+// with their original headers and validates the resulting Alh of each of them
+func (s *ImmuStore) StartReplication(target string, opts ReplicationOptions) (*ReplicationSession, error) {
+	if len(target) == 0 || opts.DialTimeout < 0 {
+		return nil, ErrIllegalArguments
+	}
+
+	s.mutex.Lock()
+	closed := s.closed
+	s.mutex.Unlock()
+
+	if closed {
+		return nil, ErrAlreadyClosed
+	}
+
+	dialTimeout := opts.DialTimeout
+	if dialTimeout == 0 {
+		dialTimeout = DefaultReplicationDialTimeout
+	}
+
+	conn, err := net.DialTimeout("tcp", target, dialTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	var handshake [replicationHandshakeSize]byte
+
+	_, err = io.ReadFull(conn, handshake[:])
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	targetTxID := binary.BigEndian.Uint64(handshake[:])
+
+	var targetAlh [sha256.Size]byte
+	copy(targetAlh[:], handshake[8:])
+
+	if opts.VerifyProofs {
+		err = s.verifyReplicaState(targetTxID, targetAlh)
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+
+	session := &ReplicationSession{
+		st:       s,
+		conn:     conn,
+		resumeCh: make(chan struct{}),
+		stopCh:   make(chan struct{}),
+		doneCh:   make(chan struct{}),
+		lastTxID: targetTxID,
+	}
+
+	s.replicationSessionsMutex.Lock()
+	if s.replicationSessions == nil {
+		s.replicationSessions = make(map[*ReplicationSession]struct{})
+	}
+	s.replicationSessions[session] = struct{}{}
+	s.replicationSessionsMutex.Unlock()
+
+	go session.replicate(targetTxID + 1)
+
+	return session, nil
+}
+
+// GetReplicationLag returns the number of committed transactions not yet sent to the receiver of the most
+// lagging replication session started with StartReplication, ErrNoReplication is returned if none is running
+func (s *ImmuStore) GetReplicationLag() (uint64, error) {
+	s.replicationSessionsMutex.Lock()
+	defer s.replicationSessionsMutex.Unlock()
+
+	if len(s.replicationSessions) == 0 {
+		return 0, ErrNoReplication
+	}
+
+	txCount := s.TransactionCount()
+
+	var lag uint64
+
+	for rs := range s.replicationSessions {
+		rs.mutex.Lock()
+		lastTxID := rs.lastTxID
+		rs.mutex.Unlock()
+
+		if lastTxID < txCount && txCount-lastTxID > lag {
+			lag = txCount - lastTxID
+		}
+	}
+
+	return lag, nil
+}
+
+func (s *ImmuStore) verifyReplicaState(txID uint64, alh [sha256.Size]byte) error {
+	if txID == 0 {
+		return nil
+	}
+
+	if txID > s.TransactionCount() {
+		return fmt.Errorf("%w: replica is ahead of source (tx %d)", ErrReplicaDiverged, txID)
+	}
+
+	hdr, err := s.ReadTxHeader(txID)
+	if err != nil {
+		return err
+	}
+
+	if hdr.Alh() != alh {
+		return fmt.Errorf("%w: Alh mismatch at tx %d", ErrReplicaDiverged, txID)
+	}
+
+	return nil
+}
+
+func (rs *ReplicationSession) replicate(fromTxID uint64) {
+	defer close(rs.doneCh)
+
+	err := rs.doReplicate(fromTxID)
+
+	rs.st.replicationSessionsMutex.Lock()
+	delete(rs.st.replicationSessions, rs)
+	rs.st.replicationSessionsMutex.Unlock()
+
+	rs.mutex.Lock()
+	defer rs.mutex.Unlock()
+
+	if err != nil && !errors.Is(err, ErrReplicationStopped) {
+		rs.st.logger.Warningf("replication stopped at tx %d: %v", rs.lastTxID, err)
+		rs.err = err
+	}
+
+	rs.stopped = true
+	rs.conn.Close()
+}
+
+func (rs *ReplicationSession) doReplicate(fromTxID uint64) error {
+	tx := newTx(rs.st.maxTxEntries, rs.st.maxKeyLen)
+
+	var lenBs [4]byte
+
+	for txID := fromTxID; ; txID++ {
+		err := rs.waitWhilePaused()
+		if err != nil {
+			return err
+		}
+
+		err = rs.st.WaitForTx(txID, rs.stopCh)
+		if errors.Is(err, watchers.ErrCancellationRequested) {
+			return ErrReplicationStopped
+		}
+		if err != nil {
+			return err
+		}
+
+		exportedTx, err := rs.st.ExportTx(txID, tx)
+		if err != nil {
+			return err
+		}
+
+		binary.BigEndian.PutUint32(lenBs[:], uint32(len(exportedTx)))
+
+		_, err = rs.conn.Write(lenBs[:])
+		if err == nil {
+			_, err = rs.conn.Write(exportedTx)
+		}
+		if err != nil {
+			return rs.writeErr(err)
+		}
+
+		rs.mutex.Lock()
+		rs.lastTxID = txID
+		rs.mutex.Unlock()
+	}
+}
+
+// writeErr returns ErrReplicationStopped when err was caused by Stop closing the connection
+func (rs *ReplicationSession) writeErr(err error) error {
+	select {
+	case <-rs.stopCh:
+		return ErrReplicationStopped
+	default:
+		return err
+	}
+}
+
+func (rs *ReplicationSession) waitWhilePaused() error {
+	rs.mutex.Lock()
+	paused := rs.paused
+	resumeCh := rs.resumeCh
+	rs.mutex.Unlock()
+
+	if !paused {
+		return nil
+	}
+
+	select {
+	case <-resumeCh:
+		return nil
+	case <-rs.stopCh:
+		return ErrReplicationStopped
+	}
+}
+
+// Pause suspends replication once the transaction currently being sent, if any, is completed
+func (rs *ReplicationSession) Pause() error {
+	rs.mutex.Lock()
+	defer rs.mutex.Unlock()
+
+	if rs.stopped {
+		return ErrReplicationStopped
+	}
+
+	rs.paused = true
+
+	return nil
+}
+
+func (rs *ReplicationSession) Resume() error {
+	rs.mutex.Lock()
+	defer rs.mutex.Unlock()
+
+	if rs.stopped {
+		return ErrReplicationStopped
+	}
+
+	if !rs.paused {
+		return nil
+	}
+
+	rs.paused = false
+
+	close(rs.resumeCh)
+	rs.resumeCh = make(chan struct{})
+
+	return nil
+}
+
+func (rs *ReplicationSession) Status() ReplicationStatus {
+	rs.mutex.Lock()
+	defer rs.mutex.Unlock()
+
+	state := ReplicationRunning
+
+	if rs.stopped {
+		state = ReplicationStopped
+	} else if rs.paused {
+		state = ReplicationPaused
+	}
+
+	return ReplicationStatus{
+		State:    state,
+		LastTxID: rs.lastTxID,
+		Err:      rs.err,
+	}
+}
+
+// Stop terminates the session and closes the connection with the receiver
+func (rs *ReplicationSession) Stop() error {
+	rs.mutex.Lock()
+
+	select {
+	case <-rs.stopCh:
+		rs.mutex.Unlock()
+		return ErrReplicationStopped
+	default:
+		close(rs.stopCh)
+	}
+
+	rs.mutex.Unlock()
+
+	// a write blocked on a stalled receiver is interrupted by closing the connection
+	rs.conn.Close()
+
+	<-rs.doneCh
+
+	return nil
+}
+
+// AcceptReplication replays into this store the transactions streamed by a source store through conn
+// (see StartReplication). The current TxCount and Alh of this store are sent first, so the source
+// starts streaming from the next transaction. It returns nil when the source ends the session,
+// the context error when ctx is done. conn is closed on return
+func (s *ImmuStore) AcceptReplication(ctx context.Context, conn net.Conn) error {
+	if ctx == nil || conn == nil {
+		return ErrIllegalArguments
+	}
+
+	defer conn.Close()
+
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-done:
+		}
+	}()
+
+	txID, alh := s.Alh()
+
+	var handshake [replicationHandshakeSize]byte
+	binary.BigEndian.PutUint64(handshake[:], txID)
+	copy(handshake[8:], alh[:])
+
+	_, err := conn.Write(handshake[:])
+	if err != nil {
+		return replicationErr(ctx, err)
+	}
+
+	maxExportedTxSize := s.maxTxSize + s.maxTxEntries*s.maxValueLen
+
+	var lenBs [4]byte
+
+	for {
+		_, err = io.ReadFull(conn, lenBs[:])
+		if err == io.EOF {
+			return replicationErr(ctx, nil)
+		}
+		if err != nil {
+			return replicationErr(ctx, err)
+		}
+
+		exportedTxLen := int(binary.BigEndian.Uint32(lenBs[:]))
+		if exportedTxLen > maxExportedTxSize {
+			return fmt.Errorf("%w: exported tx of %d bytes exceeds the maximum size", ErrIllegalArguments, exportedTxLen)
+		}
+
+		exportedTx := make([]byte, exportedTxLen)
+
+		_, err = io.ReadFull(conn, exportedTx)
+		if err != nil {
+			return replicationErr(ctx, err)
+		}
+
+		_, err = s.ReplicateTx(exportedTx, false)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func replicationErr(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	return err
+}
+
+type ReplicateOptions struct {
+	// VerifyDualProof makes each replicated transaction be verified with a dual proof from the
+	// last transaction of the receiver before being committed
+	VerifyDualProof bool
+}
+
+// ReplicateFrom replays into this store the transactions of src, committed in the same process, from fromTxID up to
+// the last one committed when the call is made. Transactions are replicated with their original headers so the
+// receiver ends up with the same history as src, those already present in the receiver are skipped once its
+// state is checked to be a prefix of the history of src
+func (s *ImmuStore) ReplicateFrom(src *ImmuStore, fromTxID uint64, opts ReplicateOptions) error {
+	if src == nil || src == s || fromTxID == 0 {
+		return ErrIllegalArguments
+	}
+
+	if s.IsClosed() {
+		return ErrAlreadyClosed
+	}
+
+	lastTxID, lastAlh := s.Alh()
+
+	if fromTxID > lastTxID+1 {
+		return fmt.Errorf("%w: tx %d does not follow the last tx of the receiver (%d)", ErrIllegalArguments, fromTxID, lastTxID)
+	}
+
+	err := src.verifyReplicaState(lastTxID, lastAlh)
+	if err != nil {
+		return err
+	}
+
+	exportTx := newTx(src.maxTxEntries, src.maxKeyLen)
+
+	return src.ForEachTx(context.Background(), fromTxID, func(tx *Tx) error {
+		hdr := tx.Header()
+
+		if hdr.ID <= lastTxID {
+			return nil
+		}
+
+		if opts.VerifyDualProof && lastTxID > 0 {
+			err := src.verifyTxExtends(lastTxID, lastAlh, hdr)
+			if err != nil {
+				return err
+			}
+		}
+
+		exportedTx, err := src.ExportTx(hdr.ID, exportTx)
+		if err != nil {
+			return err
+		}
+
+		replicatedHdr, err := s.ReplicateTx(exportedTx, false)
+		if err != nil {
+			return err
+		}
+
+		lastTxID, lastAlh = replicatedHdr.ID, replicatedHdr.Alh()
+
+		return nil
+	})
+}
+
+// verifyTxExtends checks with a dual proof that hdr extends the history ending with the tx txID whose Alh is alh
+func (s *ImmuStore) verifyTxExtends(txID uint64, alh [sha256.Size]byte, hdr *TxHeader) error {
+	txHdr, err := s.ReadTxHeader(txID)
+	if err != nil {
+		return err
+	}
+
+	proof, err := s.DualProof(txHdr, hdr)
+	if err != nil {
+		return err
+	}
+
+	if !VerifyDualProof(proof, txID, hdr.ID, alh, hdr.Alh()) {
+		return fmt.Errorf("%w: dual proof verification failed at tx %d", ErrReplicaDiverged, hdr.ID)
+	}
+
+	return nil
+}
@@ -0,0 +1,344 @@
+/*
+Copyright 2022 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package store
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestImmudbStoreReplication(t *testing.T) {
+	source, cleanupSource, err := NewTempStore(DefaultOptions())
+	require.NoError(t, err)
+	defer cleanupSource()
+
+	replica, cleanupReplica, err := NewTempStore(DefaultOptions())
+	require.NoError(t, err)
+	defer cleanupReplica()
+
+	commit := func(i int) {
+		tx, err := source.NewWriteOnlyTx()
+		require.NoError(t, err)
+
+		err = tx.Set([]byte(fmt.Sprintf("key%d", i)), nil, []byte(fmt.Sprintf("value%d", i)))
+		require.NoError(t, err)
+
+		_, err = tx.Commit()
+		require.NoError(t, err)
+	}
+
+	for i := 0; i < 5; i++ {
+		commit(i)
+	}
+
+	_, err = source.StartReplication("", ReplicationOptions{})
+	require.ErrorIs(t, err, ErrIllegalArguments)
+
+	_, err = source.GetReplicationLag()
+	require.ErrorIs(t, err, ErrNoReplication)
+
+	err = replica.AcceptReplication(context.Background(), nil)
+	require.ErrorIs(t, err, ErrIllegalArguments)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	acceptErr := make(chan error, 1)
+
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			acceptErr <- err
+			return
+		}
+
+		acceptErr <- replica.AcceptReplication(ctx, conn)
+	}()
+
+	session, err := source.StartReplication(l.Addr().String(), ReplicationOptions{VerifyProofs: true})
+	require.NoError(t, err)
+
+	waitForReplica := func(txID uint64) {
+		require.Eventually(t, func() bool {
+			return replica.TxCount() == txID
+		}, 5*time.Second, 10*time.Millisecond)
+	}
+
+	waitForReplica(5)
+
+	err = session.Pause()
+	require.NoError(t, err)
+	require.Equal(t, ReplicationPaused, session.Status().State)
+
+	err = session.Resume()
+	require.NoError(t, err)
+	require.Equal(t, ReplicationRunning, session.Status().State)
+
+	for i := 5; i < 10; i++ {
+		commit(i)
+	}
+
+	waitForReplica(10)
+
+	sourceTxID, sourceAlh := source.Alh()
+	replicaTxID, replicaAlh := replica.Alh()
+	require.Equal(t, sourceTxID, replicaTxID)
+	require.Equal(t, sourceAlh, replicaAlh)
+
+	require.Eventually(t, func() bool {
+		return session.Status().LastTxID == 10
+	}, 5*time.Second, 10*time.Millisecond)
+
+	lag, err := source.GetReplicationLag()
+	require.NoError(t, err)
+	require.Zero(t, lag)
+
+	err = session.Pause()
+	require.NoError(t, err)
+
+	// the transaction being awaited when pausing may still be sent
+	for i := 10; i < 13; i++ {
+		commit(i)
+	}
+
+	require.Eventually(t, func() bool {
+		lag, err := source.GetReplicationLag()
+		return err == nil && lag == 13-session.Status().LastTxID && lag >= 2
+	}, 5*time.Second, 10*time.Millisecond)
+
+	err = session.Resume()
+	require.NoError(t, err)
+
+	waitForReplica(13)
+
+	err = session.Stop()
+	require.NoError(t, err)
+
+	_, err = source.GetReplicationLag()
+	require.ErrorIs(t, err, ErrNoReplication)
+
+	err = session.Stop()
+	require.ErrorIs(t, err, ErrReplicationStopped)
+
+	status := session.Status()
+	require.Equal(t, ReplicationStopped, status.State)
+	require.NoError(t, status.Err)
+
+	err = session.Pause()
+	require.ErrorIs(t, err, ErrReplicationStopped)
+
+	select {
+	case err = <-acceptErr:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "receiver did not terminate")
+	}
+}
+
+func TestImmudbStoreReplicationStopStalledReceiver(t *testing.T) {
+	source, cleanupSource, err := NewTempStore(DefaultOptions().WithMaxValueLen(1 << 20))
+	require.NoError(t, err)
+	defer cleanupSource()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+
+	connCh := make(chan net.Conn, 1)
+
+	// the receiver completes the handshake as an empty store but never reads
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+
+		var handshake [replicationHandshakeSize]byte
+		conn.Write(handshake[:])
+
+		connCh <- conn
+	}()
+
+	session, err := source.StartReplication(l.Addr().String(), ReplicationOptions{})
+	require.NoError(t, err)
+
+	conn := <-connCh
+	defer conn.Close()
+
+	// more data than what fits into the socket buffers
+	for i := 0; i < 64; i++ {
+		tx, err := source.NewWriteOnlyTx()
+		require.NoError(t, err)
+
+		err = tx.Set([]byte(fmt.Sprintf("key%d", i)), nil, make([]byte, 1<<20))
+		require.NoError(t, err)
+
+		_, err = tx.AsyncCommit()
+		require.NoError(t, err)
+	}
+
+	stopped := make(chan error, 1)
+
+	go func() {
+		stopped <- session.Stop()
+	}()
+
+	select {
+	case err = <-stopped:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "replication session did not stop")
+	}
+
+	status := session.Status()
+	require.Equal(t, ReplicationStopped, status.State)
+	require.NoError(t, status.Err)
+}
+
+func TestImmudbStoreReplicationDiverged(t *testing.T) {
+	source, cleanupSource, err := NewTempStore(DefaultOptions())
+	require.NoError(t, err)
+	defer cleanupSource()
+
+	replica, cleanupReplica, err := NewTempStore(DefaultOptions())
+	require.NoError(t, err)
+	defer cleanupReplica()
+
+	for _, st := range []*ImmuStore{source, replica, replica} {
+		tx, err := st.NewWriteOnlyTx()
+		require.NoError(t, err)
+
+		err = tx.Set([]byte("key"), nil, []byte("value"))
+		require.NoError(t, err)
+
+		_, err = tx.Commit()
+		require.NoError(t, err)
+	}
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	acceptErr := make(chan error, 1)
+
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			acceptErr <- err
+			return
+		}
+
+		acceptErr <- replica.AcceptReplication(ctx, conn)
+	}()
+
+	_, err = source.StartReplication(l.Addr().String(), ReplicationOptions{VerifyProofs: true})
+	require.ErrorIs(t, err, ErrReplicaDiverged)
+
+	cancel()
+
+	<-acceptErr
+}
+
+func TestImmudbStoreReplicateFrom(t *testing.T) {
+	source, cleanupSource, err := NewTempStore(DefaultOptions())
+	require.NoError(t, err)
+	defer cleanupSource()
+
+	replica, cleanupReplica, err := NewTempStore(DefaultOptions())
+	require.NoError(t, err)
+	defer cleanupReplica()
+
+	err = replica.ReplicateFrom(nil, 1, ReplicateOptions{})
+	require.ErrorIs(t, err, ErrIllegalArguments)
+
+	err = replica.ReplicateFrom(replica, 1, ReplicateOptions{})
+	require.ErrorIs(t, err, ErrIllegalArguments)
+
+	err = replica.ReplicateFrom(source, 2, ReplicateOptions{})
+	require.ErrorIs(t, err, ErrIllegalArguments)
+
+	commit := func(from, to int) {
+		for i := from; i < to; i++ {
+			tx, err := source.NewWriteOnlyTx()
+			require.NoError(t, err)
+
+			err = tx.Set([]byte(fmt.Sprintf("key%d", i)), nil, []byte(fmt.Sprintf("value%d", i)))
+			require.NoError(t, err)
+
+			_, err = tx.Commit()
+			require.NoError(t, err)
+		}
+	}
+
+	commit(0, 5)
+
+	err = replica.ReplicateFrom(source, 1, ReplicateOptions{VerifyDualProof: true})
+	require.NoError(t, err)
+
+	replicaTxID, replicaAlh := replica.Alh()
+	sourceTxID, sourceAlh := source.Alh()
+	require.Equal(t, sourceTxID, replicaTxID)
+	require.Equal(t, sourceAlh, replicaAlh)
+
+	commit(5, 10)
+
+	// transactions already replicated are skipped
+	err = replica.ReplicateFrom(source, 3, ReplicateOptions{VerifyDualProof: true})
+	require.NoError(t, err)
+
+	replicaTxID, replicaAlh = replica.Alh()
+	sourceTxID, sourceAlh = source.Alh()
+	require.EqualValues(t, 10, replicaTxID)
+	require.Equal(t, sourceTxID, replicaTxID)
+	require.Equal(t, sourceAlh, replicaAlh)
+
+	err = replica.WaitForIndexingUpto(replicaTxID, nil)
+	require.NoError(t, err)
+
+	val, err := replica.Get([]byte("key7"))
+	require.NoError(t, err)
+
+	v, err := val.Resolve()
+	require.NoError(t, err)
+	require.Equal(t, []byte("value7"), v)
+
+	diverged, cleanupDiverged, err := NewTempStore(DefaultOptions())
+	require.NoError(t, err)
+	defer cleanupDiverged()
+
+	tx, err := diverged.NewWriteOnlyTx()
+	require.NoError(t, err)
+
+	err = tx.Set([]byte("key"), nil, []byte("value"))
+	require.NoError(t, err)
+
+	_, err = tx.Commit()
+	require.NoError(t, err)
+
+	err = diverged.ReplicateFrom(source, 2, ReplicateOptions{})
+	require.ErrorIs(t, err, ErrReplicaDiverged)
+}
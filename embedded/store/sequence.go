@@ -0,0 +1,80 @@
+/*
+Copyright 2022 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package store
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// sequenceKeyPrefix is prepended to sequence names to hold their current values
+var sequenceKeyPrefix = []byte("_seq.")
+
+func sequenceKey(name string) []byte {
+	sk := make([]byte, len(sequenceKeyPrefix)+len(name))
+	copy(sk, sequenceKeyPrefix)
+	copy(sk[len(sequenceKeyPrefix):], name)
+	return sk
+}
+
+// SequenceNext increments the sequence name and returns its new value, the first value of a sequence is 1.
+// Every increment is committed as a regular transaction, thus sequence values are verifiable
+// and remain monotonic across restarts
+func (s *ImmuStore) SequenceNext(name string) (uint64, error) {
+	if len(name) == 0 {
+		return 0, ErrIllegalArguments
+	}
+
+	v, err := s.atomicIncrement(sequenceKey(name), 1, true)
+	if err != nil {
+		return 0, err
+	}
+
+	return uint64(v), nil
+}
+
+// SequenceCurrent returns the current value of the sequence name without incrementing it,
+// zero is returned for sequences never incremented
+func (s *ImmuStore) SequenceCurrent(name string) (uint64, error) {
+	if len(name) == 0 {
+		return 0, ErrIllegalArguments
+	}
+
+	tx, err := s.NewTx()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Cancel()
+
+	valRef, err := tx.Get(sequenceKey(name))
+	if errors.Is(err, ErrKeyNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	val, err := valRef.Resolve()
+	if err != nil {
+		return 0, err
+	}
+
+	if len(val) != 8 {
+		return 0, ErrInvalidCounter
+	}
+
+	return binary.BigEndian.Uint64(val), nil
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2022 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package store
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestImmudbStoreSequence(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sequence")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	immuStore, err := Open(dir, DefaultOptions())
+	require.NoError(t, err)
+
+	_, err = immuStore.SequenceNext("")
+	require.ErrorIs(t, err, ErrIllegalArguments)
+
+	_, err = immuStore.SequenceCurrent("")
+	require.ErrorIs(t, err, ErrIllegalArguments)
+
+	v, err := immuStore.SequenceCurrent("orders")
+	require.NoError(t, err)
+	require.Zero(t, v)
+
+	for i := uint64(1); i <= 3; i++ {
+		v, err = immuStore.SequenceNext("orders")
+		require.NoError(t, err)
+		require.Equal(t, i, v)
+	}
+
+	v, err = immuStore.SequenceNext("invoices")
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), v)
+
+	v, err = immuStore.SequenceCurrent("orders")
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), v)
+
+	// sequence values can only be updated through the sequence api
+	_, err = immuStore.AtomicIncrement(sequenceKey("orders"), 10)
+	require.ErrorIs(t, err, ErrReservedKey)
+
+	tx, err := immuStore.NewWriteOnlyTx()
+	require.NoError(t, err)
+
+	err = tx.Set(sequenceKey("orders"), nil, make([]byte, 8))
+	require.ErrorIs(t, err, ErrReservedKey)
+
+	err = tx.Cancel()
+	require.NoError(t, err)
+
+	err = immuStore.Close()
+	require.NoError(t, err)
+
+	immuStore, err = Open(dir, DefaultOptions())
+	require.NoError(t, err)
+	defer immustoreClose(t, immuStore)
+
+	v, err = immuStore.SequenceCurrent("orders")
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), v)
+
+	v, err = immuStore.SequenceNext("orders")
+	require.NoError(t, err)
+	require.Equal(t, uint64(4), v)
+}
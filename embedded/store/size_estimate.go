@@ -0,0 +1,208 @@
+/*
+Copyright 2022 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package store
+
+import (
+	"crypto/sha256"
+	"math"
+
+	"github.com/codenotary/immudb/embedded/appendable"
+)
+
+// estimateSampleTxs is the number of most recent transactions read to measure the compression ratio
+const estimateSampleTxs = 64
+
+// indexFillFactor is the expected occupancy of index nodes, as nodes are split in halves when full
+const indexFillFactor = 0.69
+
+// compressedValueOverhead is the size prefix written by the appendable for each compressed value
+const compressedValueOverhead = 4
+
+// txHeaderSize is the size of a serialized tx header (version 1) without metadata, including its Alh
+const txHeaderSize = txIDSize /*txID*/ +
+	tsSize /*ts*/ +
+	txIDSize /*blTxID*/ +
+	sha256.Size /*blRoot*/ +
+	sha256.Size /*prevAlh*/ +
+	sszSize /*version*/ +
+	sszSize /*txMetadataLen*/ +
+	lszSize /*|entries|*/ +
+	sha256.Size /*alh*/
+
+// txEntryOverhead is the size of a serialized tx entry without metadata, excluding the key
+const txEntryOverhead = sszSize /*kvMetadataLen*/ +
+	sszSize /*kLen*/ +
+	lszSize /*vLen*/ +
+	offsetSize /*vOff*/ +
+	sha256.Size /*hValue*/
+
+// indexEntryOverhead is the size of an index leaf entry without metadata, excluding the key
+const indexEntryOverhead = sszSize /*kLen*/ +
+	sszSize /*vLen*/ +
+	lszSize + offsetSize + sha256.Size + sszSize + sszSize /*value ref*/ +
+	8 /*ts*/ +
+	8 /*hOff*/ +
+	8 /*hCount*/ +
+	4 + 8 + 8 /*history log entry*/
+
+type StorageSizeEstimate struct {
+	ValueLogBytes  int64
+	TxLogBytes     int64
+	CommitLogBytes int64
+	IndexBytes     int64
+
+	// CompressionRatio is the measured ratio between stored and actual value sizes, 1 when values are not compressed
+	CompressionRatio float64
+
+	// ConfidenceInterval is the relative margin of the estimate, the actual size is expected
+	// to be within TotalBytes() * (1 ± ConfidenceInterval)
+	ConfidenceInterval float64
+}
+
+func (e StorageSizeEstimate) TotalBytes() int64 {
+	return e.ValueLogBytes + e.TxLogBytes + e.CommitLogBytes + e.IndexBytes
+}
+
+// EstimateSize estimates the additional storage required to write kvCount entries with keys and values of the
+// provided average lengths. The compression ratio and the number of entries per transaction are measured from the
+// most recent transactions, when there are none values are assumed to be uncompressed and written one per transaction.
+// Metadata and the growth of the linear hash tree are not accounted for
+func (s *ImmuStore) EstimateSize(kvCount int, avgKeyLen, avgValueLen int) (StorageSizeEstimate, error) {
+	if kvCount < 0 || avgKeyLen < 0 || avgValueLen < 0 {
+		return StorageSizeEstimate{}, ErrIllegalArguments
+	}
+
+	compressionRatio, sampledValues, entriesPerTx, err := s.sampleStoredSizes()
+	if err != nil {
+		return StorageSizeEstimate{}, err
+	}
+
+	txCount := int64(math.Ceil(float64(kvCount) / entriesPerTx))
+
+	valueSize := int64(0)
+	if avgValueLen > 0 {
+		valueSize = int64(math.Ceil(float64(avgValueLen) * compressionRatio))
+
+		if compressionRatio != 1 {
+			valueSize += compressedValueOverhead
+		}
+	}
+
+	indexEntrySize := float64(avgKeyLen+indexEntryOverhead) / indexFillFactor
+
+	// structural overheads are known in advance, only the node occupancy and compression may vary
+	confidenceInterval := 1 - indexFillFactor
+
+	if s.vLogsCompressed() {
+		if sampledValues == 0 {
+			confidenceInterval = 0.5
+		} else {
+			confidenceInterval += 0.5 / math.Sqrt(float64(sampledValues))
+		}
+	}
+
+	return StorageSizeEstimate{
+		ValueLogBytes:      int64(kvCount) * valueSize,
+		TxLogBytes:         txCount*txHeaderSize + int64(kvCount)*int64(txEntryOverhead+avgKeyLen),
+		CommitLogBytes:     txCount * cLogEntrySize,
+		IndexBytes:         int64(math.Ceil(float64(kvCount) * indexEntrySize)),
+		CompressionRatio:   compressionRatio,
+		ConfidenceInterval: math.Min(confidenceInterval, 1),
+	}, nil
+}
+
+func (s *ImmuStore) vLogsCompressed() bool {
+	for i := range s.vLogs {
+		if s.vLogs[i].vLog.CompressionFormat() != appendable.NoCompression {
+			return true
+		}
+	}
+
+	return false
+}
+
+// sampleStoredSizes reads the most recent transactions and returns the ratio between stored and
+// actual value sizes, the number of values used to measure it and the average entries per transaction.
+// Values of the same transaction are appended sequentially, so the stored size of a value is
+// the distance to the offset of the next value of the same transaction
+func (s *ImmuStore) sampleStoredSizes() (compressionRatio float64, sampledValues int, entriesPerTx float64, err error) {
+	lastTxID := s.lastCommittedTxID()
+
+	if lastTxID == 0 {
+		return 1, 0, 1, nil
+	}
+
+	fromTxID := uint64(1)
+	if lastTxID > estimateSampleTxs {
+		fromTxID = lastTxID - estimateSampleTxs + 1
+	}
+
+	compressed := s.vLogsCompressed()
+
+	tx, err := s.fetchAllocTx()
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	defer s.releaseAllocTx(tx)
+
+	var valueBytes, storedBytes, entries int64
+
+	for txID := fromTxID; txID <= lastTxID; txID++ {
+		err = s.ReadTx(txID, tx)
+		if err != nil {
+			return 0, 0, 0, err
+		}
+
+		txEntries := tx.Entries()
+
+		entries += int64(len(txEntries))
+
+		if !compressed {
+			continue
+		}
+
+		for i := 0; i+1 < len(txEntries); i++ {
+			e, next := txEntries[i], txEntries[i+1]
+
+			if e.VLen() == 0 || next.VLen() == 0 {
+				continue
+			}
+
+			vLogID, off := decodeOffset(e.VOff())
+			nextVLogID, nextOff := decodeOffset(next.VOff())
+
+			// offsets are only comparable within the same value log
+			if vLogID != nextVLogID || nextOff <= off {
+				continue
+			}
+
+			valueBytes += int64(e.VLen())
+			storedBytes += nextOff - off - compressedValueOverhead
+			sampledValues++
+		}
+	}
+
+	entriesPerTx = float64(entries) / float64(lastTxID-fromTxID+1)
+	if entriesPerTx < 1 {
+		entriesPerTx = 1
+	}
+
+	if valueBytes == 0 {
+		return 1, sampledValues, entriesPerTx, nil
+	}
+
+	return float64(storedBytes) / float64(valueBytes), sampledValues, entriesPerTx, nil
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2022 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package store
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/codenotary/immudb/embedded/appendable"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImmudbStoreEstimateSize(t *testing.T) {
+	immuStore, cleanup, err := NewTempStore(DefaultOptions())
+	require.NoError(t, err)
+	defer cleanup()
+
+	_, err = immuStore.EstimateSize(-1, 8, 100)
+	require.ErrorIs(t, err, ErrIllegalArguments)
+
+	estimate, err := immuStore.EstimateSize(10, 8, 100)
+	require.NoError(t, err)
+	require.Equal(t, 1.0, estimate.CompressionRatio)
+	require.EqualValues(t, 10*100, estimate.ValueLogBytes)
+	require.EqualValues(t, 10*cLogEntrySize, estimate.CommitLogBytes)
+	require.EqualValues(t, 10*txHeaderSize+10*(txEntryOverhead+8), estimate.TxLogBytes)
+
+	tx, err := immuStore.NewWriteOnlyTx()
+	require.NoError(t, err)
+
+	for i := 0; i < 10; i++ {
+		err = tx.Set([]byte(fmt.Sprintf("key%05d", i)), nil, make([]byte, 100))
+		require.NoError(t, err)
+	}
+
+	_, err = tx.Commit()
+	require.NoError(t, err)
+
+	txLogSize, err := immuStore.txLog.Size()
+	require.NoError(t, err)
+
+	cLogSize, err := immuStore.cLog.Size()
+	require.NoError(t, err)
+
+	estimate, err = immuStore.EstimateSize(10, 8, 100)
+	require.NoError(t, err)
+	require.EqualValues(t, txLogSize, estimate.TxLogBytes)
+	require.EqualValues(t, cLogSize, estimate.CommitLogBytes)
+	require.Greater(t, estimate.IndexBytes, int64(10*(8+indexEntryOverhead)))
+	require.Equal(t, estimate.ValueLogBytes+estimate.TxLogBytes+estimate.CommitLogBytes+estimate.IndexBytes, estimate.TotalBytes())
+	require.Greater(t, estimate.ConfidenceInterval, 0.0)
+	require.Less(t, estimate.ConfidenceInterval, 1.0)
+}
+
+func TestImmudbStoreEstimateSizeWithCompression(t *testing.T) {
+	immuStore, cleanup, err := NewTempStore(DefaultOptions().WithCompressionFormat(appendable.GZipCompression))
+	require.NoError(t, err)
+	defer cleanup()
+
+	estimate, err := immuStore.EstimateSize(10, 8, 100)
+	require.NoError(t, err)
+	require.Equal(t, 1.0, estimate.CompressionRatio)
+	require.Equal(t, 0.5, estimate.ConfidenceInterval)
+
+	tx, err := immuStore.NewWriteOnlyTx()
+	require.NoError(t, err)
+
+	for i := 0; i < 10; i++ {
+		err = tx.Set([]byte(fmt.Sprintf("key%05d", i)), nil, bytes.Repeat([]byte{'a'}, 1000))
+		require.NoError(t, err)
+	}
+
+	_, err = tx.Commit()
+	require.NoError(t, err)
+
+	estimate, err = immuStore.EstimateSize(10, 8, 1000)
+	require.NoError(t, err)
+	require.Less(t, estimate.CompressionRatio, 0.5)
+	require.Less(t, estimate.ValueLogBytes, int64(10*1000/2))
+	require.Less(t, estimate.ConfidenceInterval, 0.5)
+}
@@ -0,0 +1,169 @@
+/*
+Copyright 2022 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package store
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/codenotary/immudb/embedded/appendable"
+)
+
+var ErrTagExists = errors.New("tag already exists")
+var ErrTagNotFound = errors.New("tag not found")
+
+const MaxTagLen = 256
+
+// tagStore keeps an append-only log of tag -> txID mappings
+// which is fully loaded into memory when opening the store
+type tagStore struct {
+	log    appendable.Appendable
+	synced bool
+
+	tags map[string]uint64
+
+	mutex sync.Mutex
+}
+
+// openTagStore loads the tags from log, a nil log is only allowed in read-only mode and holds no tags
+func openTagStore(log appendable.Appendable, synced bool) (*tagStore, error) {
+	if log == nil {
+		return &tagStore{tags: make(map[string]uint64)}, nil
+	}
+
+	size, err := log.Size()
+	if err != nil {
+		return nil, err
+	}
+
+	ts := &tagStore{
+		log:    log,
+		synced: synced,
+		tags:   make(map[string]uint64),
+	}
+
+	r := appendable.NewReaderFrom(log, 0, 4096)
+
+	var off int64
+
+	for off < size {
+		tagLen, err := r.ReadUint16()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if tagLen == 0 || tagLen > MaxTagLen {
+			return nil, ErrCorruptedData
+		}
+
+		tag := make([]byte, tagLen)
+		_, err = r.Read(tag)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		txID, err := r.ReadUint64()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		ts.tags[string(tag)] = txID
+
+		off += int64(sszSize + int(tagLen) + txIDSize)
+	}
+
+	if off < size {
+		// discard partially written record
+		err = log.SetOffset(off)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return ts, nil
+}
+
+func (ts *tagStore) put(tag string, txID uint64) error {
+	ts.mutex.Lock()
+	defer ts.mutex.Unlock()
+
+	_, exists := ts.tags[tag]
+	if exists {
+		return ErrTagExists
+	}
+
+	if ts.log == nil {
+		return ErrReadOnly
+	}
+
+	// tagLen + tag + txID
+	b := make([]byte, sszSize+len(tag)+txIDSize)
+	binary.BigEndian.PutUint16(b, uint16(len(tag)))
+	copy(b[sszSize:], tag)
+	binary.BigEndian.PutUint64(b[sszSize+len(tag):], txID)
+
+	_, _, err := ts.log.Append(b)
+	if err != nil {
+		return err
+	}
+
+	err = ts.log.Flush()
+	if err != nil {
+		return err
+	}
+
+	if ts.synced {
+		err = ts.log.Sync()
+		if err != nil {
+			return err
+		}
+	}
+
+	ts.tags[tag] = txID
+
+	return nil
+}
+
+func (ts *tagStore) get(tag string) (uint64, error) {
+	ts.mutex.Lock()
+	defer ts.mutex.Unlock()
+
+	txID, ok := ts.tags[tag]
+	if !ok {
+		return 0, ErrTagNotFound
+	}
+
+	return txID, nil
+}
+
+func (ts *tagStore) Close() error {
+	if ts.log == nil {
+		return nil
+	}
+
+	return ts.log.Close()
+}
@@ -20,6 +20,8 @@ import (
 	"crypto/sha256"
 	"encoding/binary"
 	"fmt"
+	"strconv"
+	"sync"
 
 	"github.com/codenotary/immudb/embedded/appendable"
 	"github.com/codenotary/immudb/embedded/htree"
@@ -97,6 +99,16 @@ func (tx *Tx) Header() *TxHeader {
 	}
 }
 
+// BlTxID returns the number of transactions included in the binary linking tree when this transaction was committed
+func (tx *Tx) BlTxID() uint64 {
+	return tx.header.BlTxID
+}
+
+// BlRoot returns the root of the binary linking tree of size BlTxID
+func (tx *Tx) BlRoot() [sha256.Size]byte {
+	return tx.header.BlRoot
+}
+
 func (hdr *TxHeader) Bytes() ([]byte, error) {
 	// ID + PrevAlh + Ts + Version + MDLen + MD + NEntries + Eh + BlTxID + BlRoot
 	var b [txIDSize + sha256.Size + tsSize + sszSize + (sszSize + maxTxMetadataLen) + lszSize + sha256.Size + txIDSize + sha256.Size]byte
@@ -160,6 +172,29 @@ func (hdr *TxHeader) Bytes() ([]byte, error) {
 	return b[:i], nil
 }
 
+// sizeBytes returns the length of the serialized header without actually serializing it
+func (hdr *TxHeader) sizeBytes() int {
+	if hdr == nil {
+		return 0
+	}
+
+	// ID + PrevAlh + Ts + Version + NEntries + Eh + BlTxID + BlRoot
+	size := txIDSize + sha256.Size + tsSize + sszSize + sha256.Size + txIDSize + sha256.Size
+
+	if hdr.Version == 0 {
+		return size + sszSize
+	}
+
+	// MDLen + MD + NEntries
+	size += sszSize + lszSize
+
+	if hdr.Metadata != nil {
+		size += len(hdr.Metadata.Bytes())
+	}
+
+	return size
+}
+
 func (hdr *TxHeader) ReadFrom(b []byte) error {
 	// Minimum length with version record
 	if len(b) < txIDSize+sha256.Size+tsSize+2*sszSize+sha256.Size+txIDSize+sha256.Size {
@@ -348,6 +383,69 @@ func (tx *Tx) Entries() []*TxEntry {
 	return tx.entries[:tx.header.NEntries]
 }
 
+// NumUniqueKeys returns the number of distinct keys in the transaction
+func (tx *Tx) NumUniqueKeys() int {
+	keys := make(map[string]struct{}, tx.header.NEntries)
+
+	for _, e := range tx.Entries() {
+		keys[string(e.key())] = struct{}{}
+	}
+
+	return len(keys)
+}
+
+// TotalValueBytes returns the sum of the lengths of the values of all the entries in the transaction
+func (tx *Tx) TotalValueBytes() int64 {
+	var total int64
+
+	for _, e := range tx.Entries() {
+		total += int64(e.vLen)
+	}
+
+	return total
+}
+
+// txSummaryPrefixLen is the number of bytes of the Alh included in Tx.Summary
+const txSummaryPrefixLen = 4
+
+const hexDigits = "0123456789abcdef"
+
+var txSummaryBufPool = sync.Pool{
+	New: func() interface{} {
+		b := make([]byte, 0, 96)
+		return &b
+	},
+}
+
+// Summary returns a short one-line description of the transaction meant to be used in logs
+// e.g. Tx{ID:42, ts:1701234567, entries:10, alh:0xdeadbeef...}
+func (tx *Tx) Summary() string {
+	bufp := txSummaryBufPool.Get().(*[]byte)
+	buf := (*bufp)[:0]
+
+	buf = append(buf, "Tx{ID:"...)
+	buf = strconv.AppendUint(buf, tx.header.ID, 10)
+	buf = append(buf, ", ts:"...)
+	buf = strconv.AppendInt(buf, tx.header.Ts, 10)
+	buf = append(buf, ", entries:"...)
+	buf = strconv.AppendInt(buf, int64(tx.header.NEntries), 10)
+	buf = append(buf, ", alh:0x"...)
+
+	alh := tx.header.Alh()
+	for _, b := range alh[:txSummaryPrefixLen] {
+		buf = append(buf, hexDigits[b>>4], hexDigits[b&0x0f])
+	}
+
+	buf = append(buf, "...}"...)
+
+	summary := string(buf)
+
+	*bufp = buf
+	txSummaryBufPool.Put(bufp)
+
+	return summary
+}
+
 func (tx *Tx) IndexOf(key []byte) (int, error) {
 	for i, e := range tx.Entries() {
 		if bytes.Equal(e.key(), key) {
@@ -656,6 +754,11 @@ func (e *TxEntry) HVal() [sha256.Size]byte {
 	return e.hVal
 }
 
+// ValueHash returns the hash of the value of the entry
+func (e *TxEntry) ValueHash() [sha256.Size]byte {
+	return e.hVal
+}
+
 func (e *TxEntry) VOff() int64 {
 	return e.vOff
 }
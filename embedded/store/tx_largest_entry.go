@@ -0,0 +1,46 @@
+/*
+Copyright 2022 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package store
+
+// TxLargestEntry returns the key and value length of the entry with the largest value in the transaction txID,
+// the first one in the transaction when several have the same length.
+// Only the tx log is read, values are not fetched from the value logs
+func (s *ImmuStore) TxLargestEntry(txID uint64) (key []byte, valueLen int, err error) {
+	tx, err := s.fetchAllocTx()
+	if err != nil {
+		return nil, 0, err
+	}
+	defer s.releaseAllocTx(tx)
+
+	err = s.ReadTx(txID, tx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var largest *TxEntry
+
+	for _, e := range tx.Entries() {
+		if largest == nil || e.VLen() > largest.VLen() {
+			largest = e
+		}
+	}
+
+	if largest == nil {
+		return nil, 0, ErrKeyNotFound
+	}
+
+	return largest.Key(), largest.VLen(), nil
+}
@@ -0,0 +1,54 @@
+/*
+Copyright 2022 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package store
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestImmudbStoreTxLargestEntry(t *testing.T) {
+	immuStore, cleanup, err := NewTempStore(DefaultOptions())
+	require.NoError(t, err)
+	defer cleanup()
+
+	_, _, err = immuStore.TxLargestEntry(1)
+	require.ErrorIs(t, err, ErrTxNotFound)
+
+	tx, err := immuStore.NewWriteOnlyTx()
+	require.NoError(t, err)
+
+	err = tx.Set([]byte("small"), nil, make([]byte, 10))
+	require.NoError(t, err)
+
+	err = tx.Set([]byte("large1"), nil, make([]byte, 100))
+	require.NoError(t, err)
+
+	err = tx.Set([]byte("large2"), nil, make([]byte, 100))
+	require.NoError(t, err)
+
+	err = tx.Set([]byte("empty"), nil, nil)
+	require.NoError(t, err)
+
+	hdr, err := tx.Commit()
+	require.NoError(t, err)
+
+	key, valueLen, err := immuStore.TxLargestEntry(hdr.ID)
+	require.NoError(t, err)
+	require.Equal(t, []byte("large1"), key)
+	require.Equal(t, 100, valueLen)
+}
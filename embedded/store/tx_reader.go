@@ -16,6 +16,7 @@ limitations under the License.
 package store
 
 import (
+	"context"
 	"crypto/sha256"
 	"fmt"
 )
@@ -60,6 +61,99 @@ func (s *ImmuStore) newTxReader(initialTxID uint64, desc bool, tx *Tx) (*TxReade
 	}, nil
 }
 
+// KVWithTxID holds an entry written for a key together with the transaction it was written in
+type KVWithTxID struct {
+	Key      []byte
+	Value    []byte
+	Metadata *KVMetadata
+	TxID     uint64
+}
+
+// ScanForwardFrom walks the transaction log starting from txID and collects every entry written for key.
+// The index is not used, so entries not yet indexed or removed from the index are also returned.
+// Deleted and expired entries are included, their metadata can be used to tell them apart
+func (s *ImmuStore) ScanForwardFrom(txID uint64, key []byte) ([]*KVWithTxID, error) {
+	if len(key) == 0 {
+		return nil, ErrNullKey
+	}
+
+	var kvs []*KVWithTxID
+
+	err := s.ForEachTx(context.Background(), txID, func(tx *Tx) error {
+		e, err := tx.EntryOf(key)
+		if err == ErrKeyNotFound {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		k := make([]byte, len(key))
+		copy(k, key)
+
+		val := make([]byte, e.vLen)
+
+		_, err = s.readValueAt(val, e.vOff, e.hVal)
+		if err != nil {
+			return err
+		}
+
+		kvs = append(kvs, &KVWithTxID{
+			Key:      k,
+			Value:    val,
+			Metadata: e.md,
+			TxID:     tx.header.ID,
+		})
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return kvs, nil
+}
+
+// ForEachTx delivers, in ascending order, each committed transaction starting from fromTxID to fn.
+// Iteration stops as soon as fn returns an error, which is then returned,
+// or the context is cancelled. The transaction passed to fn is only valid during the call.
+func (s *ImmuStore) ForEachTx(ctx context.Context, fromTxID uint64, fn func(*Tx) error) error {
+	if ctx == nil || fn == nil {
+		return ErrIllegalArguments
+	}
+
+	tx, err := s.fetchAllocTx()
+	if err != nil {
+		return err
+	}
+	defer s.releaseAllocTx(tx)
+
+	txr, err := s.NewTxReader(fromTxID, false, tx)
+	if err != nil {
+		return err
+	}
+
+	for {
+		err = ctx.Err()
+		if err != nil {
+			return err
+		}
+
+		tx, err := txr.Read()
+		if err == ErrNoMoreEntries {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		err = fn(tx)
+		if err != nil {
+			return err
+		}
+	}
+}
+
 func (txr *TxReader) Read() (*Tx, error) {
 	if txr.CurrTxID == 0 {
 		return nil, ErrNoMoreEntries
@@ -16,8 +16,10 @@ limitations under the License.
 package store
 
 import (
+	"context"
 	"encoding/binary"
 	"errors"
+	"io/ioutil"
 	"os"
 	"testing"
 
@@ -116,3 +118,134 @@ func TestWrapAppendableErr(t *testing.T) {
 	err = immuStore.wrapAppendableErr(multiapp.ErrAlreadyClosed, "anAction")
 	require.Equal(t, ErrAlreadyClosed, err)
 }
+
+func TestForEachTx(t *testing.T) {
+	immuStore, err := Open("data_foreachtx", DefaultOptions().WithSynced(false))
+	require.NoError(t, err)
+	defer os.RemoveAll("data_foreachtx")
+
+	defer immustoreClose(t, immuStore)
+
+	txCount := 10
+
+	for i := 0; i < txCount; i++ {
+		tx, err := immuStore.NewWriteOnlyTx()
+		require.NoError(t, err)
+
+		k := make([]byte, 8)
+		binary.BigEndian.PutUint64(k, uint64(i))
+
+		err = tx.Set(k, nil, k)
+		require.NoError(t, err)
+
+		_, err = tx.AsyncCommit()
+		require.NoError(t, err)
+	}
+
+	err = immuStore.ForEachTx(context.Background(), 1, nil)
+	require.ErrorIs(t, err, ErrIllegalArguments)
+
+	err = immuStore.ForEachTx(context.Background(), 0, func(tx *Tx) error { return nil })
+	require.ErrorIs(t, err, ErrIllegalArguments)
+
+	t.Run("all transactions should be delivered in order", func(t *testing.T) {
+		expectedTxID := uint64(3)
+
+		err = immuStore.ForEachTx(context.Background(), 3, func(tx *Tx) error {
+			require.Equal(t, expectedTxID, tx.header.ID)
+			expectedTxID++
+			return nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, uint64(txCount+1), expectedTxID)
+	})
+
+	t.Run("iteration should stop when fn fails", func(t *testing.T) {
+		errStop := errors.New("stop")
+		visited := 0
+
+		err = immuStore.ForEachTx(context.Background(), 1, func(tx *Tx) error {
+			visited++
+			if tx.header.ID == 5 {
+				return errStop
+			}
+			return nil
+		})
+		require.ErrorIs(t, err, errStop)
+		require.Equal(t, 5, visited)
+	})
+
+	t.Run("iteration should stop when context is cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		visited := 0
+
+		err = immuStore.ForEachTx(ctx, 1, func(tx *Tx) error {
+			visited++
+			if tx.header.ID == 2 {
+				cancel()
+			}
+			return nil
+		})
+		require.ErrorIs(t, err, context.Canceled)
+		require.Equal(t, 2, visited)
+	})
+
+	t.Run("no transactions should be delivered beyond the last one", func(t *testing.T) {
+		err = immuStore.ForEachTx(context.Background(), uint64(txCount+1), func(tx *Tx) error {
+			require.Fail(t, "unexpected transaction")
+			return nil
+		})
+		require.NoError(t, err)
+	})
+}
+
+func TestScanForwardFrom(t *testing.T) {
+	dir, err := ioutil.TempDir("", "data_scan_forward")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	immuStore, err := Open(dir, DefaultOptions())
+	require.NoError(t, err)
+	defer immustoreClose(t, immuStore)
+
+	_, err = immuStore.ScanForwardFrom(1, nil)
+	require.ErrorIs(t, err, ErrNullKey)
+
+	for i := 0; i < 5; i++ {
+		tx, err := immuStore.NewWriteOnlyTx()
+		require.NoError(t, err)
+
+		err = tx.Set([]byte{byte(i % 2)}, nil, []byte{byte(i)})
+		require.NoError(t, err)
+
+		_, err = tx.Commit()
+		require.NoError(t, err)
+	}
+
+	_, err = immuStore.MultiDelete([][]byte{{0}})
+	require.NoError(t, err)
+
+	kvs, err := immuStore.ScanForwardFrom(1, []byte{0})
+	require.NoError(t, err)
+	require.Len(t, kvs, 4)
+
+	for i, txID := range []uint64{1, 3, 5} {
+		require.Equal(t, txID, kvs[i].TxID)
+		require.Equal(t, []byte{0}, kvs[i].Key)
+		require.Equal(t, []byte{byte(txID - 1)}, kvs[i].Value)
+		require.Nil(t, kvs[i].Metadata)
+	}
+
+	require.Equal(t, uint64(6), kvs[3].TxID)
+	require.Empty(t, kvs[3].Value)
+	require.True(t, kvs[3].Metadata.Deleted())
+
+	kvs, err = immuStore.ScanForwardFrom(4, []byte{1})
+	require.NoError(t, err)
+	require.Len(t, kvs, 1)
+	require.Equal(t, uint64(4), kvs[0].TxID)
+
+	kvs, err = immuStore.ScanForwardFrom(1, []byte("non-existent"))
+	require.NoError(t, err)
+	require.Empty(t, kvs)
+}
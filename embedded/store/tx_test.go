@@ -18,6 +18,7 @@ package store
 import (
 	"crypto/sha256"
 	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/codenotary/immudb/embedded/appendable"
@@ -116,6 +117,7 @@ func TestTxHeaderBytes(t *testing.T) {
 		h.Version = 0
 		bytes, err := h.Bytes()
 		require.NoError(t, err)
+		require.Equal(t, len(bytes), h.sizeBytes())
 		assert.Equal(t, []byte{
 			0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x1, // ID
 			0x5, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, // PrevAlh
@@ -135,6 +137,7 @@ func TestTxHeaderBytes(t *testing.T) {
 		h.Version = 1
 		bytes, err := h.Bytes()
 		require.NoError(t, err)
+		require.Equal(t, len(bytes), h.sizeBytes())
 		assert.Equal(t, []byte{
 			0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x1, // ID
 			0x5, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, // PrevAlh
@@ -218,3 +221,75 @@ func TestEntryMetadataWithVersions(t *testing.T) {
 	})
 
 }
+
+func TestTxNumUniqueKeys(t *testing.T) {
+	tx := newTx(4, 32)
+
+	tx.header.NEntries = 0
+	require.Zero(t, tx.NumUniqueKeys())
+
+	for i, key := range []string{"key1", "key2", "key1"} {
+		tx.entries[i].setKey([]byte(key))
+	}
+
+	tx.header.NEntries = 3
+	require.Equal(t, 2, tx.NumUniqueKeys())
+
+	tx.entries[3].setKey([]byte("key3"))
+
+	tx.header.NEntries = 4
+	require.Equal(t, 3, tx.NumUniqueKeys())
+}
+
+func TestTxEntryValueHash(t *testing.T) {
+	hVal := sha256.Sum256([]byte("value"))
+
+	e := NewTxEntry([]byte("key"), nil, 5, hVal, 0)
+	require.Equal(t, hVal, e.ValueHash())
+	require.Equal(t, e.HVal(), e.ValueHash())
+}
+
+func TestTxTotalValueBytes(t *testing.T) {
+	immuStore, cleanup, err := NewTempStore(DefaultOptions())
+	require.NoError(t, err)
+	defer cleanup()
+
+	otx, err := immuStore.NewWriteOnlyTx()
+	require.NoError(t, err)
+
+	err = otx.Set([]byte("key1"), nil, []byte("value1"))
+	require.NoError(t, err)
+
+	err = otx.Set([]byte("key2"), nil, []byte("v2"))
+	require.NoError(t, err)
+
+	err = otx.Set([]byte("key3"), nil, nil)
+	require.NoError(t, err)
+
+	hdr, err := otx.Commit()
+	require.NoError(t, err)
+
+	tx := newTx(immuStore.maxTxEntries, immuStore.maxKeyLen)
+
+	err = immuStore.ReadTx(hdr.ID, tx)
+	require.NoError(t, err)
+	require.Equal(t, int64(8), tx.TotalValueBytes())
+
+	tx = newTx(4, 32)
+	tx.header.NEntries = 0
+	require.Zero(t, tx.TotalValueBytes())
+}
+
+func TestTxSummary(t *testing.T) {
+	tx := newTx(4, 32)
+	tx.header.ID = 42
+	tx.header.Ts = 1701234567
+	tx.header.NEntries = 3
+
+	alh := tx.header.Alh()
+
+	require.Equal(t,
+		fmt.Sprintf("Tx{ID:42, ts:1701234567, entries:3, alh:0x%x...}", alh[:4]),
+		tx.Summary(),
+	)
+}
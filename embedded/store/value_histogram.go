@@ -0,0 +1,71 @@
+/*
+Copyright 2022 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package store
+
+import "math/bits"
+
+// maxValueSizeBucket is the lower bound of the last bucket of ValueSizeHistogram (1GB)
+const maxValueSizeBucket = 1 << 30
+
+// ValueSizeHistogram returns the number of current values by size, grouped into power-of-2 buckets
+// i.e. [0,1), [1,2), [2,4), ... [1GB, ∞), each bucket indexed by its lower bound.
+// Deleted and expired entries are not included.
+// The histogram reflects the current indexed state, transactions not yet indexed are not waited for.
+// Note: the whole index is walked, its cost is O(N) in the number of indexed keys
+func (s *ImmuStore) ValueSizeHistogram() (map[int]int64, error) {
+	snap, err := s.SnapshotSince(s.IndexInfo())
+	if err != nil {
+		return nil, err
+	}
+	defer snap.Close()
+
+	r, err := snap.NewKeyReader(&KeyReaderSpec{
+		Filters: []FilterFn{IgnoreExpired, IgnoreDeleted},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	histogram := make(map[int]int64)
+
+	for {
+		_, valRef, err := r.Read()
+		if err == ErrNoMoreEntries {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		histogram[valueSizeBucket(valRef.Len())]++
+	}
+
+	return histogram, nil
+}
+
+func valueSizeBucket(size uint32) int {
+	if size == 0 {
+		return 0
+	}
+
+	bucket := 1 << (bits.Len32(size) - 1)
+	if bucket > maxValueSizeBucket {
+		return maxValueSizeBucket
+	}
+
+	return bucket
+}
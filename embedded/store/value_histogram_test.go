@@ -0,0 +1,75 @@
+/*
+Copyright 2022 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package store
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValueSizeBucket(t *testing.T) {
+	require.Equal(t, 0, valueSizeBucket(0))
+	require.Equal(t, 1, valueSizeBucket(1))
+	require.Equal(t, 2, valueSizeBucket(2))
+	require.Equal(t, 2, valueSizeBucket(3))
+	require.Equal(t, 4, valueSizeBucket(4))
+	require.Equal(t, 512, valueSizeBucket(1000))
+	require.Equal(t, 1<<30, valueSizeBucket(1<<30))
+	require.Equal(t, 1<<30, valueSizeBucket(1<<31+1))
+}
+
+func TestImmudbStoreValueSizeHistogram(t *testing.T) {
+	immuStore, cleanup, err := NewTempStore(DefaultOptions())
+	require.NoError(t, err)
+	defer cleanup()
+
+	histogram, err := immuStore.ValueSizeHistogram()
+	require.NoError(t, err)
+	require.Empty(t, histogram)
+
+	tx, err := immuStore.NewWriteOnlyTx()
+	require.NoError(t, err)
+
+	for i, size := range []int{0, 1, 2, 3, 5, 7, 100} {
+		err = tx.Set([]byte(fmt.Sprintf("key%d", i)), nil, make([]byte, size))
+		require.NoError(t, err)
+	}
+
+	_, err = tx.Commit()
+	require.NoError(t, err)
+
+	tx, err = immuStore.NewTx()
+	require.NoError(t, err)
+
+	// the previous value of key6 is no longer current
+	err = tx.Set([]byte("key6"), nil, make([]byte, 6))
+	require.NoError(t, err)
+
+	err = tx.Delete([]byte("key5"))
+	require.NoError(t, err)
+
+	hdr, err := tx.Commit()
+	require.NoError(t, err)
+
+	err = immuStore.WaitForIndexingUpto(hdr.ID, nil)
+	require.NoError(t, err)
+
+	histogram, err = immuStore.ValueSizeHistogram()
+	require.NoError(t, err)
+	require.Equal(t, map[int]int64{0: 1, 1: 1, 2: 2, 4: 2}, histogram)
+}
@@ -0,0 +1,173 @@
+/*
+Copyright 2022 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package store
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"hash"
+	"io"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/codenotary/immudb/embedded/appendable"
+)
+
+// GetValueReader returns a reader streaming the value written for key at transaction txID
+// directly from the value log. Data integrity is validated once the whole value has been read,
+// ErrCorruptedData is returned instead of io.EOF when validation fails.
+// Values stored in compressed value logs are fully read into memory.
+// The returned reader must be closed by the caller.
+func (s *ImmuStore) GetValueReader(txID uint64, key []byte) (io.ReadCloser, error) {
+	if len(key) == 0 {
+		return nil, ErrNullKey
+	}
+
+	entry, _, err := s.ReadTxEntry(txID, key)
+	if err != nil {
+		return nil, err
+	}
+
+	if entry.md != nil && entry.md.ExpiredAt(time.Now()) {
+		return nil, ErrExpiredEntry
+	}
+
+	vLogID, off := decodeOffset(entry.vOff)
+
+	r := &valueReader{
+		st:        s,
+		vLogID:    vLogID,
+		off:       off,
+		remaining: int64(entry.vLen),
+		hVal:      entry.hVal,
+		hasher:    sha256.New(),
+	}
+
+	if vLogID > 0 {
+		vLog := s.fetchVLog(vLogID)
+		compressed := vLog.CompressionFormat() != appendable.NoCompression
+		s.releaseVLog(vLogID)
+
+		if compressed {
+			// compressed values can not be read from an arbitrary offset
+			b := make([]byte, entry.vLen)
+
+			_, err = s.readValueAt(b, entry.vOff, entry.hVal)
+			if err != nil {
+				return nil, err
+			}
+
+			r.buffered = bytes.NewReader(b)
+		}
+	}
+
+	runtime.SetFinalizer(r, func(r *valueReader) {
+		if !r.isClosed() {
+			s.logger.Warningf("value reader for tx %d was not closed", txID)
+		}
+	})
+
+	return r, nil
+}
+
+type valueReader struct {
+	st *ImmuStore
+
+	vLogID    byte
+	off       int64
+	remaining int64
+
+	hVal   [sha256.Size]byte
+	hasher hash.Hash
+
+	buffered *bytes.Reader
+
+	closed bool
+
+	mutex sync.Mutex
+}
+
+func (r *valueReader) Read(b []byte) (int, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.closed {
+		return 0, ErrAlreadyClosed
+	}
+
+	if r.buffered != nil {
+		return r.buffered.Read(b)
+	}
+
+	if r.remaining == 0 {
+		var h [sha256.Size]byte
+		copy(h[:], r.hasher.Sum(nil))
+
+		if h != r.hVal {
+			return 0, ErrCorruptedData
+		}
+
+		return 0, io.EOF
+	}
+
+	if len(b) == 0 {
+		return 0, nil
+	}
+
+	if int64(len(b)) > r.remaining {
+		b = b[:r.remaining]
+	}
+
+	vLog := r.st.fetchVLog(r.vLogID)
+	n, err := r.st.readVLogAt(r.vLogID, vLog, b, r.off)
+	r.st.releaseVLog(r.vLogID)
+
+	r.hasher.Write(b[:n])
+	r.off += int64(n)
+	r.remaining -= int64(n)
+
+	if err == io.EOF && r.remaining > 0 {
+		return n, io.ErrUnexpectedEOF
+	}
+	if err != nil && err != io.EOF {
+		return n, err
+	}
+
+	return n, nil
+}
+
+func (r *valueReader) isClosed() bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return r.closed
+}
+
+func (r *valueReader) Close() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.closed {
+		return ErrAlreadyClosed
+	}
+
+	r.closed = true
+
+	runtime.SetFinalizer(r, nil)
+
+	return nil
+}
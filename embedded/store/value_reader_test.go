@@ -0,0 +1,91 @@
+/*
+Copyright 2022 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package store
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/codenotary/immudb/embedded/appendable"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetValueReader(t *testing.T) {
+	for _, compressionFormat := range []int{appendable.NoCompression, appendable.GZipCompression} {
+		dir, err := ioutil.TempDir("", "data_value_reader")
+		require.NoError(t, err)
+		defer os.RemoveAll(dir)
+
+		immuStore, err := Open(dir, DefaultOptions().WithCompressionFormat(compressionFormat))
+		require.NoError(t, err)
+
+		value := make([]byte, immuStore.MaxValueLen())
+		_, err = rand.Read(value)
+		require.NoError(t, err)
+
+		tx, err := immuStore.NewWriteOnlyTx()
+		require.NoError(t, err)
+
+		err = tx.Set([]byte("key"), nil, value)
+		require.NoError(t, err)
+
+		err = tx.Set([]byte("empty"), nil, nil)
+		require.NoError(t, err)
+
+		hdr, err := tx.Commit()
+		require.NoError(t, err)
+
+		_, err = immuStore.GetValueReader(hdr.ID, nil)
+		require.ErrorIs(t, err, ErrNullKey)
+
+		_, err = immuStore.GetValueReader(hdr.ID, []byte("non-existent"))
+		require.ErrorIs(t, err, ErrKeyNotFound)
+
+		r, err := immuStore.GetValueReader(hdr.ID, []byte("key"))
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+		_, err = io.CopyBuffer(&buf, r, make([]byte, 1024))
+		require.NoError(t, err)
+		require.Equal(t, value, buf.Bytes())
+
+		err = r.Close()
+		require.NoError(t, err)
+
+		_, err = r.Read(make([]byte, 1))
+		require.ErrorIs(t, err, ErrAlreadyClosed)
+
+		err = r.Close()
+		require.ErrorIs(t, err, ErrAlreadyClosed)
+
+		r, err = immuStore.GetValueReader(hdr.ID, []byte("empty"))
+		require.NoError(t, err)
+
+		b, err := ioutil.ReadAll(r)
+		require.NoError(t, err)
+		require.Empty(t, b)
+
+		err = r.Close()
+		require.NoError(t, err)
+
+		err = immuStore.Close()
+		require.NoError(t, err)
+	}
+}
@@ -18,6 +18,7 @@ package store
 import (
 	"crypto/sha256"
 	"encoding/binary"
+	"fmt"
 
 	"github.com/codenotary/immudb/embedded/ahtree"
 	"github.com/codenotary/immudb/embedded/htree"
@@ -27,6 +28,16 @@ func VerifyInclusion(proof *htree.InclusionProof, entryDigest, root [sha256.Size
 	return htree.VerifyInclusion(proof, entryDigest, root)
 }
 
+// VerifyBlInclusion checks the Alh of tx is included in the binary linking tree of size targetBlTxID
+// i.e. the tree whose root is the BlRoot of any transaction with such targetBlTxID
+func VerifyBlInclusion(tx *Tx, proof [][sha256.Size]byte, targetBlTxID uint64, targetBlRoot [sha256.Size]byte) bool {
+	if tx == nil || tx.header == nil || tx.header.ID == 0 || tx.header.ID > targetBlTxID {
+		return false
+	}
+
+	return ahtree.VerifyInclusion(proof, tx.header.ID, targetBlTxID, leafFor(tx.header.Alh()), targetBlRoot)
+}
+
 func VerifyLinearProof(proof *LinearProof, sourceTxID, targetTxID uint64, sourceAlh, targetAlh [sha256.Size]byte) bool {
 	if proof == nil || proof.SourceTxID != sourceTxID || proof.TargetTxID != targetTxID {
 		return false
@@ -55,26 +66,47 @@ func VerifyLinearProof(proof *LinearProof, sourceTxID, targetTxID uint64, source
 }
 
 func VerifyDualProof(proof *DualProof, sourceTxID, targetTxID uint64, sourceAlh, targetAlh [sha256.Size]byte) bool {
+	return ValidateDualProof(proof, sourceTxID, targetTxID, sourceAlh, targetAlh) == nil
+}
+
+// ValidateDualProof performs the same checks as VerifyDualProof but describes the reason of a failed verification.
+// ErrBlRootMismatch is returned when the binary linking fields (BlTxID and BlRoot) of the transaction headers
+// are not consistent with the binary linking proofs, ErrInvalidProof is returned for any other failure
+func ValidateDualProof(proof *DualProof, sourceTxID, targetTxID uint64, sourceAlh, targetAlh [sha256.Size]byte) error {
 	if proof == nil ||
 		proof.SourceTxHeader == nil ||
 		proof.TargetTxHeader == nil ||
 		proof.SourceTxHeader.ID != sourceTxID ||
 		proof.TargetTxHeader.ID != targetTxID {
-		return false
+		return ErrInvalidProof
 	}
 
 	if proof.SourceTxHeader.ID == 0 || proof.SourceTxHeader.ID > proof.TargetTxHeader.ID {
-		return false
+		return ErrInvalidProof
 	}
 
 	cSourceAlh := proof.SourceTxHeader.Alh()
 	if sourceAlh != cSourceAlh {
-		return false
+		return fmt.Errorf("%w: source alh mismatch", ErrInvalidProof)
 	}
 
 	cTargetAlh := proof.TargetTxHeader.Alh()
 	if targetAlh != cTargetAlh {
-		return false
+		return fmt.Errorf("%w: target alh mismatch", ErrInvalidProof)
+	}
+
+	err := validateBlFields(proof.SourceTxHeader)
+	if err != nil {
+		return err
+	}
+
+	err = validateBlFields(proof.TargetTxHeader)
+	if err != nil {
+		return err
+	}
+
+	if proof.SourceTxHeader.BlTxID > proof.TargetTxHeader.BlTxID {
+		return fmt.Errorf("%w: source BlTxID is greater than target BlTxID", ErrBlRootMismatch)
 	}
 
 	if sourceTxID < proof.TargetTxHeader.BlTxID {
@@ -87,7 +119,7 @@ func VerifyDualProof(proof *DualProof, sourceTxID, targetTxID uint64, sourceAlh,
 		)
 
 		if !verifies {
-			return false
+			return fmt.Errorf("%w: source tx is not included in target BlRoot", ErrBlRootMismatch)
 		}
 	}
 
@@ -101,7 +133,7 @@ func VerifyDualProof(proof *DualProof, sourceTxID, targetTxID uint64, sourceAlh,
 		)
 
 		if !verfifies {
-			return false
+			return fmt.Errorf("%w: source BlRoot is not consistent with target BlRoot", ErrBlRootMismatch)
 		}
 	}
 
@@ -114,15 +146,37 @@ func VerifyDualProof(proof *DualProof, sourceTxID, targetTxID uint64, sourceAlh,
 		)
 
 		if !verifies {
-			return false
+			return fmt.Errorf("%w: target BlTxID is not the last one included in target BlRoot", ErrBlRootMismatch)
 		}
 	}
 
+	var verifies bool
+
 	if sourceTxID < proof.TargetTxHeader.BlTxID {
-		return VerifyLinearProof(proof.LinearProof, proof.TargetTxHeader.BlTxID, targetTxID, proof.TargetBlTxAlh, targetAlh)
+		verifies = VerifyLinearProof(proof.LinearProof, proof.TargetTxHeader.BlTxID, targetTxID, proof.TargetBlTxAlh, targetAlh)
+	} else {
+		verifies = VerifyLinearProof(proof.LinearProof, sourceTxID, targetTxID, sourceAlh, targetAlh)
+	}
+
+	if !verifies {
+		return fmt.Errorf("%w: linear proof doesn't verify", ErrInvalidProof)
+	}
+
+	return nil
+}
+
+// validateBlFields checks binary linking fields are consistent within the header itself,
+// the binary linking tree can only hold transactions preceding the one being described
+func validateBlFields(hdr *TxHeader) error {
+	if hdr.BlTxID >= hdr.ID {
+		return fmt.Errorf("%w: BlTxID %d is not lower than tx %d", ErrBlRootMismatch, hdr.BlTxID, hdr.ID)
+	}
+
+	if hdr.BlTxID == 0 && hdr.BlRoot != [sha256.Size]byte{} {
+		return fmt.Errorf("%w: non-empty BlRoot with no linked transactions in tx %d", ErrBlRootMismatch, hdr.ID)
 	}
 
-	return VerifyLinearProof(proof.LinearProof, sourceTxID, targetTxID, sourceAlh, targetAlh)
+	return nil
 }
 
 func leafFor(d [sha256.Size]byte) [sha256.Size]byte {
@@ -121,6 +121,163 @@ func TestVerifyDualProofEdgeCases(t *testing.T) {
 
 		// Restore proof
 		dproof.TargetTxHeader.BlTxID--
+
+		require.NoError(t, ValidateDualProof(dproof, sourceTxID, targetTxID, sourceTx.header.Alh(), targetTx.header.Alh()))
+
+		// Alter target BlRoot, the altered header is used as the trusted one to reach binary linking checks
+		dproof.TargetTxHeader.BlRoot[0]++
+		err = ValidateDualProof(dproof, sourceTxID, targetTxID, sourceTx.header.Alh(), dproof.TargetTxHeader.Alh())
+		require.ErrorIs(t, err, ErrBlRootMismatch)
+
+		// Restore proof
+		dproof.TargetTxHeader.BlRoot[0]--
+
+		// Alter target BlTxID beyond the transaction itself
+		dproof.TargetTxHeader.BlTxID = targetTxID
+		err = ValidateDualProof(dproof, sourceTxID, targetTxID, sourceTx.header.Alh(), dproof.TargetTxHeader.Alh())
+		require.ErrorIs(t, err, ErrBlRootMismatch)
+
+		// Restore proof
+		dproof.TargetTxHeader.BlTxID = targetTx.header.BlTxID
+
+		err = ValidateDualProof(dproof, sourceTxID, targetTxID, sourceTx.header.Alh(), sha256.Sum256(nil))
+		require.ErrorIs(t, err, ErrInvalidProof)
+	}
+
+	err = ValidateDualProof(nil, 0, 0, sha256.Sum256(nil), sha256.Sum256(nil))
+	require.ErrorIs(t, err, ErrInvalidProof)
+}
+
+func TestVerifyBlInclusion(t *testing.T) {
+	require.False(t, VerifyBlInclusion(nil, nil, 0, sha256.Sum256(nil)))
+
+	opts := DefaultOptions().WithSynced(false).WithMaxLinearProofLen(0).WithMaxConcurrency(1)
+	immuStore, err := Open("data_bl_inclusion", opts)
+	require.NoError(t, err)
+	defer os.RemoveAll("data_bl_inclusion")
+
+	defer immustoreClose(t, immuStore)
+
+	txCount := 10
+
+	for i := 0; i < txCount; i++ {
+		tx, err := immuStore.NewWriteOnlyTx()
+		require.NoError(t, err)
+
+		k := make([]byte, 8)
+		binary.BigEndian.PutUint64(k, uint64(i))
+
+		err = tx.Set(k, nil, k)
+		require.NoError(t, err)
+
+		_, err = tx.AsyncCommit()
+		require.NoError(t, err)
+	}
+
+	targetTx := tempTxHolder(t, immuStore)
+
+	err = immuStore.ReadTx(uint64(txCount), targetTx)
+	require.NoError(t, err)
+	require.Equal(t, uint64(txCount-1), targetTx.BlTxID())
+
+	_, err = immuStore.BlInclusionProof(0, targetTx.BlTxID())
+	require.ErrorIs(t, err, ErrIllegalArguments)
+
+	_, err = immuStore.BlInclusionProof(uint64(txCount), targetTx.BlTxID())
+	require.ErrorIs(t, err, ErrIllegalArguments)
+
+	tx := tempTxHolder(t, immuStore)
+
+	for txID := uint64(1); txID <= targetTx.BlTxID(); txID++ {
+		err = immuStore.ReadTx(txID, tx)
+		require.NoError(t, err)
+
+		proof, err := immuStore.BlInclusionProof(txID, targetTx.BlTxID())
+		require.NoError(t, err)
+
+		require.True(t, VerifyBlInclusion(tx, proof, targetTx.BlTxID(), targetTx.BlRoot()))
+
+		require.False(t, VerifyBlInclusion(tx, proof, targetTx.BlTxID(), sha256.Sum256(nil)))
+
+		if len(proof) > 0 {
+			proof[0][0]++
+			require.False(t, VerifyBlInclusion(tx, proof, targetTx.BlTxID(), targetTx.BlRoot()))
+		}
+	}
+
+	require.False(t, VerifyBlInclusion(targetTx, nil, targetTx.BlTxID(), targetTx.BlRoot()))
+}
+
+func TestBatchVerify(t *testing.T) {
+	immuStore, cleanup, err := NewTempStore(DefaultOptions())
+	require.NoError(t, err)
+	defer cleanup()
+
+	_, err = immuStore.BatchVerify([]*EntryProof{nil})
+	require.ErrorIs(t, err, ErrIllegalArguments)
+
+	verified, err := immuStore.BatchVerify(nil)
+	require.NoError(t, err)
+	require.Empty(t, verified)
+
+	tx, err := immuStore.NewWriteOnlyTx()
+	require.NoError(t, err)
+
+	entryCount := 100
+
+	for i := 0; i < entryCount; i++ {
+		k := make([]byte, 8)
+		binary.BigEndian.PutUint64(k, uint64(i))
+
+		err = tx.Set(k, nil, k)
+		require.NoError(t, err)
+	}
+
+	hdr, err := tx.Commit()
+	require.NoError(t, err)
+
+	txHolder := tempTxHolder(t, immuStore)
+
+	err = immuStore.ReadTx(hdr.ID, txHolder)
+	require.NoError(t, err)
+
+	entryDigest, err := hdr.TxEntryDigest()
+	require.NoError(t, err)
+
+	var proofs []*EntryProof
+
+	for i, e := range txHolder.Entries() {
+		inclusionProof, err := txHolder.Proof(e.key())
+		require.NoError(t, err)
+
+		digest, err := entryDigest(e)
+		require.NoError(t, err)
+
+		proofs = append(proofs, &EntryProof{
+			InclusionProof: inclusionProof,
+			EntryIndex:     i,
+			NEntries:       hdr.NEntries,
+			Eh:             hdr.Eh,
+			Digest:         digest,
+		})
+	}
+
+	verified, err = immuStore.BatchVerify(proofs)
+	require.NoError(t, err)
+	require.Len(t, verified, entryCount)
+
+	for _, v := range verified {
+		require.True(t, v)
 	}
 
+	proofs[1].Digest = sha256.Sum256(nil)
+	proofs[2].EntryIndex = 3
+	proofs[3].InclusionProof = nil
+
+	verified, err = immuStore.BatchVerify(proofs)
+	require.NoError(t, err)
+
+	for i, v := range verified {
+		require.Equal(t, i < 1 || i > 3, v)
+	}
 }
@@ -0,0 +1,134 @@
+/*
+Copyright 2022 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package store
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+)
+
+var ErrInvalidIndex = errors.New("invalid index")
+
+// VerifiedEntry holds an entry together with the header of the transaction where it was written
+// and the proof of its inclusion in such transaction
+type VerifiedEntry struct {
+	Key      []byte
+	Value    []byte
+	Metadata *KVMetadata
+	TxHeader *TxHeader
+	Proof    *EntryProof
+}
+
+// Verify checks the entry is included in its transaction, the transaction header
+// is assumed to be verified (e.g. with a DualProof)
+func (e *VerifiedEntry) Verify() (bool, error) {
+	if e == nil || e.TxHeader == nil || e.Proof == nil {
+		return false, ErrIllegalArguments
+	}
+
+	entryDigest, err := e.TxHeader.TxEntryDigest()
+	if err != nil {
+		return false, err
+	}
+
+	digest, err := entryDigest(NewTxEntry(e.Key, e.Metadata, len(e.Value), sha256.Sum256(e.Value), 0))
+	if err != nil {
+		return false, err
+	}
+
+	return e.Proof.Digest == digest && e.Proof.Eh == e.TxHeader.Eh && e.Proof.verify(), nil
+}
+
+// GetVerifiedByIndex returns the index-th occurrence of key (1 for the first one) as found in its history,
+// deletions included, together with the proof of its inclusion in the transaction where it was written.
+// Committed transactions are indexed before the lookup
+func (s *ImmuStore) GetVerifiedByIndex(key []byte, index uint64) (*VerifiedEntry, error) {
+	if len(key) == 0 {
+		return nil, ErrNullKey
+	}
+
+	if index == 0 {
+		return nil, ErrInvalidIndex
+	}
+
+	err := s.WaitForIndexingUpto(s.TransactionCount(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	txs, _, err := s.History(key, index-1, false, 1)
+	if errors.Is(err, ErrOffsetOutOfRange) || errors.Is(err, ErrNoMoreEntries) {
+		return nil, fmt.Errorf("%w: key has less than %d occurrences", ErrInvalidIndex, index)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.fetchAllocTx()
+	if err != nil {
+		return nil, err
+	}
+	defer s.releaseAllocTx(tx)
+
+	err = s.ReadTx(txs[0], tx)
+	if err != nil {
+		return nil, err
+	}
+
+	entryIndex, err := tx.IndexOf(key)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := tx.Entries()[entryIndex]
+
+	value, err := s.ReadValue(entry)
+	if err != nil {
+		return nil, err
+	}
+
+	inclusionProof, err := tx.Proof(key)
+	if err != nil {
+		return nil, err
+	}
+
+	hdr := tx.Header()
+
+	entryDigest, err := hdr.TxEntryDigest()
+	if err != nil {
+		return nil, err
+	}
+
+	digest, err := entryDigest(entry)
+	if err != nil {
+		return nil, err
+	}
+
+	return &VerifiedEntry{
+		Key:      entry.Key(),
+		Value:    value,
+		Metadata: entry.Metadata(),
+		TxHeader: hdr,
+		Proof: &EntryProof{
+			InclusionProof: inclusionProof,
+			EntryIndex:     entryIndex,
+			NEntries:       hdr.NEntries,
+			Eh:             hdr.Eh,
+			Digest:         digest,
+		},
+	}, nil
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2022 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package store
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestImmudbStoreGetVerifiedByIndex(t *testing.T) {
+	immuStore, cleanup, err := NewTempStore(DefaultOptions())
+	require.NoError(t, err)
+	defer cleanup()
+
+	_, err = immuStore.GetVerifiedByIndex(nil, 1)
+	require.ErrorIs(t, err, ErrNullKey)
+
+	_, err = immuStore.GetVerifiedByIndex([]byte("key1"), 0)
+	require.ErrorIs(t, err, ErrInvalidIndex)
+
+	_, err = immuStore.GetVerifiedByIndex([]byte("key1"), 1)
+	require.ErrorIs(t, err, ErrKeyNotFound)
+
+	for i := 0; i < 3; i++ {
+		tx, err := immuStore.NewWriteOnlyTx()
+		require.NoError(t, err)
+
+		err = tx.Set([]byte(fmt.Sprintf("other%d", i)), nil, []byte("other"))
+		require.NoError(t, err)
+
+		err = tx.Set([]byte("key1"), nil, []byte(fmt.Sprintf("value%d", i)))
+		require.NoError(t, err)
+
+		_, err = tx.Commit()
+		require.NoError(t, err)
+	}
+
+	for i := 0; i < 3; i++ {
+		entry, err := immuStore.GetVerifiedByIndex([]byte("key1"), uint64(i+1))
+		require.NoError(t, err)
+		require.Equal(t, []byte("key1"), entry.Key)
+		require.Equal(t, []byte(fmt.Sprintf("value%d", i)), entry.Value)
+		require.Equal(t, uint64(i+1), entry.TxHeader.ID)
+		require.Equal(t, 2, entry.Proof.NEntries)
+
+		verified, err := entry.Verify()
+		require.NoError(t, err)
+		require.True(t, verified)
+
+		verifications, err := immuStore.BatchVerify([]*EntryProof{entry.Proof})
+		require.NoError(t, err)
+		require.True(t, verifications[0])
+
+		entry.Value = []byte("tampered")
+
+		verified, err = entry.Verify()
+		require.NoError(t, err)
+		require.False(t, verified)
+	}
+
+	_, err = immuStore.GetVerifiedByIndex([]byte("key1"), 4)
+	require.Contains(t, err.Error(), "less than 4 occurrences")
+	require.ErrorIs(t, err, ErrInvalidIndex)
+
+	_, err = (*VerifiedEntry)(nil).Verify()
+	require.ErrorIs(t, err, ErrIllegalArguments)
+}
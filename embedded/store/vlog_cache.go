@@ -0,0 +1,140 @@
+/*
+Copyright 2022 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package store
+
+import (
+	"io"
+	"math"
+
+	"github.com/codenotary/immudb/embedded/appendable"
+	"github.com/codenotary/immudb/embedded/cache"
+)
+
+// VLogCachePageSize is the size of the value log pages held by the cache enabled with Options.VLogCacheSize
+const VLogCachePageSize = 4096
+
+type vLogPageKey struct {
+	vLogID  byte
+	pageOff int64
+}
+
+// readVLogAt reads from vLog as vLog.ReadAt does, serving the bytes from cached pages when the cache is enabled.
+// Pages are immutable once fully written, thus the page holding the end of the value log is never cached.
+// The caller must hold vLogID
+func (s *ImmuStore) readVLogAt(vLogID byte, vLog appendable.Appendable, b []byte, off int64) (int, error) {
+	r := s.withReadDeadline(vLog)
+
+	// compressed values can not be read at arbitrary offsets
+	if s.vLogCache == nil || vLog.CompressionFormat() != appendable.NoCompression {
+		return r.ReadAt(b, off)
+	}
+
+	n := 0
+
+	for n < len(b) {
+		pageOff := (off + int64(n)) / VLogCachePageSize * VLogCachePageSize
+
+		page, err := s.vLogPage(vLogID, r, pageOff)
+		if err != nil {
+			return n, err
+		}
+		if page == nil {
+			// incomplete page
+			m, err := r.ReadAt(b[n:], off+int64(n))
+			return n + m, err
+		}
+
+		n += copy(b[n:], page[off+int64(n)-pageOff:])
+	}
+
+	return n, nil
+}
+
+// vLogPage returns the page of vLog starting at pageOff, nil is returned if it's not fully written
+func (s *ImmuStore) vLogPage(vLogID byte, vLog io.ReaderAt, pageOff int64) ([]byte, error) {
+	key := vLogPageKey{vLogID: vLogID, pageOff: pageOff}
+
+	page, err := s.vLogCache.Get(key)
+	if err == nil {
+		return page.([]byte), nil
+	}
+	if err != cache.ErrKeyNotFound {
+		return nil, err
+	}
+
+	bs := make([]byte, VLogCachePageSize)
+
+	n, err := vLog.ReadAt(bs, pageOff)
+	if n < len(bs) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	_, _, err = s.vLogCache.Put(key, bs)
+	if err != nil {
+		return nil, err
+	}
+
+	return bs, nil
+}
+
+// cachedVLog wraps a value log when the cache is enabled so its cached pages are evicted
+// whenever previously written content is changed by a truncation, a discard or a punched hole
+type cachedVLog struct {
+	appendable.Appendable
+
+	vLogID byte
+	cache  *cache.LRUCache
+}
+
+func (v *cachedVLog) SetOffset(off int64) error {
+	err := v.Appendable.SetOffset(off)
+	evictVLogPages(v.cache, v.vLogID, off, math.MaxInt64)
+	return err
+}
+
+func (v *cachedVLog) DiscardUpto(off int64) error {
+	err := v.Appendable.DiscardUpto(off)
+	evictVLogPages(v.cache, v.vLogID, 0, off)
+	return err
+}
+
+func (v *cachedVLog) PunchHole(off, length int64) error {
+	err := v.Appendable.PunchHole(off, length)
+	evictVLogPages(v.cache, v.vLogID, off, off+length)
+	return err
+}
+
+// evictVLogPages removes the cached pages of vLogID overlapping the range [from, to)
+func evictVLogPages(c *cache.LRUCache, vLogID byte, from, to int64) {
+	var keys []vLogPageKey
+
+	c.Apply(func(k, _ interface{}) error {
+		key := k.(vLogPageKey)
+
+		if key.vLogID == vLogID && key.pageOff+VLogCachePageSize > from && key.pageOff < to {
+			keys = append(keys, key)
+		}
+
+		return nil
+	})
+
+	for _, key := range keys {
+		c.Pop(key)
+	}
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2022 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package store
+
+import (
+	"crypto/rand"
+	"fmt"
+	"testing"
+
+	"github.com/codenotary/immudb/embedded/cache"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImmudbStoreVLogCache(t *testing.T) {
+	opts := DefaultOptions().
+		WithMaxIOConcurrency(1).
+		WithMaxValueLen(4 * VLogCachePageSize).
+		WithVLogCacheSize(4)
+
+	immuStore, cleanup, err := NewTempStore(opts)
+	require.NoError(t, err)
+	defer cleanup()
+
+	values := make(map[string][]byte)
+
+	for i, size := range []int{1, 100, VLogCachePageSize - 1, VLogCachePageSize + 1, 3 * VLogCachePageSize, 10} {
+		key := []byte(fmt.Sprintf("key%d", i))
+
+		value := make([]byte, size)
+		_, err = rand.Read(value)
+		require.NoError(t, err)
+
+		tx, err := immuStore.NewWriteOnlyTx()
+		require.NoError(t, err)
+
+		err = tx.Set(key, nil, value)
+		require.NoError(t, err)
+
+		_, err = tx.Commit()
+		require.NoError(t, err)
+
+		values[string(key)] = value
+
+		// values are read while the last page of the value log is being written
+		for k, v := range values {
+			valRef, err := immuStore.Get([]byte(k))
+			require.NoError(t, err)
+
+			val, err := valRef.Resolve()
+			require.NoError(t, err)
+			require.Equal(t, v, val)
+		}
+	}
+
+	require.Greater(t, immuStore.vLogCache.EntriesCount(), 0)
+	require.LessOrEqual(t, immuStore.vLogCache.EntriesCount(), 4)
+
+	t.Run("cached pages should be evicted when the value log content changes", func(t *testing.T) {
+		vLog := immuStore.vLogs[0].vLog
+		require.IsType(t, &cachedVLog{}, vLog)
+
+		firstPage := vLogPageKey{vLogID: 1, pageOff: 0}
+
+		for _, change := range []func() error{
+			func() error { return vLog.PunchHole(0, 1) },
+			func() error { return vLog.DiscardUpto(1) },
+			func() error { return vLog.SetOffset(1) },
+		} {
+			_, err := immuStore.readVLogAt(1, vLog, make([]byte, 1), 0)
+			require.NoError(t, err)
+
+			_, err = immuStore.vLogCache.Get(firstPage)
+			require.NoError(t, err)
+
+			err = change()
+			require.NoError(t, err)
+
+			_, err = immuStore.vLogCache.Get(firstPage)
+			require.ErrorIs(t, err, cache.ErrKeyNotFound)
+		}
+	})
+}
@@ -67,6 +67,11 @@ var metricsCacheEvict = promauto.NewCounterVec(prometheus.CounterOpts{
 	Help: "Number of btree nodes evicted from cache",
 }, []string{"id"})
 
+var metricsWarmedUpNodes = promauto.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "immudb_btree_warmed_up_nodes",
+	Help: "Number of btree nodes loaded into cache during the last warm up",
+}, []string{"id"})
+
 var metricsBtreeDepth = promauto.NewGaugeVec(prometheus.GaugeOpts{
 	Name: "immudb_btree_depth",
 	Help: "Btree depth",
@@ -51,6 +51,7 @@ var ErrIllegalState = errors.New("illegal state")
 var ErrAlreadyClosed = errors.New("index already closed")
 var ErrSnapshotsNotClosed = errors.New("snapshots not closed")
 var ErrorToManyActiveSnapshots = errors.New("max active snapshots limit reached")
+var ErrNoSnapshotAvailable = errors.New("no snapshot available for the requested ts")
 var ErrCorruptedFile = errors.New("file is corrupted")
 var ErrCorruptedCLog = errors.New("commit log is corrupted")
 var ErrCompactAlreadyInProgress = errors.New("compact already in progress")
@@ -979,6 +980,49 @@ func (t *TBtree) ExistKeyWith(prefix []byte, neq []byte) (bool, error) {
 	return bytes.Equal(prefix, v.key[:len(prefix)]), nil
 }
 
+// WarmUp loads up to fraction * cacheSize nodes into the cache.
+// Nodes are visited in breadth-first order so upper levels of the tree are loaded first
+func (t *TBtree) WarmUp(fraction float64) (int, error) {
+	if fraction < 0 || fraction > 1 {
+		return 0, ErrIllegalArguments
+	}
+
+	t.rwmutex.RLock()
+	defer t.rwmutex.RUnlock()
+
+	if t.closed {
+		return 0, ErrAlreadyClosed
+	}
+
+	maxNodes := int(fraction * float64(t.cacheSize))
+
+	loaded := 0
+	pending := []node{t.root}
+
+	for len(pending) > 0 && loaded < maxNodes {
+		n := pending[0]
+		pending = pending[1:]
+
+		if ref, ok := n.(*nodeRef); ok {
+			var err error
+
+			n, err = t.nodeAt(ref.off, true)
+			if err != nil {
+				return loaded, err
+			}
+
+			loaded++
+			metricsWarmedUpNodes.WithLabelValues(t.path).Set(float64(loaded))
+		}
+
+		if inner, ok := n.(*innerNode); ok {
+			pending = append(pending, inner.nodes...)
+		}
+	}
+
+	return loaded, nil
+}
+
 func (t *TBtree) Sync() error {
 	t.rwmutex.Lock()
 	defer t.rwmutex.Unlock()
@@ -1688,6 +1732,97 @@ func (t *TBtree) SnapshotSince(ts uint64) (*Snapshot, error) {
 	return snapshot, nil
 }
 
+// SnapshotAtTs returns a snapshot of the most recent persisted root whose ts is not greater than
+// the specified one. ErrNoSnapshotAvailable is returned when no such root is still available
+// e.g. because older snapshots were already discarded.
+func (t *TBtree) SnapshotAtTs(ts uint64) (*Snapshot, error) {
+	root, err := t.persistedRootAtTs(ts)
+	if err != nil {
+		return nil, err
+	}
+
+	t.rwmutex.Lock()
+	defer t.rwmutex.Unlock()
+
+	if t.closed {
+		return nil, ErrAlreadyClosed
+	}
+
+	if len(t.snapshots) == t.maxActiveSnapshots {
+		return nil, ErrorToManyActiveSnapshots
+	}
+
+	t.maxSnapshotID++
+
+	snapshot := t.newSnapshot(t.maxSnapshotID, root)
+
+	t.snapshots[snapshot.id] = snapshot
+
+	return snapshot, nil
+}
+
+// persistedRootAtTs binary searches the commit log for the most recent root whose ts is not greater than
+// the specified one, roots are committed in increasing ts order. Only the read lock is held so
+// the tree remains available for other readers during the search
+func (t *TBtree) persistedRootAtTs(ts uint64) (node, error) {
+	t.rwmutex.RLock()
+	defer t.rwmutex.RUnlock()
+
+	if t.closed {
+		return nil, ErrAlreadyClosed
+	}
+
+	var err error
+
+	rootAt := func(i int) (node, error) {
+		var b [cLogEntrySize]byte
+
+		_, err := t.cLog.ReadAt(b[:], int64(i)*cLogEntrySize)
+		if err != nil {
+			return nil, ErrNoSnapshotAvailable
+		}
+
+		cLogEntry := &cLogEntry{}
+		cLogEntry.deserialize(b[:])
+
+		if !cLogEntry.isValid() {
+			return nil, ErrNoSnapshotAvailable
+		}
+
+		n, err := t.readNodeAt(cLogEntry.finalNLogSize - int64(cLogEntry.rootNodeSize))
+		if err != nil {
+			return nil, ErrNoSnapshotAvailable
+		}
+
+		return n, nil
+	}
+
+	// index of the first root newer than ts
+	i := sort.Search(int(t.committedLogSize/cLogEntrySize), func(i int) bool {
+		if err != nil {
+			return true
+		}
+
+		var n node
+
+		n, err = rootAt(i)
+		if err != nil {
+			return true
+		}
+
+		return n.ts() > ts
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if i == 0 {
+		return nil, ErrNoSnapshotAvailable
+	}
+
+	return rootAt(i - 1)
+}
+
 func (t *TBtree) newSnapshot(snapshotID uint64, root node) *Snapshot {
 	return &Snapshot{
 		t:       t,
@@ -1171,6 +1171,51 @@ func TestTBTreeReOpen(t *testing.T) {
 	})
 }
 
+func TestTBTreeWarmUp(t *testing.T) {
+	opts := DefaultOptions().WithMaxKeySize(16).WithMaxValueSize(16).WithMaxNodeSize(256).WithCacheSize(10)
+
+	tbtree, err := Open("test_tree_warmup", opts)
+	require.NoError(t, err)
+
+	defer os.RemoveAll("test_tree_warmup")
+
+	for i := 0; i < 1000; i++ {
+		err = tbtree.Insert([]byte(fmt.Sprintf("key%04d", i)), []byte(fmt.Sprintf("value%04d", i)))
+		require.NoError(t, err)
+	}
+
+	err = tbtree.Close()
+	require.NoError(t, err)
+
+	tbtree, err = Open("test_tree_warmup", opts)
+	require.NoError(t, err)
+
+	_, err = tbtree.WarmUp(-0.1)
+	require.ErrorIs(t, err, ErrIllegalArguments)
+
+	_, err = tbtree.WarmUp(1.1)
+	require.ErrorIs(t, err, ErrIllegalArguments)
+
+	loaded, err := tbtree.WarmUp(0)
+	require.NoError(t, err)
+	require.Zero(t, loaded)
+
+	loaded, err = tbtree.WarmUp(0.5)
+	require.NoError(t, err)
+	require.Equal(t, 5, loaded)
+
+	loaded, err = tbtree.WarmUp(1)
+	require.NoError(t, err)
+	require.Equal(t, 10, loaded)
+	require.Equal(t, 10, tbtree.cache.EntriesCount())
+
+	err = tbtree.Close()
+	require.NoError(t, err)
+
+	_, err = tbtree.WarmUp(1)
+	require.ErrorIs(t, err, ErrAlreadyClosed)
+}
+
 func TestTBTreeSelfHealingHistory(t *testing.T) {
 	tbtree, err := Open("test_tree_self_healing_history", DefaultOptions())
 	require.NoError(t, err)
@@ -1486,3 +1531,56 @@ func TestLastUpdateBetween(t *testing.T) {
 		}
 	}
 }
+
+func TestSnapshotAtTs(t *testing.T) {
+	d, err := ioutil.TempDir("", "test_tree_snapshot_at_ts")
+	require.NoError(t, err)
+	defer os.RemoveAll(d)
+
+	tree, err := Open(d, DefaultOptions())
+	require.NoError(t, err)
+
+	_, err = tree.SnapshotAtTs(0)
+	require.ErrorIs(t, err, ErrNoSnapshotAvailable)
+
+	for i := 0; i < 3; i++ {
+		err = tree.Insert([]byte("key1"), []byte(fmt.Sprintf("value%d", i)))
+		require.NoError(t, err)
+
+		_, _, err = tree.Flush()
+		require.NoError(t, err)
+	}
+
+	err = tree.Insert([]byte("key1"), []byte("unflushed"))
+	require.NoError(t, err)
+
+	_, err = tree.SnapshotAtTs(0)
+	require.ErrorIs(t, err, ErrNoSnapshotAvailable)
+
+	for i := 0; i < 3; i++ {
+		snap, err := tree.SnapshotAtTs(uint64(i + 1))
+		require.NoError(t, err)
+		require.Equal(t, uint64(i+1), snap.Ts())
+
+		v, ts, _, err := snap.Get([]byte("key1"))
+		require.NoError(t, err)
+		require.Equal(t, []byte(fmt.Sprintf("value%d", i)), v)
+		require.Equal(t, uint64(i+1), ts)
+
+		err = snap.Close()
+		require.NoError(t, err)
+	}
+
+	snap, err := tree.SnapshotAtTs(100)
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), snap.Ts())
+
+	err = snap.Close()
+	require.NoError(t, err)
+
+	err = tree.Close()
+	require.NoError(t, err)
+
+	_, err = tree.SnapshotAtTs(1)
+	require.ErrorIs(t, err, ErrAlreadyClosed)
+}
@@ -148,7 +148,7 @@ func main() {
 		fmt.Printf("\r\nImmutable Transactional Key-Value Log successfully closed!\r\n")
 	}()
 
-	fmt.Printf("Immutable Transactional Key-Value Log with %d Txs successfully opened!\r\n", immuStore.TxCount())
+	fmt.Printf("Immutable Transactional Key-Value Log with %d Txs successfully opened!\r\n", immuStore.TransactionCount())
 
 	if *mode == "interactive" {
 		if *action == "get" {
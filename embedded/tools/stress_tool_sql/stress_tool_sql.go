@@ -139,7 +139,7 @@ func main() {
 	}()
 
 	for name, store := range map[string]*store.ImmuStore{"data": dataStore} {
-		log.Printf("Store %s with %d Txs successfully opened!\r\n", name, store.TxCount())
+		log.Printf("Store %s with %d Txs successfully opened!\r\n", name, store.TransactionCount())
 	}
 
 	engine, err := sql.NewEngine(dataStore, sql.DefaultOptions().WithPrefix([]byte("sql")))
@@ -21,6 +21,7 @@ import (
 	"encoding/binary"
 	"errors"
 
+	"github.com/codenotary/immudb/embedded/store"
 	"github.com/codenotary/immudb/pkg/signer"
 )
 
@@ -49,3 +50,33 @@ func (state *ImmutableState) CheckSignature(key *ecdsa.PublicKey) (ok bool, err
 	}
 	return signer.Verify(state.ToBytes(), state.Signature.Signature, key)
 }
+
+// Verify checks state is a valid successor of prevState according to the provided linear proof.
+// ErrStateMismatch is returned when both states do not belong to the same database.
+// Note: the server UUID is not part of the state, it must be validated by the caller
+func (state *ImmutableState) Verify(proof *LinearProof, prevState *ImmutableState) (ok bool, err error) {
+	if prevState == nil {
+		return false, errors.New("no previous state provided")
+	}
+
+	if state.Db != prevState.Db {
+		return false, ErrStateMismatch
+	}
+
+	if prevState.TxId == 0 {
+		// nothing to verify against
+		return true, nil
+	}
+
+	if proof == nil || len(prevState.TxHash) != sha256.Size || len(state.TxHash) != sha256.Size {
+		return false, nil
+	}
+
+	return store.VerifyLinearProof(
+		LinearProofFromProto(proof),
+		prevState.TxId,
+		state.TxId,
+		DigestFromProto(prevState.TxHash),
+		DigestFromProto(state.TxHash),
+	), nil
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2022 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package schema
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/codenotary/immudb/embedded/store"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImmutableStateVerify(t *testing.T) {
+	st, cleanup, err := store.NewTempStore(store.DefaultOptions())
+	require.NoError(t, err)
+	defer cleanup()
+
+	stateAt := func(txID uint64) *ImmutableState {
+		hdr, err := st.ReadTxHeader(txID)
+		require.NoError(t, err)
+
+		alh := hdr.Alh()
+
+		return &ImmutableState{Db: "db1", TxId: txID, TxHash: alh[:]}
+	}
+
+	for i := 0; i < 3; i++ {
+		tx, err := st.NewWriteOnlyTx()
+		require.NoError(t, err)
+
+		err = tx.Set([]byte(fmt.Sprintf("key%d", i)), nil, []byte("value"))
+		require.NoError(t, err)
+
+		_, err = tx.AsyncCommit()
+		require.NoError(t, err)
+	}
+
+	lproof, err := st.LinearProof(1, 3)
+	require.NoError(t, err)
+
+	proof := LinearProofToProto(lproof)
+
+	prevState := stateAt(1)
+	newState := stateAt(3)
+
+	_, err = newState.Verify(proof, nil)
+	require.Error(t, err)
+
+	ok, err := newState.Verify(proof, prevState)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	ok, err = newState.Verify(nil, prevState)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	ok, err = stateAt(2).Verify(proof, prevState)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	ok, err = prevState.Verify(proof, newState)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	ok, err = newState.Verify(nil, &ImmutableState{Db: "db1"})
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	_, err = newState.Verify(proof, &ImmutableState{Db: "db2", TxId: 1, TxHash: prevState.TxHash})
+	require.ErrorIs(t, err, ErrStateMismatch)
+}
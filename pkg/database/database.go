@@ -74,6 +74,7 @@ type DB interface {
 
 	Get(req *schema.KeyRequest) (*schema.Entry, error)
 	VerifiableGet(req *schema.VerifiableGetRequest) (*schema.VerifiableEntry, error)
+	VerifiedGetAtTx(key []byte, txID uint64, state *schema.ImmutableState) (*VerifiedItem, error)
 	GetAll(req *schema.KeyListRequest) (*schema.Entries, error)
 
 	Delete(req *schema.DeleteKeysRequest) (*schema.TxHeader, error)
@@ -93,6 +94,12 @@ type DB interface {
 	ZAdd(req *schema.ZAddRequest) (*schema.TxHeader, error)
 	VerifiableZAdd(req *schema.VerifiableZAddRequest) (*schema.VerifiableTx, error)
 	ZScan(req *schema.ZScanRequest) (*schema.ZEntries, error)
+	ZScanHistory(set []byte, member []byte) ([]*ZHistoryEntry, error)
+	ZScore(ctx context.Context, set []byte, member []byte) (float64, uint64, error)
+	ZUnionStore(destSet []byte, srcSets [][]byte, weights []float64) (uint64, error)
+	ListSets() ([][]byte, error)
+	ListSetsPaged(cursor []byte, limit int) ([][]byte, error)
+	AtomicSortedSetUpdate(set []byte, fn func(*SortedSetBatch) error) (uint64, error)
 
 	// SQL-related
 	NewSQLTx(ctx context.Context) (*sql.SQLTx, error)
@@ -148,6 +155,8 @@ type db struct {
 	maxResultSize int
 
 	txPool store.TxPool
+
+	setLocks setLocks
 }
 
 // OpenDB Opens an existing Database from disk
@@ -817,6 +826,98 @@ func (d *db) VerifiableGet(req *schema.VerifiableGetRequest) (*schema.Verifiable
 	}, nil
 }
 
+// VerifiedItem holds an entry whose inclusion and consistency with a trusted state were verified
+type VerifiedItem struct {
+	Entry *schema.Entry
+	Tx    uint64
+}
+
+// VerifiedGetAtTx retrieves key at transaction txID (or its latest value when txID is zero) and verifies it
+// against the trusted state. An empty state (TxId equal to zero) is trusted on first use.
+// On success the state is updated to the newest verified transaction,
+// store.ErrCorruptedData is returned and the state is left untouched if verification fails
+func (d *db) VerifiedGetAtTx(key []byte, txID uint64, state *schema.ImmutableState) (*VerifiedItem, error) {
+	if len(key) == 0 || state == nil || (state.Db != "" && state.Db != d.name) {
+		return nil, ErrIllegalArguments
+	}
+
+	kReq := &schema.KeyRequest{Key: key, AtTx: txID}
+
+	vEntry, err := d.VerifiableGet(&schema.VerifiableGetRequest{
+		KeyRequest:   kReq,
+		ProveSinceTx: state.TxId,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	entrySpecDigest, err := store.EntrySpecDigestFor(int(vEntry.VerifiableTx.Tx.Header.Version))
+	if err != nil {
+		return nil, err
+	}
+
+	inclusionProof := schema.InclusionProofFromProto(vEntry.InclusionProof)
+	dualProof := schema.DualProofFromProto(vEntry.VerifiableTx.DualProof)
+
+	var eh [sha256.Size]byte
+
+	var sourceID, targetID uint64
+	var sourceAlh, targetAlh [sha256.Size]byte
+
+	vTx := txID
+	var e *store.EntrySpec
+
+	if vEntry.Entry.ReferencedBy == nil {
+		if txID == 0 {
+			vTx = vEntry.Entry.Tx
+		}
+
+		e = EncodeEntrySpec(key, schema.KVMetadataFromProto(vEntry.Entry.Metadata), vEntry.Entry.Value)
+	} else {
+		ref := vEntry.Entry.ReferencedBy
+
+		if txID == 0 {
+			vTx = ref.Tx
+		}
+
+		e = EncodeReference(key, schema.KVMetadataFromProto(ref.Metadata), vEntry.Entry.Key, ref.AtTx)
+	}
+
+	if state.TxId <= vTx {
+		eh = dualProof.TargetTxHeader.Eh
+
+		sourceID = state.TxId
+		sourceAlh = schema.DigestFromProto(state.TxHash)
+		targetID = vTx
+		targetAlh = dualProof.TargetTxHeader.Alh()
+	} else {
+		eh = dualProof.SourceTxHeader.Eh
+
+		sourceID = vTx
+		sourceAlh = dualProof.SourceTxHeader.Alh()
+		targetID = state.TxId
+		targetAlh = schema.DigestFromProto(state.TxHash)
+	}
+
+	if !store.VerifyInclusion(inclusionProof, entrySpecDigest(e), eh) {
+		return nil, store.ErrCorruptedData
+	}
+
+	if state.TxId > 0 && !store.VerifyDualProof(dualProof, sourceID, targetID, sourceAlh, targetAlh) {
+		return nil, store.ErrCorruptedData
+	}
+
+	state.Db = d.name
+	state.TxId = targetID
+	state.TxHash = targetAlh[:]
+	state.Signature = nil
+
+	return &VerifiedItem{
+		Entry: vEntry.Entry,
+		Tx:    vTx,
+	}, nil
+}
+
 func (d *db) Delete(req *schema.DeleteKeysRequest) (*schema.TxHeader, error) {
 	if req == nil {
 		return nil, ErrIllegalArguments
@@ -923,7 +1024,7 @@ func (d *db) GetAll(req *schema.KeyListRequest) (*schema.Entries, error) {
 
 //Size ...
 func (d *db) Size() (uint64, error) {
-	return d.st.TxCount(), nil
+	return d.st.TransactionCount(), nil
 }
 
 //Count ...
@@ -2241,3 +2241,63 @@ func TestDb_SetBatchAtomicOperations(t *testing.T) {
 	require.NoError(t, err)
 }
 */
+
+func TestVerifiedGetAtTx(t *testing.T) {
+	db, closer := makeDb()
+	defer closer()
+
+	_, err := db.VerifiedGetAtTx(nil, 0, &schema.ImmutableState{})
+	require.ErrorIs(t, err, ErrIllegalArguments)
+
+	_, err = db.VerifiedGetAtTx([]byte("key1"), 0, nil)
+	require.ErrorIs(t, err, ErrIllegalArguments)
+
+	_, err = db.VerifiedGetAtTx([]byte("key1"), 0, &schema.ImmutableState{Db: "otherdb"})
+	require.ErrorIs(t, err, ErrIllegalArguments)
+
+	set := func(key, value string) uint64 {
+		hdr, err := db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte(key), Value: []byte(value)}}})
+		require.NoError(t, err)
+		return hdr.Id
+	}
+
+	tx1 := set("key1", "value1")
+
+	state := &schema.ImmutableState{}
+
+	item, err := db.VerifiedGetAtTx([]byte("key1"), 0, state)
+	require.NoError(t, err)
+	require.Equal(t, tx1, item.Tx)
+	require.Equal(t, []byte("value1"), item.Entry.Value)
+	require.Equal(t, db.GetName(), state.Db)
+	require.Equal(t, tx1, state.TxId)
+
+	tx2 := set("key1", "value2")
+	tx3 := set("key2", "value3")
+
+	item, err = db.VerifiedGetAtTx([]byte("key2"), 0, state)
+	require.NoError(t, err)
+	require.Equal(t, tx3, item.Tx)
+	require.Equal(t, tx3, state.TxId)
+
+	hdr, err := db.st.ReadTxHeader(tx3)
+	require.NoError(t, err)
+	alh := hdr.Alh()
+	require.Equal(t, alh[:], state.TxHash)
+
+	// older transactions are verified against the newer trusted state
+	item, err = db.VerifiedGetAtTx([]byte("key1"), tx2, state)
+	require.NoError(t, err)
+	require.Equal(t, tx2, item.Tx)
+	require.Equal(t, []byte("value2"), item.Entry.Value)
+	require.Equal(t, tx3, state.TxId)
+
+	tamperedState := &schema.ImmutableState{
+		TxId:   tx2,
+		TxHash: make([]byte, sha256.Size),
+	}
+
+	_, err = db.VerifiedGetAtTx([]byte("key2"), 0, tamperedState)
+	require.ErrorIs(t, err, store.ErrCorruptedData)
+	require.Equal(t, tx2, tamperedState.TxId)
+}
@@ -16,9 +16,13 @@ limitations under the License.
 package database
 
 import (
+	"bytes"
+	"context"
 	"encoding/binary"
 	"fmt"
 	"math"
+	"sort"
+	"time"
 
 	"github.com/codenotary/immudb/embedded/store"
 	"github.com/codenotary/immudb/pkg/api/schema"
@@ -245,6 +249,600 @@ func (d *db) ZScan(req *schema.ZScanRequest) (*schema.ZEntries, error) {
 	return entries, nil
 }
 
+// ZHistoryEntry describes a score assigned to a member of a sorted set
+type ZHistoryEntry struct {
+	Score float64
+	TxID  uint64
+	Ts    time.Time
+}
+
+// ZScanHistory returns every score assigned to member in the sorted set, ordered by transaction
+func (d *db) ZScanHistory(set []byte, member []byte) ([]*ZHistoryEntry, error) {
+	if len(set) == 0 || len(member) == 0 {
+		return nil, store.ErrIllegalArguments
+	}
+
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+
+	currTxID, _ := d.st.Alh()
+
+	err := d.st.WaitForIndexingUpto(currTxID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	snap, err := d.st.SnapshotSince(currTxID)
+	if err != nil {
+		return nil, err
+	}
+	defer snap.Close()
+
+	prefix := make([]byte, 1+setLenLen+len(set))
+	prefix[0] = SortedSetKeyPrefix
+	binary.BigEndian.PutUint64(prefix[1:], uint64(len(set)))
+	copy(prefix[1+setLenLen:], set)
+
+	// deleted or expired entries are kept as they are part of the history
+	r, err := snap.NewKeyReader(&store.KeyReaderSpec{
+		SeekKey: prefix,
+		Prefix:  prefix,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	key := EncodeKey(member)
+
+	var entries []*ZHistoryEntry
+
+	for {
+		zKey, _, err := r.Read()
+		if err == store.ErrNoMoreEntries {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		// zKey = [1+setLenLen+len(set)+scoreLen+keyLenLen+1+len(member)+txIDLen]
+		scoreOff := 1 + setLenLen + len(set)
+		keyOff := scoreOff + scoreLen + keyLenLen
+
+		if len(zKey) != keyOff+len(key)+txIDLen || !bytes.Equal(zKey[keyOff:keyOff+len(key)], key) {
+			continue
+		}
+
+		score := math.Float64frombits(binary.BigEndian.Uint64(zKey[scoreOff:]))
+
+		txs, _, err := d.st.History(zKey, 0, false, d.maxResultSize)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, txID := range txs {
+			hdr, err := d.st.ReadTxHeader(txID)
+			if err != nil {
+				return nil, err
+			}
+
+			entries = append(entries, &ZHistoryEntry{
+				Score: score,
+				TxID:  txID,
+				Ts:    time.Unix(hdr.Ts, 0),
+			})
+		}
+
+		if len(entries) > d.maxResultSize {
+			return nil, fmt.Errorf("%w: found more than %d entries (the maximum limit)",
+				ErrResultSizeLimitReached, d.maxResultSize)
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].TxID < entries[j].TxID
+	})
+
+	return entries, nil
+}
+
+// ZScore returns the score assigned to member by the latest ZAdd in the sorted set and the id of such transaction.
+// Sorted set keys are ordered by score, thus every entry of the set is read to find the latest one of the member,
+// ErrResultSizeLimitReached is returned when the set has more than maxResultSize entries
+func (d *db) ZScore(ctx context.Context, set []byte, member []byte) (float64, uint64, error) {
+	if ctx == nil || len(set) == 0 || len(member) == 0 {
+		return 0, 0, store.ErrIllegalArguments
+	}
+
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+
+	currTxID, _ := d.st.Alh()
+
+	err := d.st.WaitForIndexingUpto(currTxID, nil)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	snap, err := d.st.SnapshotSince(currTxID)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer snap.Close()
+
+	prefix := make([]byte, 1+setLenLen+len(set))
+	prefix[0] = SortedSetKeyPrefix
+	binary.BigEndian.PutUint64(prefix[1:], uint64(len(set)))
+	copy(prefix[1+setLenLen:], set)
+
+	r, err := snap.NewKeyReader(&store.KeyReaderSpec{
+		SeekKey: prefix,
+		Prefix:  prefix,
+		Filters: []store.FilterFn{store.IgnoreExpired, store.IgnoreDeleted},
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	defer r.Close()
+
+	key := EncodeKey(member)
+
+	var score float64
+	var txID uint64
+
+	for read := 0; ; read++ {
+		err = ctx.Err()
+		if err != nil {
+			return 0, 0, err
+		}
+
+		zKey, valRef, err := r.Read()
+		if err == store.ErrNoMoreEntries {
+			break
+		}
+		if err != nil {
+			return 0, 0, err
+		}
+
+		if read == d.maxResultSize {
+			return 0, 0, fmt.Errorf("%w: the set has more than %d entries (the maximum limit)",
+				ErrResultSizeLimitReached, d.maxResultSize)
+		}
+
+		scoreOff := 1 + setLenLen + len(set)
+		keyOff := scoreOff + scoreLen + keyLenLen
+
+		if len(zKey) != keyOff+len(key)+txIDLen || !bytes.Equal(zKey[keyOff:keyOff+len(key)], key) {
+			continue
+		}
+
+		if valRef.Tx() > txID {
+			score = math.Float64frombits(binary.BigEndian.Uint64(zKey[scoreOff:]))
+			txID = valRef.Tx()
+		}
+	}
+
+	if txID == 0 {
+		return 0, 0, store.ErrKeyNotFound
+	}
+
+	return score, txID, nil
+}
+
+type zMember struct {
+	key   []byte
+	score float64
+	atTx  uint64
+	txID  uint64
+}
+
+// latestScores returns, indexed by the encoded key, the score assigned by the latest ZAdd to each member of the set
+func latestScores(snap *store.Snapshot, set []byte) (map[string]*zMember, error) {
+	prefix := make([]byte, 1+setLenLen+len(set))
+	prefix[0] = SortedSetKeyPrefix
+	binary.BigEndian.PutUint64(prefix[1:], uint64(len(set)))
+	copy(prefix[1+setLenLen:], set)
+
+	r, err := snap.NewKeyReader(&store.KeyReaderSpec{
+		SeekKey: prefix,
+		Prefix:  prefix,
+		Filters: []store.FilterFn{store.IgnoreExpired, store.IgnoreDeleted},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	members := make(map[string]*zMember)
+
+	for {
+		zKey, valRef, err := r.Read()
+		if err == store.ErrNoMoreEntries {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		scoreOff := 1 + setLenLen + len(set)
+		keyOff := scoreOff + scoreLen + keyLenLen
+
+		key := zKey[keyOff : len(zKey)-txIDLen]
+
+		m, ok := members[string(key)]
+		if ok && m.txID > valRef.Tx() {
+			continue
+		}
+
+		members[string(key)] = &zMember{
+			key:   append([]byte{}, key...),
+			score: math.Float64frombits(binary.BigEndian.Uint64(zKey[scoreOff:])),
+			atTx:  binary.BigEndian.Uint64(zKey[len(zKey)-txIDLen:]),
+			txID:  valRef.Tx(),
+		}
+	}
+
+	return members, nil
+}
+
+func clampScore(score float64) float64 {
+	if score > math.MaxFloat64 {
+		return math.MaxFloat64
+	}
+	if score < -math.MaxFloat64 {
+		return -math.MaxFloat64
+	}
+	return score
+}
+
+// ZUnionStore stores in destSet the union of the members of srcSets, as in Redis ZUNIONSTORE.
+// The current score of a member in each source set is multiplied by the weight of the set (1 if weights is nil)
+// and the scores of the same member are summed up, overflowing scores are clamped to math.MaxFloat64.
+// destSet is overwritten: its current entries not included in the union are deleted within the same transaction.
+// A member added to the source sets with different versions of the referenced key keeps the most recently added one
+func (d *db) ZUnionStore(destSet []byte, srcSets [][]byte, weights []float64) (uint64, error) {
+	if len(destSet) == 0 || len(srcSets) == 0 || (weights != nil && len(weights) != len(srcSets)) {
+		return 0, store.ErrIllegalArguments
+	}
+
+	for _, set := range srcSets {
+		if len(set) == 0 {
+			return 0, store.ErrIllegalArguments
+		}
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if d.isReplica() {
+		return 0, ErrIsReplica
+	}
+
+	currTxID, _ := d.st.Alh()
+
+	err := d.st.WaitForIndexingUpto(currTxID, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	snap, err := d.st.SnapshotSince(currTxID)
+	if err != nil {
+		return 0, err
+	}
+	defer snap.Close()
+
+	union := make(map[string]*zMember)
+
+	for i, set := range srcSets {
+		weight := float64(1)
+		if weights != nil {
+			weight = weights[i]
+		}
+
+		members, err := latestScores(snap, set)
+		if err != nil {
+			return 0, err
+		}
+
+		for k, m := range members {
+			score := clampScore(m.score * weight)
+
+			u, ok := union[k]
+			if !ok {
+				m.score = score
+				union[k] = m
+				continue
+			}
+
+			u.score = clampScore(u.score + score)
+
+			if m.txID > u.txID {
+				u.atTx = m.atTx
+				u.txID = m.txID
+			}
+		}
+	}
+
+	prefix := make([]byte, 1+setLenLen+len(destSet))
+	prefix[0] = SortedSetKeyPrefix
+	binary.BigEndian.PutUint64(prefix[1:], uint64(len(destSet)))
+	copy(prefix[1+setLenLen:], destSet)
+
+	r, err := snap.NewKeyReader(&store.KeyReaderSpec{
+		SeekKey: prefix,
+		Prefix:  prefix,
+		Filters: []store.FilterFn{store.IgnoreExpired, store.IgnoreDeleted},
+	})
+	if err != nil {
+		return 0, err
+	}
+	defer r.Close()
+
+	var currKeys [][]byte
+
+	for {
+		zKey, _, err := r.Read()
+		if err == store.ErrNoMoreEntries {
+			break
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		currKeys = append(currKeys, append([]byte{}, zKey...))
+	}
+
+	newKeys := make(map[string]struct{}, len(union))
+
+	keys := make([]string, 0, len(union))
+	for k := range union {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	entries := make([]*store.EntrySpec, 0, len(union))
+
+	for _, k := range keys {
+		m := union[k]
+
+		e := EncodeZAdd(destSet, m.score, m.key, m.atTx)
+
+		entries = append(entries, e)
+		newKeys[string(e.Key)] = struct{}{}
+	}
+
+	var removedKeys [][]byte
+
+	for _, zKey := range currKeys {
+		_, ok := newKeys[string(zKey)]
+		if !ok {
+			removedKeys = append(removedKeys, zKey)
+		}
+	}
+
+	if len(entries)+len(removedKeys) > d.st.MaxTxEntries() {
+		return 0, fmt.Errorf("%w: storing %d members and removing %d from the destination set requires more "+
+			"than the maximum number of entries per transaction (%d)",
+			store.ErrorMaxTxEntriesLimitExceeded, len(entries), len(removedKeys), d.st.MaxTxEntries())
+	}
+
+	tx, err := d.st.NewWriteOnlyTx()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Cancel()
+
+	for _, e := range entries {
+		err = tx.Set(e.Key, e.Metadata, e.Value)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	for _, zKey := range removedKeys {
+		md := store.NewKVMetadata()
+		md.AsDeleted(true)
+
+		err = tx.Set(zKey, md, nil)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	hdr, err := tx.Commit()
+	if err != nil {
+		return 0, err
+	}
+
+	return hdr.ID, nil
+}
+
+// ListSets returns, in lexicographic order, the names of the sorted sets with at least one member.
+// At most maxResultSize names are returned, the remaining ones can be listed with ListSetsPaged
+// using the last name returned as cursor
+func (d *db) ListSets() ([][]byte, error) {
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+
+	return d.listSets(nil, d.maxResultSize)
+}
+
+// ListSetsPaged returns, in lexicographic order, up to limit sorted set names following cursor.
+// An empty cursor starts from the first set, the last name returned is the cursor of the next page
+func (d *db) ListSetsPaged(cursor []byte, limit int) ([][]byte, error) {
+	if limit < 0 {
+		return nil, store.ErrIllegalArguments
+	}
+
+	if limit > d.maxResultSize {
+		return nil, fmt.Errorf("%w: the specified limit (%d) is larger than the maximum allowed one (%d)",
+			ErrResultSizeLimitExceeded, limit, d.maxResultSize)
+	}
+
+	if limit == 0 {
+		limit = d.maxResultSize
+	}
+
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+
+	return d.listSets(cursor, limit)
+}
+
+// listSets returns, in lexicographic order, up to limit names of the sorted sets following cursor.
+// Set keys are sorted by the length of the set name first, so the sets of each name length are
+// traversed side by side and merged. Only the first member of each set is read, the remaining ones
+// are skipped by seeking past them
+func (d *db) listSets(cursor []byte, limit int) ([][]byte, error) {
+	currTxID, _ := d.st.Alh()
+
+	err := d.st.WaitForIndexingUpto(currTxID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	snap, err := d.st.SnapshotSince(currTxID)
+	if err != nil {
+		return nil, err
+	}
+	defer snap.Close()
+
+	// heads holds, for each length of set names, the first set following cursor
+	var heads [][]byte
+
+	seekKey := []byte{SortedSetKeyPrefix}
+
+	for {
+		set, err := firstSetFrom(snap, seekKey, true)
+		if err == store.ErrNoMoreEntries {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		head, err := firstSetOfLenAfter(snap, len(set), cursor)
+		if err != nil {
+			return nil, err
+		}
+		if head != nil {
+			heads = append(heads, head)
+		}
+
+		seekKey = setKeysFrom(len(set)+1, nil)
+	}
+
+	var sets [][]byte
+
+	for len(sets) < limit && len(heads) > 0 {
+		min := 0
+		for i := 1; i < len(heads); i++ {
+			if bytes.Compare(heads[i], heads[min]) < 0 {
+				min = i
+			}
+		}
+
+		set := heads[min]
+		sets = append(sets, set)
+
+		next, err := firstSetOfLenAfter(snap, len(set), set)
+		if err != nil {
+			return nil, err
+		}
+
+		if next == nil {
+			heads = append(heads[:min], heads[min+1:]...)
+		} else {
+			heads[min] = next
+		}
+	}
+
+	return sets, nil
+}
+
+// firstSetOfLenAfter returns the first sorted set whose name has setLen bytes and follows cursor
+// in lexicographic order, nil is returned if there is none. An empty cursor precedes every set
+func firstSetOfLenAfter(snap *store.Snapshot, setLen int, cursor []byte) ([]byte, error) {
+	var seekKey []byte
+
+	if len(cursor) == 0 || setLen > len(cursor) {
+		// names starting with cursor are greater than it
+		seekKey = setKeysFrom(setLen, cursor)
+	} else {
+		// names of the same length as the cursor prefix must be greater than it
+		seekKey = setKeysEnd(cursor[:setLen])
+	}
+
+	set, err := firstSetFrom(snap, seekKey, true)
+	if err == store.ErrNoMoreEntries {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(set) != setLen {
+		return nil, nil
+	}
+
+	return set, nil
+}
+
+// firstSetFrom returns the name of the first sorted set with a member from seekKey onwards
+func firstSetFrom(snap *store.Snapshot, seekKey []byte, inclusiveSeek bool) ([]byte, error) {
+	prefix := []byte{SortedSetKeyPrefix}
+
+	r, err := snap.NewKeyReader(&store.KeyReaderSpec{
+		SeekKey:       seekKey,
+		InclusiveSeek: inclusiveSeek,
+		Prefix:        prefix,
+		Filters:       []store.FilterFn{store.IgnoreExpired, store.IgnoreDeleted},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	zKey, _, err := r.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	// zKey = [1+setLenLen+len(set)+scoreLen+keyLenLen+1+len(key)+txIDLen]
+	setLen := binary.BigEndian.Uint64(zKey[1:])
+
+	return append([]byte{}, zKey[1+setLenLen:1+setLenLen+setLen]...), nil
+}
+
+// setKeysFrom returns the smallest key of the sets whose name has setLen bytes and starts with namePrefix
+func setKeysFrom(setLen int, namePrefix []byte) []byte {
+	k := make([]byte, 1+setLenLen+len(namePrefix))
+	k[0] = SortedSetKeyPrefix
+	binary.BigEndian.PutUint64(k[1:], uint64(setLen))
+	copy(k[1+setLenLen:], namePrefix)
+
+	return k
+}
+
+// setKeysEnd returns the smallest key greater than the keys of every member of set,
+// the set is prefixed by its length so no key of another set starts with the same bytes
+func setKeysEnd(set []byte) []byte {
+	k := make([]byte, 1+setLenLen+len(set))
+	k[0] = SortedSetKeyPrefix
+	binary.BigEndian.PutUint64(k[1:], uint64(len(set)))
+	copy(k[1+setLenLen:], set)
+
+	i := len(k) - 1
+	for k[i] == 0xFF {
+		i--
+	}
+
+	k[i]++
+
+	return k[:i+1]
+}
+
 //VerifiableZAdd ...
 func (d *db) VerifiableZAdd(req *schema.VerifiableZAddRequest) (*schema.VerifiableTx, error) {
 	if req == nil {
@@ -0,0 +1,194 @@
+/*
+Copyright 2022 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package database
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/codenotary/immudb/embedded/store"
+)
+
+// SortedSetBatch holds the scores of a sorted set read and updated within AtomicSortedSetUpdate
+type SortedSetBatch struct {
+	members map[string]*zMember
+	updated map[string]float64
+}
+
+// GetScore returns the current score of member, including the ones set within the batch
+func (b *SortedSetBatch) GetScore(member []byte) (float64, bool) {
+	key := string(EncodeKey(member))
+
+	score, ok := b.updated[key]
+	if ok {
+		return score, true
+	}
+
+	m, ok := b.members[key]
+	if !ok {
+		return 0, false
+	}
+
+	return m.score, true
+}
+
+// SetScore assigns score to member once the batch is committed, member must be an existing key
+func (b *SortedSetBatch) SetScore(member []byte, score float64) {
+	b.updated[string(EncodeKey(member))] = score
+}
+
+// setLocks provides a mutex for each sorted set, released entries are removed
+type setLocks struct {
+	locks map[string]*setLock
+	mutex sync.Mutex
+}
+
+type setLock struct {
+	refs  int
+	mutex sync.Mutex
+}
+
+func (l *setLocks) lock(set []byte) func() {
+	l.mutex.Lock()
+
+	if l.locks == nil {
+		l.locks = make(map[string]*setLock)
+	}
+
+	sl, ok := l.locks[string(set)]
+	if !ok {
+		sl = &setLock{}
+		l.locks[string(set)] = sl
+	}
+
+	sl.refs++
+
+	l.mutex.Unlock()
+
+	sl.mutex.Lock()
+
+	return func() {
+		sl.mutex.Unlock()
+
+		l.mutex.Lock()
+		defer l.mutex.Unlock()
+
+		sl.refs--
+
+		if sl.refs == 0 {
+			delete(l.locks, string(set))
+		}
+	}
+}
+
+// AtomicSortedSetUpdate runs fn with the current scores of the members of set and commits the scores
+// assigned within fn in a single transaction. No other update of the set happens while fn is running.
+// Nothing is committed when fn returns an error, which is then returned.
+// It returns the id of the committed transaction, 0 if no score was set
+func (d *db) AtomicSortedSetUpdate(set []byte, fn func(*SortedSetBatch) error) (uint64, error) {
+	if len(set) == 0 || fn == nil {
+		return 0, store.ErrIllegalArguments
+	}
+
+	unlock := d.setLocks.lock(set)
+	defer unlock()
+
+	// sorted sets are otherwise updated while holding the exclusive lock
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+
+	if d.isReplica() {
+		return 0, ErrIsReplica
+	}
+
+	currTxID, _ := d.st.Alh()
+
+	err := d.st.WaitForIndexingUpto(currTxID, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	snap, err := d.st.SnapshotSince(currTxID)
+	if err != nil {
+		return 0, err
+	}
+
+	members, err := latestScores(snap, set)
+	snap.Close()
+	if err != nil {
+		return 0, err
+	}
+
+	batch := &SortedSetBatch{
+		members: members,
+		updated: make(map[string]float64),
+	}
+
+	err = fn(batch)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(batch.updated) == 0 {
+		return 0, nil
+	}
+
+	keys := make([]string, 0, len(batch.updated))
+	for k := range batch.updated {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	tx, err := d.st.NewWriteOnlyTx()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Cancel()
+
+	for _, k := range keys {
+		key := []byte(k)
+
+		// current members keep the version of the referenced key they were added with
+		var atTx uint64
+
+		m, ok := members[k]
+		if ok {
+			atTx = m.atTx
+		}
+
+		refEntry, err := d.getAtTx(key, atTx, 0, d.st, 0)
+		if err != nil {
+			return 0, err
+		}
+		if refEntry.ReferencedBy != nil {
+			return 0, ErrReferencedKeyCannotBeAReference
+		}
+
+		e := EncodeZAdd(set, batch.updated[k], key, atTx)
+
+		err = tx.Set(e.Key, e.Metadata, e.Value)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	hdr, err := tx.Commit()
+	if err != nil {
+		return 0, err
+	}
+
+	return hdr.ID, nil
+}
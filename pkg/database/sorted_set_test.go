@@ -16,8 +16,13 @@ limitations under the License.
 package database
 
 import (
+	"context"
+	"errors"
 	"math"
+	"strconv"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/codenotary/immudb/embedded/store"
 	"github.com/codenotary/immudb/pkg/api/schema"
@@ -717,3 +722,403 @@ func TestStoreVerifiableZAdd(t *testing.T) {
 	require.Equal(t, req.Key, itemList1.Entries[0].Entry.Key)
 	require.Equal(t, req.Score, itemList1.Entries[0].Score)
 }
+
+func TestStoreZScanHistory(t *testing.T) {
+	db, closer := makeDb()
+	defer closer()
+
+	_, err := db.ZScanHistory(nil, []byte("member"))
+	require.ErrorIs(t, err, store.ErrIllegalArguments)
+
+	_, err = db.ZScanHistory([]byte("set"), nil)
+	require.ErrorIs(t, err, store.ErrIllegalArguments)
+
+	_, err = db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte("member"), Value: []byte("value")}}})
+	require.NoError(t, err)
+
+	_, err = db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte("member2"), Value: []byte("value2")}}})
+	require.NoError(t, err)
+
+	history, err := db.ZScanHistory([]byte("set"), []byte("member"))
+	require.NoError(t, err)
+	require.Empty(t, history)
+
+	var txIDs []uint64
+
+	for _, score := range []float64{10, 5, 10} {
+		hdr, err := db.ZAdd(&schema.ZAddRequest{Set: []byte("set"), Key: []byte("member"), Score: score})
+		require.NoError(t, err)
+
+		txIDs = append(txIDs, hdr.Id)
+
+		_, err = db.ZAdd(&schema.ZAddRequest{Set: []byte("set"), Key: []byte("member2"), Score: score + 1})
+		require.NoError(t, err)
+	}
+
+	_, err = db.ZAdd(&schema.ZAddRequest{Set: []byte("set2"), Key: []byte("member"), Score: 1})
+	require.NoError(t, err)
+
+	history, err = db.ZScanHistory([]byte("set"), []byte("member"))
+	require.NoError(t, err)
+	require.Len(t, history, 3)
+
+	for i, score := range []float64{10, 5, 10} {
+		require.Equal(t, score, history[i].Score)
+		require.Equal(t, txIDs[i], history[i].TxID)
+		require.False(t, history[i].Ts.IsZero())
+	}
+}
+
+func TestStoreZScore(t *testing.T) {
+	db, closer := makeDb()
+	defer closer()
+
+	_, _, err := db.ZScore(context.Background(), nil, []byte("member"))
+	require.ErrorIs(t, err, store.ErrIllegalArguments)
+
+	_, _, err = db.ZScore(context.Background(), []byte("set"), nil)
+	require.ErrorIs(t, err, store.ErrIllegalArguments)
+
+	_, err = db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{
+		{Key: []byte("member"), Value: []byte("value")},
+		{Key: []byte("member2"), Value: []byte("value2")},
+	}})
+	require.NoError(t, err)
+
+	_, _, err = db.ZScore(context.Background(), []byte("set"), []byte("member"))
+	require.ErrorIs(t, err, store.ErrKeyNotFound)
+
+	_, err = db.ZAdd(&schema.ZAddRequest{Set: []byte("set"), Key: []byte("member"), Score: 10})
+	require.NoError(t, err)
+
+	_, err = db.ZAdd(&schema.ZAddRequest{Set: []byte("set"), Key: []byte("member2"), Score: 1})
+	require.NoError(t, err)
+
+	hdr, err := db.ZAdd(&schema.ZAddRequest{Set: []byte("set"), Key: []byte("member"), Score: 3})
+	require.NoError(t, err)
+
+	score, txID, err := db.ZScore(context.Background(), []byte("set"), []byte("member"))
+	require.NoError(t, err)
+	require.Equal(t, float64(3), score)
+	require.Equal(t, hdr.Id, txID)
+
+	score, _, err = db.ZScore(context.Background(), []byte("set"), []byte("member2"))
+	require.NoError(t, err)
+	require.Equal(t, float64(1), score)
+
+	_, _, err = db.ZScore(context.Background(), []byte("set2"), []byte("member"))
+	require.ErrorIs(t, err, store.ErrKeyNotFound)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, _, err = db.ZScore(ctx, []byte("set"), []byte("member"))
+	require.ErrorIs(t, err, context.Canceled)
+
+	maxResultSize := db.maxResultSize
+	defer func() { db.maxResultSize = maxResultSize }()
+
+	db.maxResultSize = 2
+
+	_, _, err = db.ZScore(context.Background(), []byte("set"), []byte("member"))
+	require.ErrorIs(t, err, ErrResultSizeLimitReached)
+}
+
+func TestStoreZUnionStore(t *testing.T) {
+	db, closer := makeDb()
+	defer closer()
+
+	_, err := db.ZUnionStore(nil, [][]byte{[]byte("set1")}, nil)
+	require.ErrorIs(t, err, store.ErrIllegalArguments)
+
+	_, err = db.ZUnionStore([]byte("dest"), nil, nil)
+	require.ErrorIs(t, err, store.ErrIllegalArguments)
+
+	_, err = db.ZUnionStore([]byte("dest"), [][]byte{nil}, nil)
+	require.ErrorIs(t, err, store.ErrIllegalArguments)
+
+	_, err = db.ZUnionStore([]byte("dest"), [][]byte{[]byte("set1")}, []float64{1, 2})
+	require.ErrorIs(t, err, store.ErrIllegalArguments)
+
+	_, err = db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{
+		{Key: []byte("member1"), Value: []byte("value1")},
+		{Key: []byte("member2"), Value: []byte("value2")},
+		{Key: []byte("member3"), Value: []byte("value3")},
+	}})
+	require.NoError(t, err)
+
+	zAdd := func(set, member string, score float64) {
+		_, err := db.ZAdd(&schema.ZAddRequest{Set: []byte(set), Key: []byte(member), Score: score})
+		require.NoError(t, err)
+	}
+
+	zAdd("set1", "member1", 1)
+	zAdd("set1", "member1", 3)
+	zAdd("set1", "member2", 2)
+	zAdd("set1", "member3", math.MaxFloat64)
+
+	zAdd("set2", "member2", 10)
+	zAdd("set2", "member3", math.MaxFloat64)
+
+	zAdd("dest", "member1", 100)
+
+	txID, err := db.ZUnionStore([]byte("dest"), [][]byte{[]byte("set1"), []byte("set2")}, []float64{2, 1})
+	require.NoError(t, err)
+
+	hdr, err := db.st.ReadTxHeader(txID)
+	require.NoError(t, err)
+	require.Equal(t, 4, hdr.NEntries)
+
+	entries, err := db.ZScan(&schema.ZScanRequest{Set: []byte("dest")})
+	require.NoError(t, err)
+	require.Len(t, entries.Entries, 3)
+
+	require.Equal(t, []byte("member1"), entries.Entries[0].Key)
+	require.Equal(t, float64(6), entries.Entries[0].Score)
+	require.Equal(t, []byte("value1"), entries.Entries[0].Entry.Value)
+
+	require.Equal(t, []byte("member2"), entries.Entries[1].Key)
+	require.Equal(t, float64(14), entries.Entries[1].Score)
+
+	require.Equal(t, []byte("member3"), entries.Entries[2].Key)
+	require.Equal(t, math.MaxFloat64, entries.Entries[2].Score)
+
+	t.Run("missing weights should default to 1", func(t *testing.T) {
+		_, err := db.ZUnionStore([]byte("dest"), [][]byte{[]byte("set2"), []byte("set3")}, nil)
+		require.NoError(t, err)
+
+		entries, err := db.ZScan(&schema.ZScanRequest{Set: []byte("dest")})
+		require.NoError(t, err)
+		require.Len(t, entries.Entries, 2)
+
+		require.Equal(t, []byte("member2"), entries.Entries[0].Key)
+		require.Equal(t, float64(10), entries.Entries[0].Score)
+
+		require.Equal(t, []byte("member3"), entries.Entries[1].Key)
+		require.Equal(t, math.MaxFloat64, entries.Entries[1].Score)
+	})
+
+	t.Run("unions exceeding the max number of entries per tx should fail before committing", func(t *testing.T) {
+		rootPath := "data_" + strconv.FormatInt(time.Now().UnixNano(), 10)
+
+		options := DefaultOption().WithDBRootPath(rootPath).WithCorruptionChecker(false)
+		options.storeOpts.WithMaxTxEntries(2)
+
+		db, closer := makeDbWith("db", options)
+		defer closer()
+
+		for _, member := range []string{"member1", "member2", "member3"} {
+			_, err := db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{{Key: []byte(member), Value: []byte("value")}}})
+			require.NoError(t, err)
+
+			_, err = db.ZAdd(&schema.ZAddRequest{Set: []byte("set1"), Key: []byte(member), Score: 1})
+			require.NoError(t, err)
+		}
+
+		txCount := db.st.TransactionCount()
+
+		_, err := db.ZUnionStore([]byte("dest"), [][]byte{[]byte("set1")}, nil)
+		require.ErrorIs(t, err, store.ErrorMaxTxEntriesLimitExceeded)
+		require.Equal(t, txCount, db.st.TransactionCount())
+	})
+}
+
+func TestStoreListSets(t *testing.T) {
+	db, closer := makeDb()
+	defer closer()
+
+	sets, err := db.ListSets()
+	require.NoError(t, err)
+	require.Empty(t, sets)
+
+	_, err = db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{
+		{Key: []byte("member1"), Value: []byte("value1")},
+		{Key: []byte("member2"), Value: []byte("value2")},
+	}})
+	require.NoError(t, err)
+
+	zAdd := func(set, member string, score float64) {
+		_, err := db.ZAdd(&schema.ZAddRequest{Set: []byte(set), Key: []byte(member), Score: score})
+		require.NoError(t, err)
+	}
+
+	zAdd("setB", "member1", 1)
+	zAdd("setB", "member2", 2)
+	zAdd("a", "member1", 1)
+	zAdd("setA", "member2", 1)
+	zAdd("setAA", "member1", 1)
+
+	sets, err = db.ListSets()
+	require.NoError(t, err)
+	require.Equal(t, [][]byte{[]byte("a"), []byte("setA"), []byte("setAA"), []byte("setB")}, sets)
+
+	t.Run("sets should be paginated", func(t *testing.T) {
+		_, err := db.ListSetsPaged(nil, -1)
+		require.ErrorIs(t, err, store.ErrIllegalArguments)
+
+		_, err = db.ListSetsPaged(nil, db.maxResultSize+1)
+		require.ErrorIs(t, err, ErrResultSizeLimitExceeded)
+
+		// pages follow the same lexicographic order as ListSets
+		sets, err := db.ListSetsPaged(nil, 3)
+		require.NoError(t, err)
+		require.Equal(t, [][]byte{[]byte("a"), []byte("setA"), []byte("setAA")}, sets)
+
+		sets, err = db.ListSetsPaged(sets[len(sets)-1], 3)
+		require.NoError(t, err)
+		require.Equal(t, [][]byte{[]byte("setB")}, sets)
+
+		sets, err = db.ListSetsPaged(sets[len(sets)-1], 3)
+		require.NoError(t, err)
+		require.Empty(t, sets)
+
+		sets, err = db.ListSetsPaged([]byte("b"), 3)
+		require.NoError(t, err)
+		require.Equal(t, [][]byte{[]byte("setA"), []byte("setAA"), []byte("setB")}, sets)
+
+		sets, err = db.ListSetsPaged([]byte("setA"), 0)
+		require.NoError(t, err)
+		require.Equal(t, [][]byte{[]byte("setAA"), []byte("setB")}, sets)
+	})
+
+	t.Run("listing sets should be limited by the max result size", func(t *testing.T) {
+		maxResultSize := db.maxResultSize
+		defer func() { db.maxResultSize = maxResultSize }()
+
+		db.maxResultSize = 3
+
+		sets, err := db.ListSets()
+		require.NoError(t, err)
+		require.Equal(t, [][]byte{[]byte("a"), []byte("setA"), []byte("setAA")}, sets)
+
+		sets, err = db.ListSetsPaged(sets[len(sets)-1], 0)
+		require.NoError(t, err)
+		require.Equal(t, [][]byte{[]byte("setB")}, sets)
+	})
+
+	t.Run("sets without members should not be listed", func(t *testing.T) {
+		// the union of a missing set deletes every member of setA
+		_, err := db.ZUnionStore([]byte("setA"), [][]byte{[]byte("missing")}, nil)
+		require.NoError(t, err)
+
+		sets, err := db.ListSets()
+		require.NoError(t, err)
+		require.Equal(t, [][]byte{[]byte("a"), []byte("setAA"), []byte("setB")}, sets)
+	})
+}
+
+func TestStoreAtomicSortedSetUpdate(t *testing.T) {
+	db, closer := makeDb()
+	defer closer()
+
+	_, err := db.AtomicSortedSetUpdate(nil, func(b *SortedSetBatch) error { return nil })
+	require.ErrorIs(t, err, store.ErrIllegalArguments)
+
+	_, err = db.AtomicSortedSetUpdate([]byte("set"), nil)
+	require.ErrorIs(t, err, store.ErrIllegalArguments)
+
+	_, err = db.Set(&schema.SetRequest{KVs: []*schema.KeyValue{
+		{Key: []byte("member1"), Value: []byte("value1")},
+		{Key: []byte("member2"), Value: []byte("value2")},
+	}})
+	require.NoError(t, err)
+
+	_, err = db.ZAdd(&schema.ZAddRequest{Set: []byte("set"), Key: []byte("member1"), Score: 5})
+	require.NoError(t, err)
+
+	txID, err := db.AtomicSortedSetUpdate([]byte("set"), func(b *SortedSetBatch) error { return nil })
+	require.NoError(t, err)
+	require.Zero(t, txID)
+
+	raiseTo10 := func(b *SortedSetBatch) error {
+		for _, member := range []string{"member1", "member2"} {
+			score, ok := b.GetScore([]byte(member))
+			if !ok || score < 10 {
+				b.SetScore([]byte(member), 10)
+			}
+		}
+		return nil
+	}
+
+	txID, err = db.AtomicSortedSetUpdate([]byte("set"), func(b *SortedSetBatch) error {
+		score, ok := b.GetScore([]byte("member1"))
+		require.True(t, ok)
+		require.Equal(t, float64(5), score)
+
+		_, ok = b.GetScore([]byte("member2"))
+		require.False(t, ok)
+
+		err := raiseTo10(b)
+		require.NoError(t, err)
+
+		score, ok = b.GetScore([]byte("member2"))
+		require.True(t, ok)
+		require.Equal(t, float64(10), score)
+
+		return nil
+	})
+	require.NoError(t, err)
+	require.NotZero(t, txID)
+
+	for _, member := range []string{"member1", "member2"} {
+		score, scoreTxID, err := db.ZScore(context.Background(), []byte("set"), []byte(member))
+		require.NoError(t, err)
+		require.Equal(t, float64(10), score)
+		require.Equal(t, txID, scoreTxID)
+	}
+
+	t.Run("nothing should be committed when fn fails", func(t *testing.T) {
+		errFn := errors.New("some error")
+
+		_, err := db.AtomicSortedSetUpdate([]byte("set"), func(b *SortedSetBatch) error {
+			b.SetScore([]byte("member1"), 1)
+			return errFn
+		})
+		require.ErrorIs(t, err, errFn)
+
+		score, _, err := db.ZScore(context.Background(), []byte("set"), []byte("member1"))
+		require.NoError(t, err)
+		require.Equal(t, float64(10), score)
+	})
+
+	t.Run("members should be existing keys", func(t *testing.T) {
+		_, err := db.AtomicSortedSetUpdate([]byte("set"), func(b *SortedSetBatch) error {
+			b.SetScore([]byte("member1"), 1)
+			b.SetScore([]byte("missing"), 1)
+			return nil
+		})
+		require.ErrorIs(t, err, store.ErrKeyNotFound)
+
+		score, _, err := db.ZScore(context.Background(), []byte("set"), []byte("member1"))
+		require.NoError(t, err)
+		require.Equal(t, float64(10), score)
+	})
+
+	t.Run("concurrent updates should be serialized", func(t *testing.T) {
+		_, err := db.ZAdd(&schema.ZAddRequest{Set: []byte("counter"), Key: []byte("member1"), Score: 0})
+		require.NoError(t, err)
+
+		var wg sync.WaitGroup
+
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				_, err := db.AtomicSortedSetUpdate([]byte("counter"), func(b *SortedSetBatch) error {
+					score, _ := b.GetScore([]byte("member1"))
+					b.SetScore([]byte("member1"), score+1)
+					return nil
+				})
+				require.NoError(t, err)
+			}()
+		}
+
+		wg.Wait()
+
+		score, _, err := db.ZScore(context.Background(), []byte("counter"), []byte("member1"))
+		require.NoError(t, err)
+		require.Equal(t, float64(10), score)
+	})
+}
@@ -88,6 +88,10 @@ func (db *closedDB) VerifiableGet(req *schema.VerifiableGetRequest) (*schema.Ver
 	return nil, store.ErrAlreadyClosed
 }
 
+func (db *closedDB) VerifiedGetAtTx(key []byte, txID uint64, state *schema.ImmutableState) (*database.VerifiedItem, error) {
+	return nil, store.ErrAlreadyClosed
+}
+
 func (db *closedDB) GetAll(req *schema.KeyListRequest) (*schema.Entries, error) {
 	return nil, store.ErrAlreadyClosed
 }
@@ -136,6 +140,30 @@ func (db *closedDB) ZScan(req *schema.ZScanRequest) (*schema.ZEntries, error) {
 	return nil, store.ErrAlreadyClosed
 }
 
+func (db *closedDB) ZScanHistory(set []byte, member []byte) ([]*database.ZHistoryEntry, error) {
+	return nil, store.ErrAlreadyClosed
+}
+
+func (db *closedDB) ZScore(ctx context.Context, set []byte, member []byte) (float64, uint64, error) {
+	return 0, 0, store.ErrAlreadyClosed
+}
+
+func (db *closedDB) ZUnionStore(destSet []byte, srcSets [][]byte, weights []float64) (uint64, error) {
+	return 0, store.ErrAlreadyClosed
+}
+
+func (db *closedDB) ListSets() ([][]byte, error) {
+	return nil, store.ErrAlreadyClosed
+}
+
+func (db *closedDB) ListSetsPaged(cursor []byte, limit int) ([][]byte, error) {
+	return nil, store.ErrAlreadyClosed
+}
+
+func (db *closedDB) AtomicSortedSetUpdate(set []byte, fn func(*database.SortedSetBatch) error) (uint64, error) {
+	return 0, store.ErrAlreadyClosed
+}
+
 func (db *closedDB) NewSQLTx(ctx context.Context) (*sql.SQLTx, error) {
 	return nil, store.ErrAlreadyClosed
 }
@@ -0,0 +1,106 @@
+/*
+Copyright 2022 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stream
+
+import (
+	"bytes"
+	"io"
+)
+
+// ChunkedReader reads from the underlying reader always chunkSize bytes at a time
+type ChunkedReader struct {
+	r         io.Reader
+	chunkSize int
+	pending   []byte
+}
+
+// NewChunkedReader returns a ChunkedReader reading chunkSize bytes at a time from r.
+// DefaultChunkSize is used when chunkSize is not positive and MinChunkSize is the smallest size allowed
+func NewChunkedReader(r io.Reader, chunkSize int) *ChunkedReader {
+	if chunkSize <= 0 {
+		chunkSize = DefaultChunkSize
+	}
+	if chunkSize < MinChunkSize {
+		chunkSize = MinChunkSize
+	}
+
+	return &ChunkedReader{
+		r:         r,
+		chunkSize: chunkSize,
+	}
+}
+
+// ChunkSize returns the number of bytes read at a time from the underlying reader
+func (cr *ChunkedReader) ChunkSize() int {
+	return cr.chunkSize
+}
+
+// ReadChunk returns the next chunk, which only holds less than chunkSize bytes when the underlying reader is exhausted.
+// io.EOF is returned when there is no more data
+func (cr *ChunkedReader) ReadChunk() ([]byte, error) {
+	if len(cr.pending) > 0 {
+		chunk := cr.pending
+		cr.pending = nil
+		return chunk, nil
+	}
+
+	chunk := make([]byte, cr.chunkSize)
+
+	n, err := io.ReadFull(cr.r, chunk)
+	if err == io.EOF {
+		return nil, io.EOF
+	}
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return nil, err
+	}
+
+	return chunk[:n], nil
+}
+
+// NextValueSize returns the next chunk as a ValueSize
+func (cr *ChunkedReader) NextValueSize() (*ValueSize, error) {
+	chunk, err := cr.ReadChunk()
+	if err != nil {
+		return nil, err
+	}
+
+	return &ValueSize{
+		Content: bytes.NewReader(chunk),
+		Size:    len(chunk),
+	}, nil
+}
+
+// Read fills b with data read chunk by chunk from the underlying reader
+func (cr *ChunkedReader) Read(b []byte) (int, error) {
+	if len(b) == 0 {
+		return 0, nil
+	}
+
+	if len(cr.pending) == 0 {
+		chunk, err := cr.ReadChunk()
+		if err != nil {
+			return 0, err
+		}
+
+		cr.pending = chunk
+	}
+
+	n := copy(b, cr.pending)
+	cr.pending = cr.pending[n:]
+
+	return n, nil
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2022 CodeNotary, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stream
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/require"
+)
+
+type countingReader struct {
+	r     io.Reader
+	sizes []int
+}
+
+func (cr *countingReader) Read(b []byte) (int, error) {
+	cr.sizes = append(cr.sizes, len(b))
+	return cr.r.Read(b)
+}
+
+type failingReader struct {
+	err error
+}
+
+func (fr *failingReader) Read(b []byte) (int, error) {
+	return 0, fr.err
+}
+
+func TestChunkedReader(t *testing.T) {
+	require.Equal(t, DefaultChunkSize, NewChunkedReader(nil, 0).ChunkSize())
+	require.Equal(t, MinChunkSize, NewChunkedReader(nil, 1).ChunkSize())
+
+	data := make([]byte, 2*MinChunkSize+100)
+	for i := range data {
+		data[i] = byte(i)
+	}
+
+	src := &countingReader{r: iotest.HalfReader(bytes.NewReader(data))}
+	cr := NewChunkedReader(src, MinChunkSize)
+
+	var sizes []int
+	var content []byte
+
+	for {
+		vs, err := cr.NextValueSize()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+
+		b, err := ioutil.ReadAll(vs.Content)
+		require.NoError(t, err)
+		require.Len(t, b, vs.Size)
+
+		sizes = append(sizes, vs.Size)
+		content = append(content, b...)
+	}
+
+	require.Equal(t, []int{MinChunkSize, MinChunkSize, 100}, sizes)
+	require.Equal(t, data, content)
+
+	for _, s := range src.sizes {
+		require.LessOrEqual(t, s, MinChunkSize)
+	}
+
+	t.Run("read should return data across chunks", func(t *testing.T) {
+		cr := NewChunkedReader(bytes.NewReader(data), MinChunkSize)
+
+		b, err := ioutil.ReadAll(cr)
+		require.NoError(t, err)
+		require.Equal(t, data, b)
+
+		n, err := cr.Read(nil)
+		require.NoError(t, err)
+		require.Zero(t, n)
+	})
+
+	t.Run("reader errors should be returned", func(t *testing.T) {
+		errReader := errors.New("reader error")
+
+		cr := NewChunkedReader(&failingReader{err: errReader}, MinChunkSize)
+
+		_, err := cr.ReadChunk()
+		require.ErrorIs(t, err, errReader)
+
+		_, err = cr.Read(make([]byte, 1))
+		require.ErrorIs(t, err, errReader)
+
+		_, err = cr.NextValueSize()
+		require.ErrorIs(t, err, errReader)
+	})
+}